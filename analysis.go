@@ -0,0 +1,40 @@
+package resound
+
+import "math"
+
+// AnalysisFrame contains the levels of a single buffer of audio as it plays, passed to an analyzer callback set using
+// Player.SetAnalyzer() or DSPChannel.SetAnalyzer(). It can be used to drive VU meters, waveform displays, and other visualizers.
+type AnalysisFrame struct {
+	Player *Player // The Player that played the audio, or nil if the audio is the mix of a DSPChannel.
+
+	PeakLeft  float64 // The peak level of the left channel, ranging from 0 to 1.
+	PeakRight float64 // The peak level of the right channel, ranging from 0 to 1.
+	Peak      float64 // The peak level of both channels, ranging from 0 to 1.
+
+	RMSLeft  float64 // The root-mean-square level of the left channel, ranging from 0 to 1.
+	RMSRight float64 // The root-mean-square level of the right channel, ranging from 0 to 1.
+	RMS      float64 // The root-mean-square level of both channels, ranging from 0 to 1.
+
+	// A copy of the audio in the buffer, so it's safe to hold onto after the callback returns.
+	Samples AudioBuffer
+}
+
+// newAnalysisFrame measures the given audio, returning an AnalysisFrame.
+func newAnalysisFrame(player *Player, data []byte, bytesRead int) AnalysisFrame {
+
+	frame := AnalysisFrame{
+		Player:  player,
+		Samples: make(AudioBuffer, bytesRead),
+	}
+
+	copy(frame.Samples, data[:bytesRead])
+
+	frame.PeakLeft, frame.PeakRight = frame.Samples.Peak()
+	frame.RMSLeft, frame.RMSRight = frame.Samples.RMS()
+
+	frame.Peak = math.Max(frame.PeakLeft, frame.PeakRight)
+	frame.RMS = math.Sqrt((frame.RMSLeft*frame.RMSLeft + frame.RMSRight*frame.RMSRight) / 2)
+
+	return frame
+
+}
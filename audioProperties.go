@@ -3,34 +3,51 @@ package resound
 import (
 	"io"
 	"math"
+	"time"
 )
 
 // AnalysisResult is an object that contains the results of an analysis performed on a stream.
 type AnalysisResult struct {
-	Normalization float64
+	Normalization float64 // The factor to multiply the stream's volume by so its loudest peak reaches full volume.
+	RMS           float64 // The root-mean-square level of the stream, ranging from 0 to 1, which reflects its average level better than its peaks do.
+	LUFS          float64 // The approximate integrated loudness of the stream, in LUFS (loudness units relative to full scale), following ITU-R BS.1770.
 }
 
 // AudioProperty is an object that allows associating an AnalysisResult for a specific stream with a name for that stream.
 type AudioProperty struct {
-	result   AnalysisResult
-	analyzed bool
+	result     AnalysisResult
+	analyzed   bool
+	scanWindow time.Duration
 }
 
 func newAudioProperty() *AudioProperty {
-	ap := &AudioProperty{}
+	ap := &AudioProperty{scanWindow: 400 * time.Millisecond}
 	ap.ResetAnalyzation()
 	return ap
 }
 
-// A common byte slice for analysis of various streams
-var byteSlice = make([]byte, 512)
+// SetScanWindow sets how much audio is read at each point scanned by Analyze(). Longer windows are less likely to miss
+// loud sections of the stream between scan points, but take longer to analyze. Defaults to 400 milliseconds, which is the
+// length of the blocks used to measure loudness in ITU-R BS.1770.
+func (ap *AudioProperty) SetScanWindow(window time.Duration) *AudioProperty {
+	ap.scanWindow = window
+	return ap
+}
+
+// ScanWindow returns how much audio is read at each point scanned by Analyze().
+func (ap *AudioProperty) ScanWindow() time.Duration {
+	return ap.scanWindow
+}
 
 // Analyze analyzes the provided audio stream, returning an AnalysisResult object.
 // The stream is the audio stream to be used for scanning, and the scanCount is the number of times
 // the function should scan various parts of the audio stream. The higher the scan count, the more accurate
 // the results should be, but the longer the scan would take.
-// A scanCount of 16 means it samples the stream 16 times evenly throughout the file.
+// A scanCount of 16 means it samples the stream 16 times evenly throughout the file, reading a window of audio
+// (set with SetScanWindow()) at each point; if the windows would cover the whole stream, the whole stream is read instead.
 // If a scanCount of 0 or less is provided, it will default to 64.
+// Note that as only parts of the stream are scanned, the RMS and LUFS measurements are approximations of the stream's overall levels.
+// Analyze is safe to call on different AudioProperties concurrently.
 func (ap *AudioProperty) Analyze(stream io.ReadSeeker, scanCount int64) (AnalysisResult, error) {
 
 	if scanCount <= 0 {
@@ -41,8 +58,6 @@ func (ap *AudioProperty) Analyze(stream io.ReadSeeker, scanCount int64) (Analysi
 		return ap.result, nil
 	}
 
-	largest := 0.0
-
 	// Get the length of the stream normally
 	length, err := stream.Seek(0, io.SeekEnd)
 
@@ -57,52 +72,77 @@ func (ap *AudioProperty) Analyze(stream io.ReadSeeker, scanCount int64) (Analysi
 
 	}
 
-	// Seek back afterwards as necessary
-	stream.Seek(0, io.SeekStart)
+	sampleRate := SampleRate()
 
-	seekJump := length / int64(scanCount)
+	windowFrames := int64(ap.scanWindow.Seconds() * float64(sampleRate))
+	if windowFrames < 1 {
+		windowFrames = 1
+	}
 
-	pos := int64(0)
+	windowBytes := windowFrames * 4
 
-	for err == nil {
+	// If the windows would overlap, just scan the whole stream as a series of windows.
+	if windowBytes*scanCount >= length {
+		scanCount = (length + windowBytes - 1) / windowBytes
+	}
 
-		_, err = stream.Read(byteSlice)
+	buffer := make([]byte, windowBytes)
 
-		if err != nil {
-			break
-		}
+	kWeight := newKWeighting(sampleRate)
 
-		audioBuffer := AudioBuffer(byteSlice)
+	largest := 0.0
+	sumSquares := 0.0
+	frames := 0
 
-		for i := 0; i < audioBuffer.Len(); i++ {
+	// Each scanned window is treated as a block for measuring loudness.
+	blocks := []float64{}
 
-			l, r := audioBuffer.Get(i)
+	for scan := int64(0); scan < scanCount; scan++ {
 
-			la := math.Abs(l)
-			ra := math.Abs(r)
+		// Scan points are evenly spaced through the stream, aligned to the start of a frame.
+		pos := int64(float64(length-windowBytes) * float64(scan) / math.Max(float64(scanCount-1), 1))
+		if scanCount*windowBytes >= length {
+			pos = scan * windowBytes
+		}
+		pos = pos / 4 * 4
+		if pos < 0 {
+			pos = 0
+		}
 
-			if la > largest {
-				largest = la
-			}
-			if ra > largest {
-				largest = ra
-			}
+		if _, err := stream.Seek(pos, io.SeekStart); err != nil {
+			return AnalysisResult{}, err
+		}
 
+		n, err := io.ReadFull(stream, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return AnalysisResult{}, err
 		}
 
-		// InfiniteLoops don't return an error if you attempt to seek too far; they just go back to the start when attempting to read
-		if pos+seekJump >= length {
-			break
+		audioBuffer := AudioBuffer(buffer[:n])
+
+		if audioBuffer.Len() == 0 {
+			continue
 		}
 
-		pos += seekJump
+		blockSum := 0.0
 
-		_, err = stream.Seek(seekJump, io.SeekCurrent)
+		for i := 0; i < audioBuffer.Len(); i++ {
+
+			l, r := audioBuffer.Get(i)
+
+			largest = math.Max(largest, math.Max(math.Abs(l), math.Abs(r)))
+
+			sumSquares += (l*l + r*r) / 2
+
+			kl := kWeight.process(0, l)
+			kr := kWeight.process(1, r)
+			blockSum += kl*kl + kr*kr
 
-		if err != nil {
-			return AnalysisResult{}, err
 		}
 
+		frames += audioBuffer.Len()
+		blocks = append(blocks, blockSum/float64(audioBuffer.Len()))
+
 	}
 
 	// Seek back to the beginning
@@ -112,8 +152,15 @@ func (ap *AudioProperty) Analyze(stream io.ReadSeeker, scanCount int64) (Analysi
 		return AnalysisResult{}, err
 	}
 
+	rms := 0.0
+	if frames > 0 {
+		rms = math.Sqrt(sumSquares / float64(frames))
+	}
+
 	ap.result = AnalysisResult{
 		Normalization: 1.0 / largest,
+		RMS:           rms,
+		LUFS:          integratedLoudness(blocks),
 	}
 
 	ap.analyzed = true
@@ -0,0 +1,83 @@
+package resound
+
+import (
+	"bytes"
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLoudSection returns ten seconds of a quiet tone, with a loud burst from 3.5 to 3.8 seconds; with 4 scans, the scan points
+// are at 0, 3, 6, and 9 seconds, so the burst is only found if the scan window is long enough to reach it.
+func testLoudSection() []byte {
+	data := testSine(SampleRate()*10, 440, 0.05)
+	loud := testSine(SampleRate()*3/10, 440, 0.9)
+	copy(data[SampleRate()*35/10*4:], loud)
+	return data
+}
+
+func TestAnalyzeScanWindow(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	data := testLoudSection()
+
+	short, err := newAudioProperty().SetScanWindow(10*time.Millisecond).Analyze(bytes.NewReader(data), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(short.Normalization-1/0.05) > 0.5 {
+		t.Errorf("expected a short scan window to miss the loud section, got a normalization of %f", short.Normalization)
+	}
+
+	long, err := newAudioProperty().SetScanWindow(time.Second).Analyze(bytes.NewReader(data), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(long.Normalization-1/0.9) > 0.01 {
+		t.Errorf("expected a one second scan window to find the loud section between scan points, got a normalization of %f", long.Normalization)
+	}
+
+	// The RMS is measured across all of the scanned windows, so the loud section raises it above the quiet tone's level.
+	if quiet := 0.05 / math.Sqrt2; long.RMS <= quiet*1.5 || short.RMS > quiet*1.01 {
+		t.Errorf("expected the RMS to reflect the windows that were scanned, got %f (short) and %f (long)", short.RMS, long.RMS)
+	}
+
+}
+
+func TestAnalyzeConcurrently(t *testing.T) {
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	SetDefaultSampleRate(44100)
+
+	data := testLoudSection()
+	properties := NewAudioProperties()
+	a, b := properties.Get("a"), properties.Get("b")
+
+	wg := sync.WaitGroup{}
+
+	for _, ap := range []*AudioProperty{a, b} {
+		wg.Add(1)
+		go func(ap *AudioProperty) {
+			defer wg.Done()
+			if _, err := ap.SetScanWindow(time.Second).Analyze(bytes.NewReader(data), 4); err != nil {
+				t.Error(err)
+			}
+		}(ap)
+	}
+
+	wg.Wait()
+
+	ra, _ := a.Analyze(nil, 4)
+	rb, _ := b.Analyze(nil, 4)
+
+	if ra != rb {
+		t.Errorf("expected analyzing the same stream concurrently to give the same results, got %v and %v", ra, rb)
+	}
+
+}
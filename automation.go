@@ -0,0 +1,92 @@
+package resound
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/tanema/gween"
+	"github.com/tanema/gween/ease"
+)
+
+// Automation smoothly changes a value over time, calling a setter function with the new value each time it's updated.
+// This can be used to automate any effect parameter, like a filter's strength, a Pan effect's panning, or a PitchShift's pitch.
+type Automation struct {
+	set        func(float64)
+	tween      *gween.Tween
+	finished   bool
+	lastSample int64
+}
+
+// Automate creates a new Automation that changes a value from the starting value to the ending value over the given duration,
+// calling the set function with the new value each time the Automation is updated.
+// curve is the easing function to use; if it's nil, the value changes linearly.
+// The Automation must be updated, either using Update() from your game's Update() function, or Tick() to follow the global sample clock.
+func Automate(set func(float64), from, to float64, duration time.Duration, curve ease.TweenFunc) *Automation {
+
+	if curve == nil {
+		curve = ease.Linear
+	}
+
+	a := &Automation{
+		set:        set,
+		tween:      gween.New(float32(from), float32(to), float32(duration.Seconds()), curve),
+		lastSample: -1,
+	}
+
+	set(from)
+
+	return a
+
+}
+
+// Update advances the Automation by the given time in seconds, calling its setter function with the new value.
+// Update returns true once the Automation has finished.
+func (a *Automation) Update(dt float64) bool {
+
+	if a.finished {
+		return true
+	}
+
+	value, finished := a.tween.Update(float32(dt))
+	a.set(float64(value))
+	a.finished = finished
+
+	return finished
+
+}
+
+// Tick advances the Automation by the amount of time that has passed on the global sample clock since the last time
+// Tick was called, calling its setter function with the new value. This allows an Automation to follow the audio timeline
+// rather than the game's frame rate.
+// Tick returns true once the Automation has finished.
+func (a *Automation) Tick() bool {
+
+	now := ElapsedSamples()
+
+	if a.lastSample < 0 {
+		a.lastSample = now
+		return a.finished
+	}
+
+	context := audio.CurrentContext()
+
+	if context == nil {
+		return a.finished
+	}
+
+	dt := float64(now-a.lastSample) / float64(context.SampleRate())
+	a.lastSample = now
+
+	return a.Update(dt)
+
+}
+
+// Stop stops the Automation where it is, leaving the automated value as it currently is.
+func (a *Automation) Stop() {
+	a.finished = true
+}
+
+// Finished returns if the Automation has finished.
+func (a *Automation) Finished() bool {
+	return a.finished
+}
@@ -0,0 +1,212 @@
+package resound
+
+import (
+	"io"
+	"math"
+	"time"
+)
+
+// BeatDetector estimates the tempo and beat positions of audio offline, using an energy-based onset detector followed by autocorrelation
+// of the onset envelope. It's written in pure Go.
+//
+// For music with a steady tempo and clear percussion, the estimated tempo is usually within a beat per minute or two of the true tempo.
+// Like most tempo estimators, it can be fooled into reporting double or half the true tempo (an "octave error"), so narrowing the tempo range
+// with SetTempoRange() helps when you know roughly what to expect. Music with a changing tempo, or little percussion, is estimated less reliably.
+type BeatDetector struct {
+	minBPM float64
+	maxBPM float64
+}
+
+// NewBeatDetector creates a new BeatDetector that looks for tempos between 60 and 200 beats per minute.
+func NewBeatDetector() *BeatDetector {
+	return &BeatDetector{minBPM: 60, maxBPM: 200}
+}
+
+// SetTempoRange sets the range of tempos (in beats per minute) the BeatDetector considers.
+func (bd *BeatDetector) SetTempoRange(minBPM, maxBPM float64) *BeatDetector {
+	if minBPM > maxBPM {
+		minBPM, maxBPM = maxBPM, minBPM
+	}
+	bd.minBPM = math.Max(minBPM, 1)
+	bd.maxBPM = math.Max(maxBPM, bd.minBPM)
+	return bd
+}
+
+// TempoRange returns the range of tempos (in beats per minute) the BeatDetector considers.
+func (bd *BeatDetector) TempoRange() (minBPM, maxBPM float64) {
+	return bd.minBPM, bd.maxBPM
+}
+
+const (
+	beatWindowFrames = 1024 // The number of frames of audio used to measure the energy of each step of the onset envelope
+	beatHopFrames    = 512  // The number of frames between each step of the onset envelope
+)
+
+// AnalyzeTempo reads the given stream from the start to the end, returning its estimated tempo (in beats per minute) and the estimated positions
+// of its beats. The stream is seeked back to the start afterwards. Note that this reads the entire stream, so it shouldn't be used on infinite loops.
+// If no tempo could be found (for example, if the stream is silent or too short), AnalyzeTempo returns a tempo of 0 and no beats.
+func (bd *BeatDetector) AnalyzeTempo(stream io.ReadSeeker) (bpm float64, beats []time.Duration, err error) {
+
+	if _, err = stream.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+
+	sampleRate := SampleRate()
+
+	onsets, err := onsetEnvelope(stream)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if _, err = stream.Seek(0, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+
+	// The onset envelope has one step per hop, so this is its rate in steps per second.
+	envelopeRate := float64(sampleRate) / beatHopFrames
+
+	minLag := int(math.Floor(envelopeRate * 60 / bd.maxBPM))
+	maxLag := int(math.Ceil(envelopeRate * 60 / bd.minBPM))
+
+	if minLag < 1 {
+		minLag = 1
+	}
+
+	if maxLag+1 >= len(onsets) || minLag >= maxLag {
+		return 0, nil, nil
+	}
+
+	// Autocorrelating the onset envelope finds the lag (the beat period) at which onsets most strongly repeat.
+	correlations := make([]float64, maxLag+2)
+
+	for lag := minLag - 1; lag <= maxLag+1; lag++ {
+		if lag < 1 {
+			continue
+		}
+		sum := 0.0
+		for i := lag; i < len(onsets); i++ {
+			sum += onsets[i] * onsets[i-lag]
+		}
+		correlations[lag] = sum / float64(len(onsets)-lag)
+	}
+
+	bestLag := minLag
+	for lag := minLag; lag <= maxLag; lag++ {
+		if correlations[lag] > correlations[bestLag] {
+			bestLag = lag
+		}
+	}
+
+	if correlations[bestLag] <= 0 {
+		return 0, nil, nil
+	}
+
+	// Parabolic interpolation between the neighboring lags gives a period more precise than a single step of the envelope.
+	period := float64(bestLag)
+	if bestLag > 1 {
+		a, b, c := correlations[bestLag-1], correlations[bestLag], correlations[bestLag+1]
+		if denom := a - 2*b + c; denom != 0 {
+			period += clamp(0.5*(a-c)/denom, -0.5, 0.5)
+		}
+	}
+
+	bpm = 60 * envelopeRate / period
+
+	// The beat phase is the offset at which onsets line up best with a grid of beats one period apart.
+	bestPhase := 0
+	bestScore := -1.0
+
+	for phase := 0; phase < bestLag; phase++ {
+		score := 0.0
+		for pos := float64(phase); int(pos) < len(onsets); pos += period {
+			score += onsets[int(pos)]
+		}
+		if score > bestScore {
+			bestScore = score
+			bestPhase = phase
+		}
+	}
+
+	// Each beat is snapped to the strongest onset near where the grid says it should be.
+	tolerance := int(period * 0.1)
+
+	for pos := float64(bestPhase); int(pos) < len(onsets); pos += period {
+
+		best := int(pos)
+
+		for i := int(pos) - tolerance; i <= int(pos)+tolerance; i++ {
+			if i >= 0 && i < len(onsets) && onsets[i] > onsets[best] {
+				best = i
+			}
+		}
+
+		seconds := float64(best*beatHopFrames) / float64(sampleRate)
+		beats = append(beats, time.Duration(seconds*float64(time.Second)))
+
+	}
+
+	return bpm, beats, nil
+
+}
+
+// onsetEnvelope reads the given stream until it ends, returning how strongly the energy of the audio rises at each hop.
+func onsetEnvelope(stream io.Reader) ([]float64, error) {
+
+	buffer := make([]byte, beatHopFrames*4)
+
+	// The energy of each hop is kept, so each window's energy is the sum of the energies of the hops it spans.
+	hopEnergies := []float64{}
+
+	for {
+
+		n, err := io.ReadFull(stream, buffer)
+
+		if n > 0 {
+
+			audioBuffer := AudioBuffer(buffer[:n])
+			energy := 0.0
+
+			for i := 0; i < audioBuffer.Len(); i++ {
+				l, r := audioBuffer.Get(i)
+				energy += l*l + r*r
+			}
+
+			hopEnergies = append(hopEnergies, energy)
+
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+	}
+
+	hopsPerWindow := beatWindowFrames / beatHopFrames
+
+	onsets := make([]float64, len(hopEnergies))
+	prevLog := 0.0
+
+	for i := range hopEnergies {
+
+		energy := 0.0
+		for j := i; j < i+hopsPerWindow && j < len(hopEnergies); j++ {
+			energy += hopEnergies[j]
+		}
+
+		// Using the logarithm of the energy makes the detector respond to relative rises in energy, so quiet and loud passages are treated alike.
+		logEnergy := math.Log1p(energy)
+
+		// Only rises in energy count as onsets.
+		if i > 0 {
+			onsets[i] = math.Max(logEnergy-prevLog, 0)
+		}
+
+		prevLog = logEnergy
+
+	}
+
+	return onsets, nil
+
+}
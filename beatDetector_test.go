@@ -0,0 +1,49 @@
+package resound
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBeatDetectorTempo(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	// A click track at 120 beats per minute; each click is a short, decaying burst of a 1 khz tone.
+	frames := 44100 * 12
+	beatFrames := 44100 / 2
+
+	data := make([]byte, frames*4)
+	for i := 0; i < frames; i++ {
+		pos := i % beatFrames
+		v := 0.8 * math.Exp(-float64(pos)/200) * math.Sin(2*math.Pi*1000*float64(pos)/44100)
+		AudioBuffer(data).Set(i, v, v)
+	}
+
+	bpm, beats, err := NewBeatDetector().AnalyzeTempo(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(bpm-120) > 2 {
+		t.Errorf("expected a tempo of 120 bpm, got %f", bpm)
+	}
+
+	if len(beats) < 20 {
+		t.Fatalf("expected at least 20 beats in 12 seconds, got %d", len(beats))
+	}
+
+	for i := 1; i < len(beats); i++ {
+		if gap := beats[i] - beats[i-1]; gap < 470*time.Millisecond || gap > 530*time.Millisecond {
+			t.Errorf("expected the beats to be half a second apart, got %s between beats %d and %d", gap, i-1, i)
+		}
+	}
+
+	if bpm, beats, _ := NewBeatDetector().AnalyzeTempo(bytes.NewReader(make([]byte, 44100*4))); bpm != 0 || len(beats) > 0 {
+		t.Errorf("expected no tempo to be found in silence, got %f bpm and %d beats", bpm, len(beats))
+	}
+
+}
@@ -0,0 +1,41 @@
+package resound
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// Buffer holds a fully-decoded sound in memory, so that it can be played many times over without being decoded each time
+// (like a footstep or a UI click). Rather than being played directly, a Buffer hands out lightweight readers over its audio with Reader().
+type Buffer struct {
+	data []byte
+}
+
+// NewBuffer creates a new Buffer by reading the given stream (like a decoded WAV or OGG file) from its current position until it ends.
+// The stream should be 16-bit stereo audio at the audio context's sample rate.
+func NewBuffer(stream io.ReadSeeker) (*Buffer, error) {
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, err
+	}
+	// A partial frame at the end of the stream isn't valid audio.
+	return &Buffer{data: data[:len(data)/4*4]}, nil
+}
+
+// Reader returns a new stream that reads the Buffer's audio from the start, which can be used as a Player's source.
+// Each reader has its own read position, so any number of them can play (and seek) at the same time without interfering with each other.
+// The readers share the Buffer's audio rather than copying it, so they're cheap to create.
+func (b *Buffer) Reader() io.ReadSeeker {
+	return bytes.NewReader(b.data)
+}
+
+// Bytes returns the Buffer's raw audio, which shouldn't be modified while it's being played.
+func (b *Buffer) Bytes() []byte {
+	return b.data
+}
+
+// Duration returns the length of the Buffer's audio, based on the sample rate given by SampleRate().
+func (b *Buffer) Duration() time.Duration {
+	return time.Duration(float64(len(b.data)) / float64(SampleRate()*4) * float64(time.Second))
+}
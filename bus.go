@@ -0,0 +1,437 @@
+package resound
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// channelVoice is a Player playing through a DSPChannel, along with the gain it was last mixed into the channel with.
+type channelVoice struct {
+	player *Player
+	gain   float64 // The gain the Player was mixed in with at the end of the last buffer, or -1 if it hasn't been mixed yet
+}
+
+// channelBus holds the audio a DSPChannel renders each buffer: the mix of the Players playing through it and the channels routed into it,
+// and the result of processing that mix with the channel's effects and volume, which is mixed into the channel's output channel in turn.
+type channelBus struct {
+	mix     []float64 // The mix of everything routed into the channel, in 16-bit sample units so it can exceed full scale without clipping
+	out     []byte    // The channel's processed audio
+	scratch []byte    // The audio read from each of the channel's Players
+
+	processed    []float64 // The mix of the Players that processed their audio with the channel's effects themselves (see EffectRouting)
+	hasProcessed bool      // Whether anything has been mixed into the processed mix in the current pass
+
+	pass     uint64 // The render pass the bus was last prepared for
+	live     bool   // Whether the channel and every channel it's routed through are active
+	closed   bool   // Whether the channel or any channel it's routed through is closed
+	received bool   // Whether anything has been routed into the channel in the current pass
+	dormant  bool   // Whether the channel's output has died away since anything was last routed into it
+
+	gain  float64 // The volume (including ducking) the channel's audio was last scaled by, or -1 before it's first scaled
+	block *processBlock
+
+	// mutex is held while the bus is being rendered or mixed into. When a channel is mixed into its output channel, the
+	// output channel's bus is locked while the channel's is still held, so buses are always locked in the direction audio flows.
+	mutex sync.Mutex
+}
+
+// resize prepares the bus for rendering the given number of frames, clearing its mix.
+func (b *channelBus) resize(frames int) {
+
+	if cap(b.out) < frames*4 {
+		b.mix = make([]float64, frames*2)
+		b.out = make([]byte, frames*4)
+		b.scratch = make([]byte, frames*4)
+		b.processed = make([]float64, frames*2)
+	}
+
+	b.mix = b.mix[:frames*2]
+	b.out = b.out[:frames*4]
+	b.scratch = b.scratch[:frames*4]
+	b.processed = b.processed[:frames*2]
+
+	for i := range b.mix {
+		b.mix[i] = 0
+	}
+
+	if b.hasProcessed {
+		for i := range b.processed {
+			b.processed[i] = 0
+		}
+		b.hasProcessed = false
+	}
+
+}
+
+// add mixes the given audio into the bus, ramping its gain from start to end across the audio to avoid clicks.
+func (b *channelBus) add(data []byte, start, end float64) {
+	mixInto(b.mix, data, start, end)
+}
+
+// addProcessed mixes the given audio into the bus's processed mix, which is added to the channel's audio after its effects,
+// ramping its gain from start to end across the audio to avoid clicks.
+func (b *channelBus) addProcessed(data []byte, start, end float64) {
+	mixInto(b.processed, data, start, end)
+	b.hasProcessed = true
+}
+
+// mixInto mixes the given audio into the given mix, ramping its gain from start to end across the audio.
+func mixInto(mix []float64, data []byte, start, end float64) {
+
+	frames := len(data) / 4
+	if frames > len(mix)/2 {
+		frames = len(mix) / 2
+	}
+
+	if frames == 0 || (start == 0 && end == 0) {
+		return
+	}
+
+	step := (end - start) / float64(frames)
+	gain := start
+
+	for i := 0; i < frames; i++ {
+		gain += step
+		mix[i*2] += float64(int16(data[i*4])|int16(data[i*4+1])<<8) * gain
+		mix[i*2+1] += float64(int16(data[i*4+2])|int16(data[i*4+3])<<8) * gain
+	}
+
+}
+
+// send mixes the given audio into the bus, scaled by the given gain, if the bus is being rendered in the given render pass.
+func (b *channelBus) send(data []byte, gain float64, pass uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.pass == pass {
+		b.add(data, gain, gain)
+		b.received = true
+	}
+}
+
+// store converts the bus's mix into 16-bit audio in its output buffer, clamping it to full scale.
+func (b *channelBus) store() {
+
+	const max = math.MaxInt16
+
+	for i := 0; i < len(b.mix)/2; i++ {
+
+		l := clamp(b.mix[i*2], -max, max)
+		r := clamp(b.mix[i*2+1], -max, max)
+
+		lc, rc := int16(l), int16(r)
+
+		b.out[i*4] = byte(lc)
+		b.out[i*4+1] = byte(lc >> 8)
+		b.out[i*4+2] = byte(rc)
+		b.out[i*4+3] = byte(rc >> 8)
+
+	}
+
+}
+
+// storeProcessed adds the bus's processed mix to its output buffer, clamping the result to full scale.
+func (b *channelBus) storeProcessed() {
+
+	if !b.hasProcessed {
+		return
+	}
+
+	const max = math.MaxInt16
+
+	for i := 0; i < len(b.processed)/2; i++ {
+
+		l := clamp(float64(int16(b.out[i*4])|int16(b.out[i*4+1])<<8)+b.processed[i*2], -max, max)
+		r := clamp(float64(int16(b.out[i*4+2])|int16(b.out[i*4+3])<<8)+b.processed[i*2+1], -max, max)
+
+		lc, rc := int16(l), int16(r)
+
+		b.out[i*4] = byte(lc)
+		b.out[i*4+1] = byte(lc >> 8)
+		b.out[i*4+2] = byte(rc)
+		b.out[i*4+3] = byte(rc >> 8)
+
+	}
+
+}
+
+// peak returns the peak level of the bus's mix, which can be above 1 if the mix is louder than full scale.
+func (b *channelBus) peak() float64 {
+	peak := 0.0
+	for _, s := range b.mix {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	return peak / math.MaxInt16
+}
+
+// silence zeroes the bus's output.
+func (b *channelBus) silence() {
+	for i := range b.out {
+		b.out[i] = 0
+	}
+}
+
+// silent returns if the bus's output is silent, allowing for the last bit of noise left as effects die away.
+func (b *channelBus) silent() bool {
+	for i := 0; i+1 < len(b.out); i += 2 {
+		if s := int16(b.out[i]) | int16(b.out[i+1])<<8; s > 1 || s < -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// graphVersion is incremented whenever a DSPChannel is linked into or unlinked from another channel's inputs, or its sends change,
+// so render graphs know to update their order.
+var graphVersion atomic.Uint64
+
+// renderPasses counts the render passes that have started, giving each pass a unique ID.
+var renderPasses atomic.Uint64
+
+// renderGraph renders a set of root DSPChannels along with every channel routed into them, rendering each channel before
+// the channel it outputs into, so that every channel's bus is mixed and processed exactly once per buffer.
+type renderGraph struct {
+	roots   []*DSPChannel
+	order   []*DSPChannel // The channels to render, in order
+	version uint64        // The graph version the order was built for
+	built   bool
+}
+
+// update rebuilds the graph's render order if channels have been linked or unlinked since it was last built.
+func (g *renderGraph) update() {
+
+	version := graphVersion.Load()
+
+	if g.built && g.version == version {
+		return
+	}
+
+	g.built = true
+	g.version = version
+	g.order = g.order[:0]
+
+	for _, root := range g.roots {
+		g.order = root.appendRenderOrder(g.order)
+	}
+
+	g.order = sortRenderOrder(g.order)
+
+}
+
+// sortRenderOrder returns the given channels sorted so that each one comes after the channels routed into it and the channels that
+// send to it, so everything mixed into a channel's bus has been rendered before the channel is. Channels otherwise keep their order.
+func sortRenderOrder(channels []*DSPChannel) []*DSPChannel {
+
+	index := make(map[*DSPChannel]int, len(channels))
+	for i, c := range channels {
+		index[c] = i
+	}
+
+	waiting := make([]int, len(channels)) // How many channels each channel is waiting on
+	next := make([][]int, len(channels))  // The channels each channel is mixed into
+
+	for i, c := range channels {
+
+		targets := []*DSPChannel{c.outputChannel()}
+		for _, s := range c.sendList() {
+			targets = append(targets, s.target)
+		}
+
+		for _, target := range targets {
+			if j, ok := index[target]; ok && j != i {
+				next[i] = append(next[i], j)
+				waiting[j]++
+			}
+		}
+
+	}
+
+	ready := []int{}
+	for i := range channels {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order := make([]*DSPChannel, 0, len(channels))
+
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, channels[i])
+		for _, j := range next[i] {
+			if waiting[j]--; waiting[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	return order
+
+}
+
+// render renders the given number of frames through the graph; the result is left in each root channel's bus.
+func (g *renderGraph) render(frames int) {
+	g.update()
+	g.renderOrder(frames)
+}
+
+// renderOrder renders the given number of frames through the graph's channels in its current order, without updating it.
+func (g *renderGraph) renderOrder(frames int) {
+
+	pass := renderPasses.Add(1)
+
+	// Channels are prepared from the roots outwards, so each one knows if the channels it's routed through are active.
+	for i := len(g.order) - 1; i >= 0; i-- {
+		g.order[i].prepareBus(pass, frames)
+	}
+
+	for _, c := range g.order {
+		c.renderBus(pass)
+	}
+
+}
+
+// appendRenderOrder appends the DSPChannel to the given render order, after the channels routed into it.
+func (d *DSPChannel) appendRenderOrder(order []*DSPChannel) []*DSPChannel {
+	for _, input := range d.inputList() {
+		order = input.appendRenderOrder(order)
+	}
+	return append(order, d)
+}
+
+// prepareBus clears the DSPChannel's bus for the given render pass. The channel's output channel should be prepared first.
+func (d *DSPChannel) prepareBus(pass uint64, frames int) {
+
+	d.mutex.Lock()
+	active, closed, output := d.Active, d.closed, d.outputChannelLocked()
+	d.mutex.Unlock()
+
+	b := &d.bus
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.resize(frames)
+	b.pass = pass
+	b.received = false
+	b.live = active
+	b.closed = closed
+
+	if output != nil && output.bus.pass == pass {
+		b.live = b.live && output.bus.live
+		b.closed = b.closed || output.bus.closed
+	}
+
+}
+
+// renderBus mixes the DSPChannel's Players into its bus, processes the mix, and then mixes the result into its output channel's bus
+// and the buses of the channels it sends to.
+func (d *DSPChannel) renderBus(pass uint64) {
+
+	b := &d.bus
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		d.closeVoices()
+		b.silence()
+		return
+	}
+
+	if !b.live {
+		b.silence()
+		return
+	}
+
+	d.mixVoices()
+
+	if b.received {
+		b.dormant = false
+	} else if b.dormant {
+		b.silence()
+		d.disconnectIfIdle()
+		return
+	}
+
+	settings := d.settings()
+
+	d.process(settings)
+
+	// Once nothing is routed into the channel, it keeps rendering until its effects have died away (like a reverb tail).
+	if !b.received && b.silent() {
+		b.dormant = true
+	}
+
+	if output := d.outputChannel(); output != nil {
+		output.bus.send(b.out, 1, pass)
+	}
+
+	for _, s := range settings.sends {
+		s.target.bus.send(b.out, s.level, pass)
+	}
+
+}
+
+// mixVoices reads the audio of each of the Players playing through the DSPChannel and mixes it into the channel's bus,
+// scaled by each Player's volume (or silenced if it's muted or another Player is soloed). Players whose streams end are removed.
+func (d *DSPChannel) mixVoices() {
+
+	d.mutex.Lock()
+	voices := d.voices
+	d.mutex.Unlock()
+
+	if len(voices) == 0 {
+		return
+	}
+
+	b := &d.bus
+	b.received = true
+
+	solo := false
+	for _, v := range voices {
+		if v.player.Solo() {
+			solo = true
+			break
+		}
+	}
+
+	for _, v := range voices {
+
+		n, err := v.player.Read(b.scratch)
+
+		gain := v.player.mixGain(solo)
+
+		start := v.gain
+		if start < 0 {
+			start = gain
+		}
+		v.gain = gain
+
+		if v.player.processedByChannel() {
+			b.addProcessed(b.scratch[:n], start, gain)
+		} else {
+			b.add(b.scratch[:n], start, gain)
+		}
+
+		if err != nil {
+			d.endVoice(v.player)
+		}
+
+	}
+
+}
+
+// closeVoices stops each of the Players playing through the DSPChannel and closes them, as the channel (or one it's routed through) has been closed.
+func (d *DSPChannel) closeVoices() {
+
+	d.mutex.Lock()
+	voices := d.voices
+	d.mutex.Unlock()
+
+	for _, v := range voices {
+		v.player.closeOnChannel()
+	}
+
+}
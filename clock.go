@@ -0,0 +1,119 @@
+package resound
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// clock is the global sample clock, used for scheduling audio against the samples played through the audio context.
+var clock = &sampleClock{}
+
+// sampleClock is a silent stream that plays through the audio context to count how many samples the context has played since the clock was started.
+// Because Ebitengine reads from each player ahead of time in buffers, the clock is only as accurate as the difference between the
+// buffer sizes of the clock and the Players that are scheduled against it.
+type sampleClock struct {
+	mutex   sync.Mutex
+	samples int64
+	events  []scheduledEvent
+	player  *audio.Player
+}
+
+type scheduledEvent struct {
+	sample   int64
+	callback func()
+}
+
+func (c *sampleClock) Read(p []byte) (int, error) {
+
+	n := len(p) / 4 * 4
+
+	for i := 0; i < n; i++ {
+		p[i] = 0
+	}
+
+	c.mutex.Lock()
+
+	c.samples += int64(n / 4)
+
+	remaining := c.events[:0]
+
+	for _, event := range c.events {
+		if event.sample <= c.samples {
+			// Callbacks are run in their own goroutines so they can't block the audio thread.
+			go event.callback()
+		} else {
+			remaining = append(remaining, event)
+		}
+	}
+
+	c.events = remaining
+
+	c.mutex.Unlock()
+
+	return n, nil
+
+}
+
+// start starts the clock if it isn't already running. An audio context must exist for the clock to start.
+func (c *sampleClock) start() error {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.player != nil {
+		return nil
+	}
+
+	context := audio.CurrentContext()
+
+	if context == nil {
+		return errors.New("resound: the sample clock requires an audio context to be created")
+	}
+
+	player, err := context.NewPlayer(c)
+	if err != nil {
+		return err
+	}
+
+	c.player = player
+	c.player.Play()
+
+	return nil
+
+}
+
+// elapsed returns the number of samples the clock has played.
+func (c *sampleClock) elapsed() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.samples
+}
+
+// ScheduleAtSample schedules the callback to be called once the global sample clock reaches the given sample position.
+// The callback is called from its own goroutine, so be careful to synchronize any data it touches.
+// The sample clock starts the first time a sound is scheduled against it; an error is returned if there's no audio context to start it with.
+func ScheduleAtSample(samplePos int64, callback func()) error {
+
+	if err := clock.start(); err != nil {
+		return err
+	}
+
+	clock.mutex.Lock()
+	clock.events = append(clock.events, scheduledEvent{sample: samplePos, callback: callback})
+	clock.mutex.Unlock()
+
+	return nil
+
+}
+
+// ElapsedSamples returns the total number of samples the audio context has played since the global sample clock started.
+// This provides a monotonic audio timeline that can be used to schedule audio more reliably than the wall clock.
+// The clock starts the first time it's used; if no audio context exists yet, ElapsedSamples returns 0.
+func ElapsedSamples() int64 {
+	if err := clock.start(); err != nil {
+		return 0
+	}
+	return clock.elapsed()
+}
@@ -1,20 +1,86 @@
 package resound
 
+import (
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
 // DSPChannel represents an audio channel that can have various effects applied to it.
-// Any Players that have a DSPChannel set will take on the effects applied to the channel as well.
+// Any Players that have a DSPChannel set will take on the effects applied to the channel as well. Like a bus on a mixing desk,
+// the channel mixes the Players playing through it (along with any channels routed into it) together, and then its effects
+// process the mix once per buffer, so an effect like a Limiter acts on the sum of everything playing through the channel.
+// Adding, removing, and reordering effects through the DSPChannel's functions is safe to do while audio is playing; modifying
+// the Effects map or EffectOrder slice directly is not.
 type DSPChannel struct {
 	Active      bool
 	Effects     map[any]IEffect
 	EffectOrder []IEffect
 	closed      bool
+
+	players      map[any]*Player
+	voices       []*channelVoice // The Players playing through the channel; replaced rather than modified in place
+	oneShotPool  []*Player
+	maxOneShots  int
+	oneShotMutex sync.Mutex // Guards the one-shot pool, separately from the channel's mutex, as playing a one-shot locks the channel
+
+	autoGain      bool
+	autoGainLevel float64
+
+	output *DSPChannel
+	mixer  *Mixer        // The Mixer the channel belongs to, if any
+	inputs []*DSPChannel // The channels routed into this one that are rendered along with it; replaced rather than modified in place
+	linked bool          // Whether the channel is one of its output channel's inputs
+
+	sends    []channelSend
+	sendRefs int // How many channels send to this one
+
+	volume float64
+	muted  bool
+	level  float64 // The RMS level of the channel's most recently processed audio, before its volume was applied
+
+	duckSource    *DSPChannel
+	duckAmount    float64
+	duckAttack    float64
+	duckRelease   float64
+	duckThreshold float64
+	duckGain      float64
+	duckers       int // How many channels duck from this one, and so need its level measured
+
+	analyzer func(AnalysisFrame)
+
+	timings map[IEffect]time.Duration // How long each effect has taken to apply, while profiling is enabled
+
+	processBlockSize int
+
+	bus channelBus
+
+	// mutex guards the channel's effect order, its Players, and its routing, as these are used by both the game's goroutine
+	// and the audio goroutine.
+	mutex sync.Mutex
 }
 
+// ErrRoutingCycle is returned when routing a DSPChannel's output would cause audio to loop back into the same channel.
+var ErrRoutingCycle = errors.New("resound: routing the DSPChannel to that output would create a cycle")
+
 // NewDSPChannel returns a new DSPChannel.
 func NewDSPChannel() *DSPChannel {
 	dsp := &DSPChannel{
 		Active:      true,
 		Effects:     map[any]IEffect{},
 		EffectOrder: []IEffect{},
+		players:     map[any]*Player{},
+
+		autoGainLevel: 1,
+
+		volume: 1,
+
+		duckThreshold: -40,
+		duckGain:      1,
+
+		bus: channelBus{gain: -1},
 	}
 	return dsp
 }
@@ -22,13 +88,893 @@ func NewDSPChannel() *DSPChannel {
 // Close closes the DSP channel. When closed, any players that play on the channel do not play and automatically close their sources.
 // Closing the channel can be used to stop any sounds that might be playing back on the DSPChannel.
 func (d *DSPChannel) Close() {
+	d.mutex.Lock()
 	d.closed = true
+	d.mutex.Unlock()
 }
 
 // AddEffect adds the specified Effect to the DSPChannel under the given identification. Note that effects added to DSPChannels don't need
-// to specify source streams, as the DSPChannel automatically handles this.
+// to specify source streams, as the DSPChannel applies its effects to the mix of the Players playing through it directly (using
+// IEffect.ApplyEffect()). To play effects as a standalone stream instead, wire them together using ChainEffects() or IEffect.SetSource().
+// If an effect already exists with the given ID, it's replaced, and the new effect is added to the end of the channel's effect order.
 func (d *DSPChannel) AddEffect(id any, effect IEffect) *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	order := d.EffectOrder
+	if existing, ok := d.Effects[id]; ok {
+		order = removeEffect(order, existing)
+		delete(d.timings, existing)
+	}
 	d.Effects[id] = effect
-	d.EffectOrder = append(d.EffectOrder, effect)
+	d.EffectOrder = append(order[:len(order):len(order)], effect)
+	return d
+}
+
+// RemoveEffect removes the effect with the given ID from the DSPChannel. If an effect with the provided ID doesn't exist, this does nothing.
+func (d *DSPChannel) RemoveEffect(id any) *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if effect, ok := d.Effects[id]; ok {
+		delete(d.Effects, id)
+		delete(d.timings, effect)
+		d.EffectOrder = removeEffect(d.EffectOrder, effect)
+	}
+	return d
+}
+
+// MoveEffect moves the effect with the given ID to the given index in the DSPChannel's effect order, which is the order the
+// effects are applied in. The index is clamped to the bounds of the effect order. If an effect with the provided ID doesn't exist, this does nothing.
+func (d *DSPChannel) MoveEffect(id any, toIndex int) *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if effect, ok := d.Effects[id]; ok {
+		d.EffectOrder = moveEffect(d.EffectOrder, effect, toIndex)
+	}
+	return d
+}
+
+// ClearEffects removes all effects from the DSPChannel.
+func (d *DSPChannel) ClearEffects() *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.Effects = map[any]IEffect{}
+	d.EffectOrder = []IEffect{}
+	d.timings = nil
+	return d
+}
+
+// ResetEffects clears the internal state of each of the DSPChannel's effects that holds any (i.e. that implements IResettable),
+// silencing any echoes, reverb tails, or filter history left over from audio that has played through the channel.
+func (d *DSPChannel) ResetEffects() {
+	for _, effect := range d.effectOrder() {
+		if resettable, ok := effect.(IResettable); ok {
+			resettable.Reset()
+		}
+	}
+}
+
+// NewPlayer creates a new Player to play back the given audio stream through the DSPChannel, registering it with the channel under the given ID.
+// This is a shortcut for creating a Player and then setting its DSPChannel. The Player stays registered until it's closed (see Player.Close()),
+// or until another Player is created with the same ID.
+func (d *DSPChannel) NewPlayer(id any, source io.ReadSeeker) (*Player, error) {
+
+	player, err := NewPlayer(source)
+	if err != nil {
+		return nil, err
+	}
+
+	player.SetDSPChannel(d)
+
+	d.registerPlayer(id, player)
+
+	return player, nil
+
+}
+
+// registerPlayer registers the given Player with the DSPChannel under the given ID, so it can be retrieved with Player().
+func (d *DSPChannel) registerPlayer(id any, player *Player) {
+
+	player.mutex.Lock()
+	player.registry, player.registryID = d, id
+	player.mutex.Unlock()
+
+	d.mutex.Lock()
+	d.players[id] = player
+	d.mutex.Unlock()
+
+}
+
+// unregisterPlayer removes the given Player from the DSPChannel's registered Players, if it's still registered under the given ID.
+func (d *DSPChannel) unregisterPlayer(id any, player *Player) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.players[id] == player {
+		delete(d.players, id)
+	}
+}
+
+// Player returns the Player created through the DSPChannel's NewPlayer() function with the given ID.
+// If a Player with the provided ID doesn't exist (or it's been closed), this function will return nil.
+func (d *DSPChannel) Player(id any) *Player {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.players[id]
+}
+
+// effectOrder returns the DSPChannel's current effect order. The effect order is never modified in place (functions like
+// AddEffect() and RemoveEffect() replace it instead), so the returned slice can be iterated over safely without holding the lock.
+func (d *DSPChannel) effectOrder() []IEffect {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.EffectOrder
+}
+
+// effectMap returns a copy of the DSPChannel's Effects map, so it can be iterated over without holding the lock.
+// Unlike the effect order, the map is modified in place when effects are added or removed.
+func (d *DSPChannel) effectMap() map[any]IEffect {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return copyEffectMap(d.Effects)
+}
+
+// Latency returns the total latency of the DSPChannel's effects (see ILatency), plus the latency of its process block size
+// (see SetProcessBlockSize()). This doesn't include the latency of any channels the DSPChannel is routed into, or of the effects
+// of the Players playing through it.
+func (d *DSPChannel) Latency() time.Duration {
+	block := time.Duration(float64(d.ProcessBlockSize()) / float64(SampleRate()) * float64(time.Second))
+	return effectLatency(d.effectOrder(), nil) + block
+}
+
+// PlayOneShot plays the given audio stream through the DSPChannel as a "fire-and-forget" sound, returning the Player used to play it.
+// One-shot Players are pooled by the channel and automatically reused once they finish playing, so you don't need to keep references to them.
+// A reused Player starts out with its default properties (volume, panning, effects, end callback, and so on), so changes made to the
+// returned Player only last until it finishes playing.
+// If the channel's one-shot limit (set through SetMaxOneShots()) has been reached, the oldest playing one-shot is stopped and reused.
+func (d *DSPChannel) PlayOneShot(stream io.ReadSeeker) (*Player, error) {
+
+	d.oneShotMutex.Lock()
+	defer d.oneShotMutex.Unlock()
+
+	pool, player, err := playPooled(d.oneShotPool, d.maxOneShots, true, d, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	d.oneShotPool = pool
+
+	return player, nil
+
+}
+
+// SetMaxOneShots sets the maximum number of one-shot sounds that can play simultaneously through PlayOneShot().
+// A value of 0 or less means there's no limit.
+func (d *DSPChannel) SetMaxOneShots(max int) *DSPChannel {
+	d.oneShotMutex.Lock()
+	d.maxOneShots = max
+	d.oneShotMutex.Unlock()
+	return d
+}
+
+// MaxOneShots returns the maximum number of one-shot sounds that can play simultaneously through PlayOneShot().
+func (d *DSPChannel) MaxOneShots() int {
+	d.oneShotMutex.Lock()
+	defer d.oneShotMutex.Unlock()
+	return d.maxOneShots
+}
+
+// PlayingPlayers returns the Players that are currently playing through the DSPChannel. Players are removed from the channel as soon
+// as they're paused (or moved to another channel), or once their streams end. The returned slice is a copy, so it's safe to modify.
+func (d *DSPChannel) PlayingPlayers() []*Player {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	out := make([]*Player, len(d.voices))
+	for i, v := range d.voices {
+		out[i] = v.player
+	}
+	return out
+}
+
+// addVoice adds the given Player to the Players the DSPChannel mixes, linking the channel into the channels it's routed through so it's rendered.
+func (d *DSPChannel) addVoice(player *Player) {
+
+	d.mutex.Lock()
+
+	for _, v := range d.voices {
+		if v.player == player {
+			d.mutex.Unlock()
+			return
+		}
+	}
+
+	// The voices are replaced rather than modified in place, so a render in progress isn't disturbed.
+	d.voices = append(d.voices[:len(d.voices):len(d.voices)], &channelVoice{player: player, gain: -1})
+
+	d.mutex.Unlock()
+
+	d.connect()
+
+	if d.root() == MasterChannel() {
+		master.start()
+	}
+
+}
+
+// removeVoice removes the given Player from the Players the DSPChannel mixes.
+func (d *DSPChannel) removeVoice(player *Player) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	voices := make([]*channelVoice, 0, len(d.voices))
+	for _, v := range d.voices {
+		if v.player != player {
+			voices = append(voices, v)
+		}
+	}
+	d.voices = voices
+}
+
+// endVoice removes the given Player from the DSPChannel once its stream has ended, marking it as no longer playing.
+func (d *DSPChannel) endVoice(player *Player) {
+	d.removeVoice(player)
+	player.voiceEnded(d)
+}
+
+// SetAutoGain sets whether the DSPChannel should automatically reduce its gain when the mix of all of the Players (and channels)
+// playing through it would clip. This acts like a gentle limiter on the channel as a whole, which is useful for keeping busy
+// channels (like one playing many sound effects simultaneously) from overloading. The gain is applied to the mix before the
+// channel's effects, so it catches the mix before it's clipped to full scale.
+func (d *DSPChannel) SetAutoGain(autoGain bool) *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.autoGain = autoGain
+	if !autoGain {
+		d.autoGainLevel = 1
+	}
+	return d
+}
+
+// AutoGain returns if the DSPChannel automatically reduces its gain to keep its combined output from clipping.
+func (d *DSPChannel) AutoGain() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.autoGain
+}
+
+// AutoGainLevel returns the current gain multiplier applied by the DSPChannel's automatic gain, ranging from 0 to 1.
+func (d *DSPChannel) AutoGainLevel() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.autoGainLevel
+}
+
+// SetOutput routes the DSPChannel into the given output DSPChannel, like a bus on a mixer; the mix of the Players playing through this channel
+// has this channel's effects applied, and is then mixed into the output channel along with anything else playing through it, which is
+// processed by the output channel's effects in turn, and so on down the chain until reaching a channel with no output. For example, "music"
+// and "sfx" channels could both be routed into a "bus" channel with a Limiter effect, which then limits the sum of the two.
+// Passing nil routes the channel into the master channel (see MasterChannel()), which plays to the audio context.
+// If routing to the given channel would create a cycle (including through the channels' sends; see AddSend()), SetOutput returns
+// ErrRoutingCycle and leaves the routing unchanged.
+// As every channel is routed into the master channel in the end, the master channel itself can't be routed into another channel.
+// While the channel belongs to a Mixer, it outputs into the Mixer regardless of its output channel.
+func (d *DSPChannel) SetOutput(output *DSPChannel) error {
+
+	if output != nil && d == MasterChannel() {
+		return ErrRoutingCycle
+	}
+
+	if output != nil && output.reaches(d) {
+		return ErrRoutingCycle
+	}
+
+	d.reroute(func() { d.output = output })
+
+	return nil
+
+}
+
+// Output returns the DSPChannel this channel is routed into, or nil if it's routed into the master channel (or is the master channel).
+func (d *DSPChannel) Output() *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.output
+}
+
+// outputChannel returns the DSPChannel this channel's audio goes to next - either the channel it's routed into, or the master
+// channel if it isn't routed into one. The master channel's output channel is nil, as it plays to the audio context, and so is
+// the output channel of a channel that belongs to a Mixer, as it outputs into the Mixer.
+func (d *DSPChannel) outputChannel() *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.outputChannelLocked()
+}
+
+// outputChannelLocked is outputChannel() for when the DSPChannel's mutex is already held.
+func (d *DSPChannel) outputChannelLocked() *DSPChannel {
+	if d.mixer != nil {
+		return nil
+	}
+	if d.output != nil {
+		return d.output
+	}
+	if d == MasterChannel() {
+		return nil
+	}
+	return MasterChannel()
+}
+
+// root returns the last DSPChannel along the route from this channel - the master channel, or the channel of a Mixer.
+func (d *DSPChannel) root() *DSPChannel {
+	c := d
+	for output := c.outputChannel(); output != nil; output = c.outputChannel() {
+		c = output
+	}
+	return c
+}
+
+// bufferedBytes returns the number of bytes of audio that have been rendered through the DSPChannel's route, but not heard yet.
+func (d *DSPChannel) bufferedBytes() int64 {
+	if d.root() == MasterChannel() {
+		return master.buffered()
+	}
+	return 0
+}
+
+// reroute changes where the DSPChannel outputs to using the given function, moving the channel from its old output channel's
+// inputs to its new output channel's.
+func (d *DSPChannel) reroute(change func()) {
+
+	d.mutex.Lock()
+	if d.linked {
+		d.outputChannelLocked().removeInput(d)
+		d.linked = false
+	}
+	change()
+	d.mutex.Unlock()
+
+	d.connect()
+
+}
+
+// connect links the DSPChannel into its output channel's inputs (and so on down the chain), so it's rendered along with the channels
+// it's routed through. Channels that have nothing routed into them are unlinked again once their audio dies away (see disconnectIfIdle()).
+func (d *DSPChannel) connect() {
+
+	for c := d; c != nil; {
+
+		c.mutex.Lock()
+
+		output := c.outputChannelLocked()
+
+		// If the channel is already linked, so are the channels it's routed through.
+		if c.linked || output == nil {
+			c.mutex.Unlock()
+			return
+		}
+
+		c.linked = true
+		output.addInput(c)
+
+		c.mutex.Unlock()
+
+		c = output
+
+	}
+
+}
+
+// disconnectIfIdle unlinks the DSPChannel from its output channel's inputs if nothing is routed into it, so idle channels aren't rendered.
+// Channels that other channels send to stay linked, so they're rendered along with the channels that send to them.
+func (d *DSPChannel) disconnectIfIdle() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if !d.linked || len(d.voices) > 0 || len(d.inputs) > 0 || d.sendRefs > 0 {
+		return
+	}
+	d.linked = false
+	d.outputChannelLocked().removeInput(d)
+}
+
+// idleRoute returns if no Players are playing through the DSPChannel or the channels routed into it, and the audio of each of those
+// channels has died away (see renderBus()).
+func (d *DSPChannel) idleRoute() bool {
+
+	d.mutex.Lock()
+	voices, inputs := len(d.voices), d.inputs
+	d.mutex.Unlock()
+
+	d.bus.mutex.Lock()
+	dormant := d.bus.dormant
+	d.bus.mutex.Unlock()
+
+	if voices > 0 || !dormant {
+		return false
+	}
+
+	for _, input := range inputs {
+		if !input.idleRoute() {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// addInput adds the given channel to the DSPChannel's inputs. The inputs are replaced rather than modified in place, so a render in
+// progress isn't disturbed.
+func (d *DSPChannel) addInput(input *DSPChannel) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.inputs = append(d.inputs[:len(d.inputs):len(d.inputs)], input)
+	graphVersion.Add(1)
+}
+
+// removeInput removes the given channel from the DSPChannel's inputs.
+func (d *DSPChannel) removeInput(input *DSPChannel) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	inputs := make([]*DSPChannel, 0, len(d.inputs))
+	for _, c := range d.inputs {
+		if c != input {
+			inputs = append(inputs, c)
+		}
+	}
+	d.inputs = inputs
+	graphVersion.Add(1)
+}
+
+// inputList returns the channels routed into the DSPChannel that are rendered along with it.
+func (d *DSPChannel) inputList() []*DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.inputs
+}
+
+// channelSettings is a snapshot of the settings a DSPChannel processes a buffer with.
+type channelSettings struct {
+	effects    []IEffect
+	blockSize  int
+	autoGain   bool
+	volume     float64
+	muted      bool
+	duckSource *DSPChannel
+	duckGain   float64
+	metered    bool
+	analyzer   func(AnalysisFrame)
+	sends      []channelSend
+}
+
+// idle returns if the settings leave the DSPChannel's mix unchanged, so processing it can be skipped entirely.
+func (s channelSettings) idle() bool {
+	return len(s.effects) == 0 && s.blockSize == 0 && !s.autoGain && s.volume == 1 && !s.muted && s.duckSource == nil &&
+		s.duckGain == 1 && !s.metered && s.analyzer == nil
+}
+
+// settings returns a snapshot of the DSPChannel's processing settings.
+func (d *DSPChannel) settings() channelSettings {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return channelSettings{
+		effects:    d.EffectOrder,
+		blockSize:  d.processBlockSize,
+		autoGain:   d.autoGain,
+		volume:     d.volume,
+		muted:      d.muted,
+		duckSource: d.duckSource,
+		duckGain:   d.duckGain,
+		metered:    d.duckers > 0,
+		analyzer:   d.analyzer,
+		sends:      d.sends,
+	}
+}
+
+// process applies the DSPChannel's processing to the mix in its bus using the given settings: its automatic gain, its effects,
+// its volume and ducking, and its analyzer. The audio of Players that have already applied the channel's effects themselves
+// (see EffectRouting) is added after the effects. The processed audio is left in the bus's output buffer.
+func (d *DSPChannel) process(settings channelSettings) {
+
+	b := &d.bus
+
+	// A channel that doesn't change its mix (like the master channel, by default) only has to convert it.
+	if settings.idle() && (b.gain == 1 || b.gain < 0) {
+		b.gain = 1
+		b.store()
+		b.storeProcessed()
+		return
+	}
+
+	if settings.autoGain {
+		d.applyAutoGain()
+	}
+
+	b.store()
+
+	if settings.blockSize > 0 {
+		d.applyEffectBlocks(settings.effects, settings.blockSize)
+	} else {
+		d.applyEffectOrder(settings.effects, b.out, len(b.out))
+	}
+
+	b.storeProcessed()
+
+	if settings.metered {
+		d.measureLevel()
+	}
+
+	d.updateDuck(settings.duckSource)
+
+	d.applyVolume(settings)
+
+	if settings.analyzer != nil {
+		settings.analyzer(newAnalysisFrame(nil, b.out, len(b.out)))
+	}
+
+}
+
+// applyEffectOrder applies each of the given effects of the DSPChannel to the given audio, in order.
+func (d *DSPChannel) applyEffectOrder(effects []IEffect, data []byte, bytesRead int) {
+	if profiling.Load() {
+		for _, effect := range effects {
+			d.applyEffectTimed(effect, data, bytesRead)
+		}
+	} else {
+		for _, effect := range effects {
+			effect.ApplyEffect(data, bytesRead)
+		}
+	}
+}
+
+// processBlock collects a DSPChannel's mix into blocks, so the channel's effects can process it a block at a time.
+type processBlock struct {
+	input  []byte // The block of audio being collected
+	output []byte // The most recently processed block of audio, which is being played
+	pos    int    // The position (in bytes) in the current blocks
+}
+
+// SetProcessBlockSize sets the number of frames the DSPChannel's effects process at a time. By default (or if the size is 0 or less),
+// the effects process audio in whatever size buffers Ebitengine reads, which can be small. With a block size set, the audio is
+// collected until a full block is ready and then processed all at once, which reduces the per-buffer overhead of CPU-heavy effects
+// (like recalculating filter coefficients) at the cost of delaying the audio by the block size (e.g. 2048 frames is about 46 milliseconds
+// at 44100 Hz). Changing the block size clears any audio already collected.
+func (d *DSPChannel) SetProcessBlockSize(frames int) *DSPChannel {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if frames < 0 {
+		frames = 0
+	}
+	d.processBlockSize = frames
+	return d
+}
+
+// ProcessBlockSize returns the number of frames the DSPChannel's effects process at a time, or 0 if they process audio as it's read.
+func (d *DSPChannel) ProcessBlockSize() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.processBlockSize
+}
+
+// applyEffectBlocks applies the given effects of the DSPChannel to its processed audio a block of the given size at a time; each frame of
+// audio is swapped for the frame from the same position in the previously processed block, and once a block is full, it's processed
+// and starts playing.
+func (d *DSPChannel) applyEffectBlocks(effects []IEffect, blockSize int) {
+
+	b := &d.bus
+	data := b.out
+
+	if b.block == nil || len(b.block.input) != blockSize*4 {
+		b.block = &processBlock{input: make([]byte, blockSize*4), output: make([]byte, blockSize*4)}
+	}
+
+	block := b.block
+
+	for offset := 0; offset < len(data); {
+
+		n := len(block.input) - block.pos
+		if n > len(data)-offset {
+			n = len(data) - offset
+		}
+
+		copy(block.input[block.pos:block.pos+n], data[offset:offset+n])
+		copy(data[offset:offset+n], block.output[block.pos:block.pos+n])
+
+		block.pos += n
+		offset += n
+
+		if block.pos >= len(block.input) {
+			d.applyEffectOrder(effects, block.input, len(block.input))
+			block.input, block.output = block.output, block.input
+			block.pos = 0
+		}
+
+	}
+
+}
+
+const (
+	autoGainAttack  = 0.01 // How long (in seconds) it takes for the automatic gain to react to the channel getting louder
+	autoGainRelease = 0.25 // How long (in seconds) it takes for the automatic gain to recover once the channel gets quieter
+)
+
+// measureLevel records the RMS level of the DSPChannel's processed audio, for the channels that duck from it (see DuckFrom()).
+// Channels that nothing ducks from aren't measured.
+func (d *DSPChannel) measureLevel() {
+
+	_, rms := AudioBuffer(d.bus.out).RMS()
+
+	d.mutex.Lock()
+	d.level = rms
+	d.mutex.Unlock()
+
+}
+
+// applyAutoGain moves the DSPChannel's automatic gain towards the gain that keeps its mix from clipping, and applies it to the mix.
+func (d *DSPChannel) applyAutoGain() {
+
+	b := &d.bus
+	frames := len(b.mix) / 2
+
+	if frames == 0 {
+		return
+	}
+
+	target := 1.0
+	if peak := b.peak(); peak > 1 {
+		target = 1 / peak
+	}
+
+	d.mutex.Lock()
+	start := d.autoGainLevel
+	d.mutex.Unlock()
+
+	tau := autoGainRelease
+	if target < start {
+		tau = autoGainAttack
+	}
+
+	dt := float64(frames) / float64(SampleRate())
+	end := start + (target-start)*(1-math.Exp(-dt/tau))
+
+	// Ramp the gain across the buffer to avoid clicks.
+	for i := 0; i < frames; i++ {
+		gain := start + (end-start)*(float64(i+1)/float64(frames))
+		b.mix[i*2] *= gain
+		b.mix[i*2+1] *= gain
+	}
+
+	d.mutex.Lock()
+	if d.autoGain {
+		d.autoGainLevel = end
+	}
+	d.mutex.Unlock()
+
+}
+
+// SetVolume sets the volume of the DSPChannel, which is applied after the channel's effects. 1 is the original volume, and 0 is the minimum value.
+func (d *DSPChannel) SetVolume(volume float64) *DSPChannel {
+	if volume < 0 {
+		volume = 0
+	}
+	d.mutex.Lock()
+	d.volume = volume
+	d.mutex.Unlock()
+	return d
+}
+
+// Volume returns the volume of the DSPChannel.
+func (d *DSPChannel) Volume() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.volume
+}
+
+// SetMuted sets whether the DSPChannel is muted. Unlike deactivating the channel (by setting DSPChannel.Active to false),
+// muting a channel keeps the Players playing through it advancing through their streams - they're just silent. This means
+// unmuting the channel picks up where the audio would be, rather than where it was when it was muted.
+func (d *DSPChannel) SetMuted(muted bool) *DSPChannel {
+	d.mutex.Lock()
+	d.muted = muted
+	d.mutex.Unlock()
+	return d
+}
+
+// Muted returns if the DSPChannel is muted.
+func (d *DSPChannel) Muted() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.muted
+}
+
+// DuckFrom sets the DSPChannel to duck (automatically lower its volume) whenever the given source DSPChannel is playing audio
+// louder than the duck threshold (see SetDuckThreshold()). This is useful for lowering music while dialogue plays, for example.
+// amountDB is how far the volume is lowered in decibels (e.g. -12), while attack and release are how long (in seconds) it takes
+// for the volume to lower once the source channel starts playing and to recover once it stops, respectively.
+// Passing a nil source stops ducking.
+func (d *DSPChannel) DuckFrom(source *DSPChannel, amountDB, attack, release float64) *DSPChannel {
+
+	d.mutex.Lock()
+	old := d.duckSource
+	d.duckSource = source
+	d.duckAmount = math.Pow(10, math.Min(amountDB, 0)/20)
+	d.duckAttack = math.Max(attack, 0)
+	d.duckRelease = math.Max(release, 0)
+	d.mutex.Unlock()
+
+	if old != source {
+		old.addDucker(-1)
+		source.addDucker(1)
+	}
+
 	return d
+
+}
+
+// addDucker changes the number of channels that duck from the DSPChannel by the given amount. This does nothing if the channel is nil.
+func (d *DSPChannel) addDucker(n int) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	d.duckers += n
+	d.mutex.Unlock()
+}
+
+// SetDuckThreshold sets the level (in decibels) the source channel's audio has to exceed for this DSPChannel to duck. Defaults to -40.
+func (d *DSPChannel) SetDuckThreshold(thresholdDB float64) *DSPChannel {
+	d.mutex.Lock()
+	d.duckThreshold = thresholdDB
+	d.mutex.Unlock()
+	return d
+}
+
+// DuckThreshold returns the level (in decibels) the source channel's audio has to exceed for this DSPChannel to duck.
+func (d *DSPChannel) DuckThreshold() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.duckThreshold
+}
+
+// DuckGain returns the gain multiplier currently applied to the DSPChannel by ducking, ranging from 0 to 1 (1 being no ducking).
+func (d *DSPChannel) DuckGain() float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.duckGain
+}
+
+// updateDuck moves the DSPChannel's duck gain towards its target according to the level of the given duck source channel,
+// over the duration of the buffer being processed.
+func (d *DSPChannel) updateDuck(source *DSPChannel) {
+
+	dt := float64(len(d.bus.out)/4) / float64(SampleRate())
+
+	target := 1.0
+
+	if source != nil {
+
+		source.mutex.Lock()
+		rms := source.level
+
+		// The source's level is measured before its volume is applied, so that's taken into account here.
+		if source.muted {
+			rms = 0
+		}
+		rms *= source.volume
+		source.mutex.Unlock()
+
+		d.mutex.Lock()
+		if rms > 0 && 20*math.Log10(rms) > d.duckThreshold {
+			target = d.duckAmount
+		}
+		d.mutex.Unlock()
+
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	tau := d.duckRelease
+	if target < d.duckGain {
+		tau = d.duckAttack
+	}
+
+	if tau <= 0 {
+		d.duckGain = target
+	} else {
+		d.duckGain += (target - d.duckGain) * (1 - math.Exp(-dt/tau))
+	}
+
+}
+
+// applyVolume applies the DSPChannel's volume and duck gain (or silence, if it's muted) to its processed audio.
+func (d *DSPChannel) applyVolume(settings channelSettings) {
+
+	b := &d.bus
+
+	d.mutex.Lock()
+	target := settings.volume * d.duckGain
+	d.mutex.Unlock()
+
+	if settings.muted {
+		target = 0
+	}
+
+	start := b.gain
+	if start < 0 {
+		start = target
+	}
+	b.gain = target
+
+	if start == 1 && target == 1 {
+		return
+	}
+
+	audioBuffer := AudioBuffer(b.out)
+	frames := audioBuffer.Len()
+
+	// Ramp the gain across the buffer from where it was last buffer to avoid clicks.
+	for i := 0; i < frames; i++ {
+		gain := start + (target-start)*(float64(i+1)/float64(frames))
+		l, r := audioBuffer.Get(i)
+		audioBuffer.Set(i, l*gain, r*gain)
+	}
+
+}
+
+// SetAnalyzer sets a callback that receives the levels of each buffer of audio played through the DSPChannel, after the channel's
+// effects and volume have been applied. The callback receives the channel's whole mix, so AnalysisFrame.Player is nil.
+// The callback runs in the audio thread, so it should return quickly. Passing nil removes the analyzer.
+func (d *DSPChannel) SetAnalyzer(analyzer func(AnalysisFrame)) *DSPChannel {
+	d.mutex.Lock()
+	d.analyzer = analyzer
+	d.mutex.Unlock()
+	return d
+}
+
+// Clone returns a new DSPChannel with the same settings and effects as the original. Each effect is cloned
+// using its Clone() function, so the new channel's effects don't share state with the original channel's effects.
+// The clone keeps the effects' IDs and order, as well as the channel's active state, volume, muting, ducking, automatic gain,
+// and output routing. The Players playing through the original channel (including its one-shot Players) aren't carried over.
+// This makes it easy to create several independent channels from one template, like a set of identical reverb sends.
+func (d *DSPChannel) Clone() *DSPChannel {
+
+	newDSP := NewDSPChannel()
+	newDSP.maxOneShots = d.MaxOneShots()
+
+	d.mutex.Lock()
+
+	newDSP.Active = d.Active
+	newDSP.closed = d.closed
+	newDSP.autoGain = d.autoGain
+	newDSP.output = d.output
+	newDSP.volume = d.volume
+	newDSP.muted = d.muted
+	newDSP.duckSource = d.duckSource
+	newDSP.duckAmount = d.duckAmount
+	newDSP.duckAttack = d.duckAttack
+	newDSP.duckRelease = d.duckRelease
+	newDSP.duckThreshold = d.duckThreshold
+	newDSP.processBlockSize = d.processBlockSize
+	newDSP.sends = append([]channelSend{}, d.sends...)
+
+	clones := make(map[IEffect]IEffect, len(d.EffectOrder))
+
+	for _, effect := range d.EffectOrder {
+		clone := effect.Clone()
+		clones[effect] = clone
+		newDSP.EffectOrder = append(newDSP.EffectOrder, clone)
+	}
+
+	for id, effect := range d.Effects {
+		newDSP.Effects[id] = clones[effect]
+	}
+
+	d.mutex.Unlock()
+
+	// The clone's duck source and send targets are updated once the original is unlocked, as a channel can duck from itself.
+	newDSP.duckSource.addDucker(1)
+
+	for _, s := range newDSP.sends {
+		s.target.addSendRef(1)
+	}
+
+	return newDSP
+
 }
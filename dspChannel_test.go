@@ -0,0 +1,555 @@
+package resound
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentControl plays audio through a DSPChannel on one goroutine while its settings and effects (and those of the Players
+// playing through it) are changed from others, as a game would; any unguarded state shows up as a data race with -race.
+func TestConcurrentControl(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	channel := NewDSPChannel().SetMaxOneShots(2)
+	music := NewDSPChannel()
+	music.DuckFrom(channel, -12, 0.01, 0.1)
+
+	mixer := NewMixer(channel, music)
+
+	player := newPlayer(bytes.NewReader(testSine(44100*10, 440, 0.25)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	// The pool is filled ahead of time, so playing one-shots reuses these rather than creating Players through the audio context.
+	for i := 0; i < 2; i++ {
+		oneShot := newPlayer(bytes.NewReader(testSine(441, 880, 0.25)))
+		oneShot.SetDSPChannel(channel)
+		channel.oneShotPool = append(channel.oneShotPool, oneShot)
+	}
+
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	// The audio thread
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		buffer := make([]byte, 512*4)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				mixer.Read(buffer)
+			}
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			channel.PlayOneShot(bytes.NewReader(testSine(441, 880, 0.25)))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+
+		player.AddEffect("gain", &testEffect{gain: 0.5})
+		channel.AddEffect("gain", &testEffect{gain: 0.5})
+
+		player.SetPan(float64(i%3) - 1).SetMuted(i%2 == 0).SetSolo(i%4 == 0)
+		player.SetPlaybackRate(1+float64(i%2)/2).SetLoopRegion(0, time.Second)
+		player.SetAnalyzer(func(AnalysisFrame) {})
+
+		channel.SetVolume(float64(i % 2)).SetMuted(i%3 == 0).SetAutoGain(i%2 == 0).SetDuckThreshold(-30)
+		channel.SetAnalyzer(func(AnalysisFrame) {})
+
+		if i%10 == 0 {
+			player.FadeOut(time.Millisecond * 5)
+		} else if i%10 == 5 {
+			player.FadeIn(time.Millisecond * 5)
+		}
+
+		player.Pan()
+		player.Muted()
+		player.IsFading()
+		channel.Volume()
+		channel.DuckGain()
+		channel.AutoGainLevel()
+
+		player.RemoveEffect("gain")
+		channel.RemoveEffect("gain")
+
+		time.Sleep(time.Millisecond / 2)
+
+	}
+
+	close(done)
+	wg.Wait()
+
+}
+
+// TestPlayingPlayers checks that PlayingPlayers returns every Player playing through the channel, and leaves out those that have finished.
+func TestPlayingPlayers(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	long := newPlayer(bytes.NewReader(testSine(44100, 440, 0.25)))
+	short := newPlayer(bytes.NewReader(testSine(256, 440, 0.25)))
+
+	for _, player := range []*Player{long, short} {
+		player.SetDSPChannel(channel)
+		mixer.Add(player)
+	}
+
+	if playing := channel.PlayingPlayers(); len(playing) != 2 || playing[0] != long || playing[1] != short {
+		t.Fatalf("expected both Players to be playing, got %v", playing)
+	}
+
+	// The short Player's stream ends partway through the first buffer, and returns io.EOF on the next read.
+	buffer := make([]byte, 512*4)
+	mixer.Read(buffer)
+	mixer.Read(buffer)
+
+	if playing := channel.PlayingPlayers(); len(playing) != 1 || playing[0] != long {
+		t.Errorf("expected only the Player that hasn't finished to be playing, got %v", playing)
+	}
+
+}
+
+// TestStoppedPlayersRemoved checks that stopping several Players at once removes all of them from the channel. There's no separate
+// cleanup pass to miss any; each Player is removed from the channel's voices as it's paused.
+func TestStoppedPlayersRemoved(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	players := []*Player{}
+
+	for i := 0; i < 3; i++ {
+		player := newPlayer(bytes.NewReader(testSine(44100, 440, 0.25)))
+		player.SetDSPChannel(channel)
+		mixer.Add(player)
+		players = append(players, player)
+	}
+
+	buffer := make([]byte, 256*4)
+	mixer.Read(buffer)
+
+	for _, player := range players {
+		player.Stop()
+	}
+
+	if playing := channel.PlayingPlayers(); len(playing) != 0 {
+		t.Errorf("expected no Players to be playing after stopping all three, got %d", len(playing))
+	}
+
+	mixer.Read(buffer)
+
+	if l, _ := AudioBuffer(buffer).Get(255); l != 0 {
+		t.Errorf("expected the channel to be silent once its Players are stopped, got %f", l)
+	}
+
+}
+
+// TestDSPChannelCloneEffects checks that a cloned channel keeps its original's effect order and settings, but processes audio
+// with effects of its own rather than sharing the original's.
+func TestDSPChannelCloneEffects(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	first, second := &testEffect{gain: 0.5}, &testEffect{gain: 0.25}
+
+	channel := NewDSPChannel().SetVolume(0.5)
+	channel.AddEffect("first", first).AddEffect("second", second)
+
+	clone := channel.Clone()
+
+	if clone.Volume() != 0.5 {
+		t.Errorf("expected the clone to keep the original's volume, got %f", clone.Volume())
+	}
+
+	order := clone.effectOrder()
+
+	if len(order) != 2 || order[0] != clone.Effects["first"] || order[1] != clone.Effects["second"] {
+		t.Fatal("expected the clone to keep the original's effect IDs and order")
+	}
+
+	for i, original := range []*testEffect{first, second} {
+		if cloned := order[i].(*testEffect); cloned == original || cloned.gain != original.gain {
+			t.Errorf("expected effect %d to be a copy of the original's", i)
+		}
+	}
+
+	mixer := NewMixer(clone)
+	player := newPlayer(bytes.NewReader(testConstant(1024, 0.8)))
+	player.SetDSPChannel(clone)
+	mixer.Add(player)
+
+	buffer := make([]byte, 256*4)
+	mixer.Read(buffer)
+
+	if first.frames != 0 || second.frames != 0 {
+		t.Error("expected playing through the clone to leave the original's effects untouched")
+	}
+
+	if cloned := order[0].(*testEffect); cloned.frames != 256 {
+		t.Errorf("expected the clone's effects to process its audio, got %d frames", cloned.frames)
+	}
+
+	if l, _ := AudioBuffer(buffer).Get(255); math.Abs(l-0.8*0.5*0.25*0.5) > 0.001 {
+		t.Errorf("expected the clone to apply its effects and volume, got %f", l)
+	}
+
+}
+
+// TestChannelCleanup checks the lifecycle of a channel routed into another: it's linked in while Players play through it, unlinked
+// once its audio has died away, and closing it closes the Players playing through it and releases their streams.
+func TestChannelCleanup(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	bus, sfx := NewDSPChannel(), NewDSPChannel()
+	sfx.SetOutput(bus)
+
+	mixer := NewMixer(bus)
+
+	player := newPlayer(bytes.NewReader(testSine(256, 440, 0.25)))
+	player.SetDSPChannel(sfx)
+	mixer.Add(player)
+
+	if inputs := bus.inputList(); len(inputs) != 1 || inputs[0] != sfx {
+		t.Fatal("expected the channel to be linked into its output while a Player plays through it")
+	}
+
+	buffer := make([]byte, 256*4)
+	for i := 0; i < 4; i++ {
+		mixer.Read(buffer)
+	}
+
+	if len(bus.inputList()) != 0 {
+		t.Error("expected the channel to be unlinked from its output once its audio died away")
+	}
+
+	player = newPlayer(bytes.NewReader(testSine(44100, 440, 0.25)))
+	player.SetDSPChannel(sfx)
+	mixer.Add(player)
+
+	sfx.Close()
+	mixer.Read(buffer)
+
+	if player.IsPlaying() || player.Source != nil {
+		t.Error("expected closing the channel to stop its Players and release their streams")
+	}
+
+	if l, _ := AudioBuffer(buffer).Get(255); l != 0 {
+		t.Errorf("expected a closed channel to be silent, got %f", l)
+	}
+
+}
+
+// TestSoloAndMute plays three Players through a DSPChannel, checking which of them are heard as they're soloed and muted,
+// and that the silenced Players keep advancing.
+func TestSoloAndMute(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	players := []*Player{}
+	sources := []*bytes.Reader{}
+
+	for _, level := range []float64{0.1, 0.2, 0.4} {
+		source := bytes.NewReader(testConstant(44100, level))
+		player := newPlayer(source)
+		player.SetDSPChannel(channel)
+		mixer.Add(player)
+		players = append(players, player)
+		sources = append(sources, source)
+	}
+
+	a, b, c := players[0], players[1], players[2]
+
+	tests := []struct {
+		name     string
+		muted    []*Player
+		soloed   []*Player
+		expected float64
+	}{
+		{"none", nil, nil, 0.7},
+		{"solo b", nil, []*Player{b}, 0.2},
+		{"solo a and c", nil, []*Player{a, c}, 0.5},
+		{"mute a", []*Player{a}, nil, 0.6},
+		{"solo b, mute a", []*Player{a}, []*Player{b}, 0.2},
+		{"solo and mute b", []*Player{b}, []*Player{b}, 0},
+	}
+
+	buffer := make([]byte, 256*4)
+
+	for _, test := range tests {
+
+		for _, p := range players {
+			p.SetMuted(false).SetSolo(false)
+		}
+		for _, p := range test.muted {
+			p.SetMuted(true)
+		}
+		for _, p := range test.soloed {
+			p.SetSolo(true)
+		}
+
+		// The first buffer ramps to the new volumes to avoid clicks.
+		mixer.Read(buffer)
+		mixer.Read(buffer)
+
+		if l, r := AudioBuffer(buffer).Get(255); math.Abs(l-test.expected) > 0.001 || math.Abs(r-test.expected) > 0.001 {
+			t.Errorf("%s: expected the channel's output to be %f, got %f, %f", test.name, test.expected, l, r)
+		}
+
+		for i, source := range sources {
+			if source.Len() != sources[0].Len() {
+				t.Errorf("%s: expected silenced Players to keep advancing, but Player %d is at %d bytes left rather than %d", test.name, i, source.Len(), sources[0].Len())
+			}
+		}
+
+	}
+
+}
+
+// testSquare is an effect that squares audio (keeping its sign), so applying it before or after another effect, or applying it twice,
+// changes its output, while silence stays silent.
+type testSquare struct{}
+
+func (e *testSquare) ApplyEffect(data []byte, bytesRead int) {
+	buffer := AudioBuffer(data[:bytesRead])
+	for i := 0; i < buffer.Len(); i++ {
+		l, r := buffer.Get(i)
+		buffer.Set(i, l*math.Abs(l), r*math.Abs(r))
+	}
+}
+
+func (e *testSquare) Read(p []byte) (int, error)                   { return 0, io.EOF }
+func (e *testSquare) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+func (e *testSquare) Clone() IEffect                               { return &testSquare{} }
+func (e *testSquare) SetSource(source io.ReadSeeker)               {}
+
+func TestEffectRouting(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	channel.AddEffect("square", &testSquare{})
+
+	mixer := NewMixer(channel)
+
+	player := newPlayer(bytes.NewReader(testConstant(44100, 0.8)))
+	player.AddEffect("first", &testEffect{gain: 0.5})
+	player.AddEffect("second", &testEffect{gain: 0.5})
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	tests := []struct {
+		name        string
+		routing     EffectRouting
+		insertIndex int
+		expected    float64
+	}{
+		{"player then channel", EffectRoutingPlayerThenChannel, 0, 0.04},
+		{"channel then player", EffectRoutingChannelThenPlayer, 0, 0.16},
+		{"custom", EffectRoutingCustom, 1, 0.08},
+		{"custom past the end", EffectRoutingCustom, 5, 0.04},
+	}
+
+	buffer := make([]byte, 256*4)
+
+	for _, test := range tests {
+
+		player.SetEffectRouting(test.routing).SetChannelInsertIndex(test.insertIndex)
+
+		mixer.Read(buffer)
+
+		// The channel's effect is applied exactly once, whether by the channel or the Player.
+		if l, r := AudioBuffer(buffer).Get(255); math.Abs(l-test.expected) > 0.001 || math.Abs(r-test.expected) > 0.001 {
+			t.Errorf("%s: expected the channel's output to be %f, got %f, %f", test.name, test.expected, l, r)
+		}
+
+	}
+
+	// The Player's copies of the channel's effects are remade when the channel's effects change.
+	player.SetEffectRouting(EffectRoutingChannelThenPlayer)
+	channel.AddEffect("square", &testEffect{gain: 0.5})
+
+	mixer.Read(buffer)
+
+	if l, _ := AudioBuffer(buffer).Get(255); math.Abs(l-0.1) > 0.001 {
+		t.Errorf("expected the Player to use the channel's new effect, got %f", l)
+	}
+
+}
+
+func TestIdleRoute(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	bus := NewDSPChannel()
+	mixer := NewMixer(bus)
+
+	channel := NewDSPChannel()
+	channel.SetOutput(bus)
+
+	player := newPlayer(bytes.NewReader(testConstant(512, 0.5)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	if bus.idleRoute() {
+		t.Fatalf("expected the route to be busy while a Player is playing through it")
+	}
+
+	buffer := make([]byte, 256*4)
+
+	// Once the Player ends, each channel along the route renders until it finds its audio has died away.
+	for i := 0; i < 8; i++ {
+		mixer.Read(buffer)
+	}
+
+	if !bus.idleRoute() {
+		t.Errorf("expected the route to be idle once its Player has ended and its audio has died away")
+	}
+
+	player.Rewind()
+	player.Play()
+
+	if bus.idleRoute() {
+		t.Errorf("expected the route to be busy again once a Player plays through it again")
+	}
+
+}
+
+// TestSnapshotConcurrently takes snapshots of a Player's and a DSPChannel's effects while effects are added and removed from another
+// goroutine; reading the Effects maps without the lock shows up as a data race with -race.
+func TestSnapshotConcurrently(t *testing.T) {
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	channel := NewDSPChannel()
+	player := newPlayer(bytes.NewReader(testConstant(64, 0.5)))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			player.AddEffect(i%4, &testEffect{gain: 0.5})
+			channel.AddEffect(i%4, &testEffect{gain: 0.5})
+			player.RemoveEffect((i + 2) % 4)
+			channel.RemoveEffect((i + 2) % 4)
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		player.Restore(player.Snapshot())
+		channel.Restore(channel.Snapshot())
+	}
+
+	<-done
+
+}
+
+func TestPlayerRegistry(t *testing.T) {
+
+	channel := NewDSPChannel()
+
+	first := newPlayer(bytes.NewReader(testConstant(64, 0.5)))
+	second := newPlayer(bytes.NewReader(testConstant(64, 0.5)))
+
+	channel.registerPlayer("sound", first)
+
+	if channel.Player("sound") != first {
+		t.Fatalf("expected the registered Player to be returned")
+	}
+
+	// Registering another Player under the same ID replaces the first, which then leaves it alone when closed.
+	channel.registerPlayer("sound", second)
+	first.Close()
+
+	if channel.Player("sound") != second {
+		t.Errorf("expected closing a replaced Player to leave its replacement registered")
+	}
+
+	second.Close()
+
+	if channel.Player("sound") != nil || len(channel.players) != 0 {
+		t.Errorf("expected closing a registered Player to remove it from its channel")
+	}
+
+}
+
+func TestOneShotReuseResets(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	NewMixer(channel)
+
+	// The pool is filled ahead of time, so playing one-shots reuses this rather than creating a Player through the audio context.
+	pooled := newPlayer(bytes.NewReader(testConstant(64, 0.5)))
+	pooled.SetDSPChannel(channel)
+	channel.oneShotPool = append(channel.oneShotPool, pooled)
+
+	player, err := channel.PlayOneShot(bytes.NewReader(testConstant(64, 0.5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player.SetVolume(0.25)
+	player.SetPan(1).SetMuted(true).SetSolo(true).SetOnEnd(func() {})
+	player.SetLoopRegion(0, time.Millisecond).SetPlaybackRate(2).SetPreservePitch(true)
+	player.AddEffect("gain", &testEffect{gain: 0.5})
+	player.FadeOut(time.Second)
+	player.Pause()
+
+	reused, err := channel.PlayOneShot(bytes.NewReader(testConstant(64, 0.5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reused != player {
+		t.Fatalf("expected the finished one-shot Player to be reused")
+	}
+
+	start, end := reused.LoopRegion()
+
+	for name, changed := range map[string]bool{
+		"volume":        reused.Volume() != 1,
+		"pan":           reused.Pan() != 0,
+		"muting":        reused.Muted(),
+		"soloing":       reused.Solo(),
+		"end callback":  reused.onEnd != nil,
+		"loop region":   start != 0 || end != 0,
+		"playback rate": reused.PlaybackRate() != 1,
+		"pitch":         reused.PreservePitch(),
+		"effects":       len(reused.EffectOrder) != 0,
+		"fade":          reused.IsFading(),
+	} {
+		if changed {
+			t.Errorf("expected the reused one-shot Player's %s to be reset", name)
+		}
+	}
+
+}
@@ -0,0 +1,229 @@
+package resound
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// EffectChain is a group of effects applied one after another, like a pedalboard. Unlike ChainEffects(), which wires effects together
+// once, an EffectChain can be changed as it plays; effects can be added, removed, swapped, and bypassed individually.
+// An EffectChain is an effect itself, so it can be added to a Player or DSPChannel as a unit, played as a stream by setting its source,
+// or even be placed inside another EffectChain. Rather than wiring its effects' sources together, the chain reads from its own source
+// and applies each of its effects to the audio in turn, which is what allows effects to be rearranged or bypassed while playing.
+type EffectChain struct {
+	Source   io.ReadSeeker
+	effects  []IEffect
+	bypassed []bool
+	mutex    sync.Mutex
+}
+
+// NewEffectChain creates a new EffectChain with the given effects, in the order they're applied.
+func NewEffectChain(effects ...IEffect) *EffectChain {
+	chain := &EffectChain{}
+	for _, e := range effects {
+		chain.Add(e)
+	}
+	return chain
+}
+
+// Add adds the given effect to the end of the chain.
+func (chain *EffectChain) Add(effect IEffect) *EffectChain {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	chain.effects = append(chain.effects[:len(chain.effects):len(chain.effects)], effect)
+	chain.bypassed = append(chain.bypassed[:len(chain.bypassed):len(chain.bypassed)], false)
+	return chain
+}
+
+// Insert inserts the given effect into the chain at the given index, which is clamped to the bounds of the chain.
+func (chain *EffectChain) Insert(index int, effect IEffect) *EffectChain {
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	if index < 0 {
+		index = 0
+	} else if index > len(chain.effects) {
+		index = len(chain.effects)
+	}
+
+	effects := make([]IEffect, 0, len(chain.effects)+1)
+	effects = append(effects, chain.effects[:index]...)
+	effects = append(effects, effect)
+	chain.effects = append(effects, chain.effects[index:]...)
+
+	bypassed := make([]bool, 0, len(chain.bypassed)+1)
+	bypassed = append(bypassed, chain.bypassed[:index]...)
+	bypassed = append(bypassed, false)
+	chain.bypassed = append(bypassed, chain.bypassed[index:]...)
+
+	return chain
+
+}
+
+// Remove removes the effect at the given index from the chain. If the index is out of range, this does nothing.
+func (chain *EffectChain) Remove(index int) *EffectChain {
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	if index < 0 || index >= len(chain.effects) {
+		return chain
+	}
+
+	// New slices are made (rather than removing the effect in place) so that audio being processed with the old ones isn't disturbed.
+	chain.effects = append(append([]IEffect{}, chain.effects[:index]...), chain.effects[index+1:]...)
+	chain.bypassed = append(append([]bool{}, chain.bypassed[:index]...), chain.bypassed[index+1:]...)
+
+	return chain
+
+}
+
+// Swap swaps the positions of the effects at the given indices in the chain, changing the order they're applied in.
+// If either index is out of range, this does nothing.
+func (chain *EffectChain) Swap(i, j int) *EffectChain {
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	if i < 0 || j < 0 || i >= len(chain.effects) || j >= len(chain.effects) {
+		return chain
+	}
+
+	effects := append([]IEffect{}, chain.effects...)
+	bypassed := append([]bool{}, chain.bypassed...)
+
+	effects[i], effects[j] = effects[j], effects[i]
+	bypassed[i], bypassed[j] = bypassed[j], bypassed[i]
+
+	chain.effects = effects
+	chain.bypassed = bypassed
+
+	return chain
+
+}
+
+// Bypass sets whether the effect at the given index is bypassed; bypassed effects are skipped when the chain processes audio,
+// as though they weren't in the chain. If the index is out of range, this does nothing.
+func (chain *EffectChain) Bypass(index int, bypass bool) *EffectChain {
+
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+
+	if index < 0 || index >= len(chain.bypassed) {
+		return chain
+	}
+
+	bypassed := append([]bool{}, chain.bypassed...)
+	bypassed[index] = bypass
+	chain.bypassed = bypassed
+
+	return chain
+
+}
+
+// Bypassed returns whether the effect at the given index is bypassed. If the index is out of range, Bypassed returns false.
+func (chain *EffectChain) Bypassed(index int) bool {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	if index < 0 || index >= len(chain.bypassed) {
+		return false
+	}
+	return chain.bypassed[index]
+}
+
+// Effect returns the effect at the given index in the chain. If the index is out of range, Effect returns nil.
+func (chain *EffectChain) Effect(index int) IEffect {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	if index < 0 || index >= len(chain.effects) {
+		return nil
+	}
+	return chain.effects[index]
+}
+
+// Len returns the number of effects in the chain.
+func (chain *EffectChain) Len() int {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	return len(chain.effects)
+}
+
+// stages returns the chain's current effects and whether each is bypassed. The slices are never modified in place,
+// so they can be used safely without holding the lock.
+func (chain *EffectChain) stages() ([]IEffect, []bool) {
+	chain.mutex.Lock()
+	defer chain.mutex.Unlock()
+	return chain.effects, chain.bypassed
+}
+
+// ApplyEffect applies each of the chain's effects that isn't bypassed to the given buffer, in order.
+func (chain *EffectChain) ApplyEffect(p []byte, bytesRead int) {
+	effects, bypassed := chain.stages()
+	for i, effect := range effects {
+		if !bypassed[i] {
+			effect.ApplyEffect(p, bytesRead)
+		}
+	}
+}
+
+func (chain *EffectChain) Read(p []byte) (n int, err error) {
+
+	if n, err = chain.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	chain.ApplyEffect(p, n)
+
+	return
+}
+
+// Seek seeks the chain's source. Seeking to the start of the stream also resets the chain's effects (see Reset()).
+func (chain *EffectChain) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		chain.Reset()
+	}
+	return chain.Source.Seek(offset, whence)
+}
+
+// Reset clears the internal state of each of the chain's effects that holds any (i.e. that implements IResettable).
+func (chain *EffectChain) Reset() {
+	effects, _ := chain.stages()
+	for _, effect := range effects {
+		if resettable, ok := effect.(IResettable); ok {
+			resettable.Reset()
+		}
+	}
+}
+
+// Latency returns the total latency of the chain's effects that aren't bypassed (see ILatency). As an EffectChain reports
+// its latency this way, chains nested inside other chains or DSPChannels are accounted for as well.
+func (chain *EffectChain) Latency() time.Duration {
+	effects, bypassed := chain.stages()
+	return effectLatency(effects, bypassed)
+}
+
+// Clone creates a clone of the EffectChain, cloning each of its effects as well.
+func (chain *EffectChain) Clone() IEffect {
+
+	effects, bypassed := chain.stages()
+
+	clone := &EffectChain{
+		Source:   chain.Source,
+		effects:  make([]IEffect, len(effects)),
+		bypassed: append([]bool{}, bypassed...),
+	}
+
+	for i, effect := range effects {
+		clone.effects[i] = effect.Clone()
+	}
+
+	return clone
+
+}
+
+// SetSource sets the active source for the chain.
+func (chain *EffectChain) SetSource(source io.ReadSeeker) {
+	chain.Source = source
+}
@@ -0,0 +1,85 @@
+package resound
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+// testLinear is an effect that scales audio and then offsets it, so the order a chain applies effects in changes its output.
+type testLinear struct {
+	scale, offset float64
+}
+
+func (e *testLinear) ApplyEffect(data []byte, bytesRead int) {
+	buffer := AudioBuffer(data[:bytesRead])
+	for i := 0; i < buffer.Len(); i++ {
+		l, r := buffer.Get(i)
+		buffer.Set(i, l*e.scale+e.offset, r*e.scale+e.offset)
+	}
+}
+
+func (e *testLinear) Read(p []byte) (int, error)                   { return 0, io.EOF }
+func (e *testLinear) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+func (e *testLinear) Clone() IEffect                               { clone := *e; return &clone }
+func (e *testLinear) SetSource(source io.ReadSeeker)               {}
+
+func TestEffectChain(t *testing.T) {
+
+	half := &testLinear{scale: 0.5}
+	offset := &testLinear{scale: 1, offset: 0.25}
+
+	chain := NewEffectChain(half, offset)
+
+	// process returns the level a constant input of 0.5 comes out of the chain at.
+	process := func() float64 {
+		data := make([]byte, 16*4)
+		for i := 0; i < 16; i++ {
+			AudioBuffer(data).Set(i, 0.5, 0.5)
+		}
+		chain.ApplyEffect(data, len(data))
+		l, _ := AudioBuffer(data).Get(15)
+		return l
+	}
+
+	for _, test := range []struct {
+		name     string
+		change   func()
+		expected float64
+	}{
+		{"in order", func() {}, 0.5},
+		{"swapped", func() { chain.Swap(0, 1) }, 0.375},
+		{"with the first effect bypassed", func() { chain.Bypass(0, true) }, 0.25},
+		{"with the bypass cleared", func() { chain.Bypass(0, false) }, 0.375},
+		{"with an effect removed", func() { chain.Remove(0) }, 0.25},
+		{"with an effect inserted", func() { chain.Insert(0, &testLinear{scale: 1, offset: -0.25}) }, 0.125},
+	} {
+		test.change()
+		if level := process(); math.Abs(level-test.expected) > 0.001 {
+			t.Errorf("%s: expected a level of %f, got %f", test.name, test.expected, level)
+		}
+	}
+
+	if chain.Len() != 2 || chain.Effect(1) != half {
+		t.Errorf("expected the chain to hold 2 effects, ending with the halving effect; got %d", chain.Len())
+	}
+
+	// Played as a stream, the chain applies its effects to its source.
+	source := make([]byte, 16*4)
+	for i := 0; i < 16; i++ {
+		AudioBuffer(source).Set(i, 0.5, 0.5)
+	}
+
+	chain.SetSource(bytes.NewReader(source))
+
+	data := make([]byte, 16*4)
+	if n, err := chain.Read(data); n != len(data) || err != nil {
+		t.Fatalf("expected to read the chain's source, got %d bytes and %v", n, err)
+	}
+
+	if l, _ := AudioBuffer(data).Get(0); math.Abs(l-0.125) > 0.001 {
+		t.Errorf("expected reading the chain to apply its effects, got %f", l)
+	}
+
+}
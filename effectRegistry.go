@@ -0,0 +1,116 @@
+package resound
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// effectRegistry maps the names of registered effect types to their constructors, and the other way around.
+var effectRegistry = struct {
+	sync.Mutex
+	constructors map[string]func() IEffect
+	names        map[reflect.Type]string
+}{
+	constructors: map[string]func() IEffect{},
+	names:        map[reflect.Type]string{},
+}
+
+// RegisterEffect registers an effect type under the given name so that it can be serialized with MarshalEffects() and reconstructed with
+// UnmarshalEffects(). The constructor should return a new instance of the effect with its default settings; its type is used to
+// recognize the effect when marshaling. Only effects that implement IParameterized can be serialized, as their parameters are what's saved.
+// All of the effects in the effects package are registered under their type names (e.g. "Delay", "Reverb") when the package is imported.
+// Registering a name that's already registered replaces the previous registration.
+func RegisterEffect(name string, constructor func() IEffect) {
+	effectRegistry.Lock()
+	defer effectRegistry.Unlock()
+	effectRegistry.constructors[name] = constructor
+	effectRegistry.names[reflect.TypeOf(constructor())] = name
+}
+
+// RegisteredEffects returns the names of the effect types that have been registered with RegisterEffect().
+func RegisteredEffects() []string {
+	effectRegistry.Lock()
+	defer effectRegistry.Unlock()
+	names := make([]string, 0, len(effectRegistry.constructors))
+	for name := range effectRegistry.constructors {
+		names = append(names, name)
+	}
+	return names
+}
+
+// serializedEffect is how an effect is represented in JSON by MarshalEffects().
+type serializedEffect struct {
+	Type       string             `json:"type"`
+	Parameters map[string]float64 `json:"parameters"`
+}
+
+// MarshalEffects serializes the given effects (like a Player's or DSPChannel's EffectOrder) to JSON, storing each effect's
+// registered type name and its parameters (see IParameterized). This allows effect chains to be saved to files as presets,
+// and loaded again with UnmarshalEffects(). Note that an effect's source isn't serialized.
+// An error is returned if an effect's type hasn't been registered (see RegisterEffect()) or if it doesn't implement IParameterized.
+func MarshalEffects(effects []IEffect) ([]byte, error) {
+
+	effectRegistry.Lock()
+	defer effectRegistry.Unlock()
+
+	out := make([]serializedEffect, 0, len(effects))
+
+	for _, effect := range effects {
+
+		name, ok := effectRegistry.names[reflect.TypeOf(effect)]
+		if !ok {
+			return nil, fmt.Errorf("resound: effect type %T hasn't been registered", effect)
+		}
+
+		parameterized, ok := effect.(IParameterized)
+		if !ok {
+			return nil, fmt.Errorf("resound: effect type %T doesn't implement IParameterized", effect)
+		}
+
+		out = append(out, serializedEffect{Type: name, Parameters: parameterized.Parameters()})
+
+	}
+
+	return json.Marshal(out)
+
+}
+
+// UnmarshalEffects reconstructs effects from JSON created by MarshalEffects(), creating each effect with its registered
+// constructor (see RegisterEffect()) and then restoring its parameters. The returned effects have no sources set,
+// so they're ready to be added to a Player or DSPChannel, or wired together with ChainEffects().
+// An error is returned if the JSON is invalid or refers to an effect type that hasn't been registered.
+func UnmarshalEffects(data []byte) ([]IEffect, error) {
+
+	serialized := []serializedEffect{}
+
+	if err := json.Unmarshal(data, &serialized); err != nil {
+		return nil, err
+	}
+
+	effectRegistry.Lock()
+	defer effectRegistry.Unlock()
+
+	out := make([]IEffect, 0, len(serialized))
+
+	for _, s := range serialized {
+
+		constructor, ok := effectRegistry.constructors[s.Type]
+		if !ok {
+			return nil, fmt.Errorf("resound: effect type %q hasn't been registered", s.Type)
+		}
+
+		effect := constructor()
+
+		if parameterized, ok := effect.(IParameterized); ok {
+			parameterized.SetParameters(s.Parameters)
+		}
+
+		out = append(out, effect)
+
+	}
+
+	return out, nil
+
+}
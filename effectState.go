@@ -0,0 +1,59 @@
+package resound
+
+// IParameterized is an optional interface for effects that can report and restore their settings as named parameters.
+// Every effect in the effects package implements IParameterized, with the "active" parameter being 1 if the effect is active and 0 if not.
+type IParameterized interface {
+	Parameters() map[string]float64          // This function should return the effect's settings as a map of named parameters.
+	SetParameters(params map[string]float64) // This function should set the effect's settings from a map of named parameters.
+}
+
+// EffectState is a snapshot of the settings of a group of effects, keyed by each effect's ID.
+// It can be used to save and later restore the audio mix state (e.g. when saving and loading a game).
+// Only effects that implement IParameterized are included in an EffectState.
+type EffectState map[any]map[string]float64
+
+func snapshotEffects(effects map[any]IEffect) EffectState {
+
+	state := EffectState{}
+
+	for id, effect := range effects {
+		if p, ok := effect.(IParameterized); ok {
+			state[id] = p.Parameters()
+		}
+	}
+
+	return state
+
+}
+
+func restoreEffects(effects map[any]IEffect, state EffectState) {
+
+	for id, params := range state {
+		if p, ok := effects[id].(IParameterized); ok {
+			p.SetParameters(params)
+		}
+	}
+
+}
+
+// Snapshot returns the current settings of the Player's effects as an EffectState.
+func (p *Player) Snapshot() EffectState {
+	return snapshotEffects(p.effectMap())
+}
+
+// Restore restores the settings of the Player's effects from the given EffectState.
+// Effects that exist in the EffectState but not on the Player are ignored.
+func (p *Player) Restore(state EffectState) {
+	restoreEffects(p.effectMap(), state)
+}
+
+// Snapshot returns the current settings of the DSPChannel's effects as an EffectState.
+func (d *DSPChannel) Snapshot() EffectState {
+	return snapshotEffects(d.effectMap())
+}
+
+// Restore restores the settings of the DSPChannel's effects from the given EffectState.
+// Effects that exist in the EffectState but not on the DSPChannel are ignored.
+func (d *DSPChannel) Restore(state EffectState) {
+	restoreEffects(d.effectMap(), state)
+}
@@ -0,0 +1,235 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// autoWahUpdateInterval is how many samples the AutoWah processes between updates of its filter's frequency; updating the
+// filter every sample would be needlessly expensive, and the envelope doesn't change quickly enough for it to be audible.
+const autoWahUpdateInterval = 32
+
+// AutoWah is an envelope-controlled filter effect (an "envelope filter"). It sweeps a resonant band-pass filter up as the audio gets
+// louder and back down as it gets quieter, giving the "wah" sound of a wah pedal that responds to how hard each note is played.
+type AutoWah struct {
+	baseEffect
+
+	sensitivity float64
+	minHz       float64
+	maxHz       float64
+	q           float64
+	attack      float64
+	release     float64
+	Source      io.ReadSeeker
+
+	envelope envelopeFollower
+	filter   biquad
+	counter  int
+}
+
+// NewAutoWah creates a new AutoWah effect, sweeping from 300 to 2500 Hz.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewAutoWah() *AutoWah {
+	return &AutoWah{
+		sensitivity: 1,
+		minHz:       300,
+		maxHz:       2500,
+		q:           4,
+		attack:      5,
+		release:     150,
+		baseEffect:  newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (wah *AutoWah) Clone() resound.IEffect {
+	return &AutoWah{
+		sensitivity: wah.sensitivity,
+		minHz:       wah.minHz,
+		maxHz:       wah.maxHz,
+		q:           wah.q,
+		attack:      wah.attack,
+		release:     wah.release,
+		baseEffect:  wah.baseEffect.clone(),
+		Source:      wah.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (wah *AutoWah) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":      boolToFloat(wah.active),
+		"mix":         wah.mix,
+		"sensitivity": wah.sensitivity,
+		"minHz":       wah.minHz,
+		"maxHz":       wah.maxHz,
+		"q":           wah.q,
+		"attack":      wah.attack,
+		"release":     wah.release,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (wah *AutoWah) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { wah.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { wah.SetMix(x) })
+	setParam(params, "sensitivity", func(x float64) { wah.SetSensitivity(x) })
+	setParam(params, "minHz", func(x float64) { wah.SetRange(x, wah.maxHz) })
+	setParam(params, "maxHz", func(x float64) { wah.SetRange(wah.minHz, x) })
+	setParam(params, "q", func(x float64) { wah.SetQ(x) })
+	setParam(params, "attack", func(x float64) { wah.SetAttack(x) })
+	setParam(params, "release", func(x float64) { wah.SetRelease(x) })
+}
+
+func (wah *AutoWah) Read(p []byte) (n int, err error) {
+
+	if n, err = wah.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	wah.ApplyEffect(p, n)
+
+	return
+}
+
+func (wah *AutoWah) ApplyEffect(p []byte, bytesRead int) {
+
+	if !wah.active {
+		return
+	}
+
+	if wah.blends() {
+		wah.storeDry(p, bytesRead)
+		defer wah.blendDry(p, bytesRead)
+	}
+
+	sampleRate := resound.SampleRate()
+
+	wah.envelope.setTimes(wah.attack, wah.release, sampleRate)
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		level := wah.envelope.process(math.Max(math.Abs(l), math.Abs(r)))
+
+		if wah.counter <= 0 {
+			// At a sensitivity of 1, audio at around -12 dB opens the filter fully. The sweep is exponential, so it sounds even to the ear.
+			amount := clamp(level*wah.sensitivity*4, 0, 1)
+			freq := wah.minHz * math.Pow(wah.maxHz/wah.minHz, amount)
+			wah.filter.set(biquadBandpass, freq, wah.q, 0, sampleRate)
+			wah.counter = autoWahUpdateInterval
+		}
+		wah.counter--
+
+		audio.Set(i, wah.filter.process(0, l), wah.filter.process(1, r))
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's envelope and filter history (see Reset()).
+func (wah *AutoWah) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		wah.Reset()
+	}
+	if wah.Source == nil {
+		return 0, nil
+	}
+	return wah.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's envelope and filter history, as though it had just been created. Its settings are left unchanged.
+func (wah *AutoWah) Reset() {
+	wah.envelope.level = 0
+	wah.filter.reset()
+	wah.counter = 0
+}
+
+// SetActive sets the effect to be active.
+func (wah *AutoWah) SetActive(active bool) *AutoWah {
+	wah.active = active
+	return wah
+}
+
+// Active returns if the effect is active.
+func (wah *AutoWah) Active() bool {
+	return wah.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (wah *AutoWah) SetMix(mix float64) *AutoWah {
+	wah.setMix(mix)
+	return wah
+}
+
+// SetSensitivity sets how strongly the volume of the audio opens up the filter. At 1, audio at around -12 dB sweeps the filter
+// all the way to the top of its range; higher values make quieter audio sweep it further. 0 is the minimum value. Defaults to 1.
+func (wah *AutoWah) SetSensitivity(sensitivity float64) *AutoWah {
+	if sensitivity < 0 {
+		sensitivity = 0
+	}
+	wah.sensitivity = sensitivity
+	return wah
+}
+
+// Sensitivity returns how strongly the volume of the audio opens up the filter.
+func (wah *AutoWah) Sensitivity() float64 {
+	return wah.sensitivity
+}
+
+// SetRange sets the range of frequencies (in hertz) the filter sweeps across, from where it rests when the audio is quiet (minHz)
+// to where it opens up to when the audio is loud (maxHz). Both are limited to at least 20 Hz. Defaults to 300 to 2500 Hz.
+func (wah *AutoWah) SetRange(minHz, maxHz float64) *AutoWah {
+	wah.minHz = math.Max(minHz, 20)
+	wah.maxHz = math.Max(maxHz, 20)
+	return wah
+}
+
+// Range returns the range of frequencies (in hertz) the filter sweeps across.
+func (wah *AutoWah) Range() (minHz, maxHz float64) {
+	return wah.minHz, wah.maxHz
+}
+
+// SetQ sets the resonance of the filter; higher values give a narrower, more vocal "wah". 0.1 is the minimum value. Defaults to 4.
+func (wah *AutoWah) SetQ(q float64) *AutoWah {
+	wah.q = math.Max(q, 0.1)
+	return wah
+}
+
+// Q returns the resonance of the filter.
+func (wah *AutoWah) Q() float64 {
+	return wah.q
+}
+
+// SetAttack sets how quickly the filter opens up as the audio gets louder, in milliseconds. 0 is the minimum value. Defaults to 5.
+func (wah *AutoWah) SetAttack(ms float64) *AutoWah {
+	wah.attack = math.Max(ms, 0)
+	return wah
+}
+
+// Attack returns how quickly the filter opens up as the audio gets louder, in milliseconds.
+func (wah *AutoWah) Attack() float64 {
+	return wah.attack
+}
+
+// SetRelease sets how quickly the filter closes again as the audio gets quieter, in milliseconds. 0 is the minimum value. Defaults to 150.
+func (wah *AutoWah) SetRelease(ms float64) *AutoWah {
+	wah.release = math.Max(ms, 0)
+	return wah
+}
+
+// Release returns how quickly the filter closes again as the audio gets quieter, in milliseconds.
+func (wah *AutoWah) Release() float64 {
+	return wah.release
+}
+
+// SetSource sets the active source for the effect.
+func (wah *AutoWah) SetSource(source io.ReadSeeker) {
+	wah.Source = source
+}
@@ -0,0 +1,166 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// BandpassFilter is a filter that only lets through a band of frequencies around its center frequency, cutting out everything
+// above and below it. This is useful for "walkie-talkie" or telephone-style sounds.
+type BandpassFilter struct {
+	baseEffect
+
+	Source io.ReadSeeker
+	center float64
+	q      float64
+
+	filter     biquad
+	sampleRate int
+	dirty      bool
+}
+
+// NewBandpassFilter creates a new BandpassFilter effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewBandpassFilter() *BandpassFilter {
+	return &BandpassFilter{
+		center:     1000,
+		q:          1,
+		baseEffect: newBaseEffect(),
+		dirty:      true,
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (bpf *BandpassFilter) Clone() resound.IEffect {
+	return &BandpassFilter{
+		center:     bpf.center,
+		q:          bpf.q,
+		Source:     bpf.Source,
+		baseEffect: bpf.baseEffect.clone(),
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (bpf *BandpassFilter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(bpf.active),
+		"mix":    bpf.mix,
+		"center": bpf.center,
+		"q":      bpf.q,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (bpf *BandpassFilter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { bpf.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { bpf.SetMix(x) })
+	setParam(params, "center", func(x float64) { bpf.SetCenter(x) })
+	setParam(params, "q", func(x float64) { bpf.SetQ(x) })
+}
+
+func (bpf *BandpassFilter) Read(p []byte) (n int, err error) {
+
+	if n, err = bpf.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	bpf.ApplyEffect(p, n)
+
+	return
+
+}
+
+func (bpf *BandpassFilter) ApplyEffect(p []byte, bytesRead int) {
+
+	if !bpf.active {
+		return
+	}
+
+	if bpf.blends() {
+		bpf.storeDry(p, bytesRead)
+		defer bpf.blendDry(p, bytesRead)
+	}
+
+	if sampleRate := resound.SampleRate(); bpf.dirty || sampleRate != bpf.sampleRate {
+		bpf.sampleRate = sampleRate
+		bpf.filter.set(biquadBandpass, bpf.center, bpf.q, 0, sampleRate)
+		bpf.dirty = false
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		audio.Set(i, bpf.filter.process(0, l), bpf.filter.process(1, r))
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history (see Reset()).
+func (bpf *BandpassFilter) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		bpf.Reset()
+	}
+	if bpf.Source == nil {
+		return 0, nil
+	}
+	return bpf.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's filter history, as though it had just been created. Its settings are left unchanged.
+func (bpf *BandpassFilter) Reset() {
+	bpf.filter.reset()
+}
+
+// SetActive sets the effect to be active.
+func (bpf *BandpassFilter) SetActive(active bool) *BandpassFilter {
+	bpf.active = active
+	return bpf
+}
+
+// Active returns if the effect is active.
+func (bpf *BandpassFilter) Active() bool {
+	return bpf.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (bpf *BandpassFilter) SetMix(mix float64) *BandpassFilter {
+	bpf.setMix(mix)
+	return bpf
+}
+
+// SetCenter sets the center frequency of the BandpassFilter in hertz.
+func (bpf *BandpassFilter) SetCenter(hz float64) *BandpassFilter {
+	bpf.center = clamp(hz, minFilterFrequency, maxFilterFrequency)
+	bpf.dirty = true
+	return bpf
+}
+
+// Center returns the center frequency of the BandpassFilter in hertz.
+func (bpf *BandpassFilter) Center() float64 {
+	return bpf.center
+}
+
+// SetQ sets the Q of the BandpassFilter, which controls how wide the band of frequencies that passes through is; higher values let a narrower band through.
+// The value is clamped from 0.1 to 100.
+func (bpf *BandpassFilter) SetQ(q float64) *BandpassFilter {
+	bpf.q = clamp(q, 0.1, 100)
+	bpf.dirty = true
+	return bpf
+}
+
+// Q returns the Q of the BandpassFilter.
+func (bpf *BandpassFilter) Q() float64 {
+	return bpf.q
+}
+
+// SetSource sets the active source for the effect.
+func (bpf *BandpassFilter) SetSource(source io.ReadSeeker) {
+	bpf.Source = source
+}
@@ -0,0 +1,62 @@
+package effects
+
+import "github.com/solarlune/resound"
+
+// baseEffect holds the state shared by every effect - whether it's active, and how much of the processed (wet) signal
+// is blended with the original (dry) signal.
+type baseEffect struct {
+	active bool
+	mix    float64
+	dry    []byte
+}
+
+func newBaseEffect() baseEffect {
+	return baseEffect{active: true, mix: 1}
+}
+
+// clone returns a copy of the base effect's settings, without sharing its dry buffer.
+func (base *baseEffect) clone() baseEffect {
+	return baseEffect{active: base.active, mix: base.mix}
+}
+
+// Mix returns how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 to 1.
+func (base *baseEffect) Mix() float64 {
+	return base.mix
+}
+
+func (base *baseEffect) setMix(mix float64) {
+	base.mix = clamp(mix, 0, 1)
+}
+
+// blends returns if the effect blends the original (dry) signal back into its processed audio - that is, if it's active and not
+// fully wet. Effects only store and blend their dry signal (with storeDry() and blendDry()) when this is true.
+func (base *baseEffect) blends() bool {
+	return base.active && base.mix < 1
+}
+
+// storeDry copies the unprocessed audio so it can be blended back in with blendDry() once the effect has processed it.
+// If the effect is fully wet, nothing is copied.
+func (base *baseEffect) storeDry(p []byte, bytesRead int) {
+	base.dry = base.dry[:0]
+	if base.mix < 1 {
+		base.dry = append(base.dry, p[:bytesRead]...)
+	}
+}
+
+// blendDry blends the audio stored with storeDry() back into the processed audio according to the effect's mix.
+func (base *baseEffect) blendDry(p []byte, bytesRead int) {
+
+	if len(base.dry) != bytesRead {
+		return
+	}
+
+	wet := resound.AudioBuffer(p)
+	dry := resound.AudioBuffer(base.dry)
+
+	for i := 0; i < bytesRead/4; i++ {
+		wl, wr := wet.Get(i)
+		dl, dr := dry.Get(i)
+		wet.Set(i, mix(dl, wl, base.mix), mix(dr, wr, base.mix))
+	}
+
+}
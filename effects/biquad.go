@@ -0,0 +1,151 @@
+package effects
+
+import "math"
+
+const (
+	minFilterFrequency = 20    // The lowest frequency a filter's strength maps to
+	maxFilterFrequency = 20000 // The highest frequency a filter's strength maps to
+	defaultResonance   = math.Sqrt2 / 2
+)
+
+// strengthToFrequency maps a 0 to 1 strength value to a frequency on a logarithmic scale, ranging from minFilterFrequency to maxFilterFrequency.
+func strengthToFrequency(strength float64) float64 {
+	return minFilterFrequency * math.Pow(maxFilterFrequency/minFilterFrequency, strength)
+}
+
+// frequencyToStrength is the inverse of strengthToFrequency.
+func frequencyToStrength(freq float64) float64 {
+	return clamp(math.Log(freq/minFilterFrequency)/math.Log(maxFilterFrequency/minFilterFrequency), 0, 1)
+}
+
+// biquadType indicates which kind of filter a biquad is.
+type biquadType int
+
+const (
+	biquadLowpass biquadType = iota
+	biquadHighpass
+	biquadBandpass
+	biquadNotch
+	biquadPeaking
+	biquadLowShelf
+	biquadHighShelf
+)
+
+// biquad is a second-order (two-pole, two-zero) filter section that processes stereo audio. Its coefficients
+// are calculated using the formulas from Robert Bristow-Johnson's Audio EQ Cookbook.
+// Changing the filter's settings doesn't reset its history, so filters can be swept in real time without clicking.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+
+	x1, x2 [2]float64
+	y1, y2 [2]float64
+}
+
+// set calculates the filter's coefficients for the given filter type, frequency (in hertz), Q, and gain (in decibels, used only by
+// peaking and shelving filters) at the given sample rate.
+func (b *biquad) set(filterType biquadType, freq, q, gainDB float64, sampleRate int) {
+
+	// Keep the frequency within a range the filter can actually represent.
+	freq = clamp(freq, 1, float64(sampleRate)*0.49)
+	if q <= 0 {
+		q = 0.0001
+	}
+
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+	a := math.Pow(10, gainDB/40)
+
+	var b0, b1, b2, a0, a1, a2 float64
+
+	switch filterType {
+
+	case biquadLowpass:
+		b0 = (1 - cos) / 2
+		b1 = 1 - cos
+		b2 = (1 - cos) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+
+	case biquadHighpass:
+		b0 = (1 + cos) / 2
+		b1 = -(1 + cos)
+		b2 = (1 + cos) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+
+	case biquadBandpass:
+		b0 = alpha
+		b1 = 0
+		b2 = -alpha
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+
+	case biquadNotch:
+		b0 = 1
+		b1 = -2 * cos
+		b2 = 1
+		a0 = 1 + alpha
+		a1 = -2 * cos
+		a2 = 1 - alpha
+
+	case biquadPeaking:
+		b0 = 1 + alpha*a
+		b1 = -2 * cos
+		b2 = 1 - alpha*a
+		a0 = 1 + alpha/a
+		a1 = -2 * cos
+		a2 = 1 - alpha/a
+
+	case biquadLowShelf:
+		sqA := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) - (a-1)*cos + sqA)
+		b1 = 2 * a * ((a - 1) - (a+1)*cos)
+		b2 = a * ((a + 1) - (a-1)*cos - sqA)
+		a0 = (a + 1) + (a-1)*cos + sqA
+		a1 = -2 * ((a - 1) + (a+1)*cos)
+		a2 = (a + 1) + (a-1)*cos - sqA
+
+	case biquadHighShelf:
+		sqA := 2 * math.Sqrt(a) * alpha
+		b0 = a * ((a + 1) + (a-1)*cos + sqA)
+		b1 = -2 * a * ((a - 1) + (a+1)*cos)
+		b2 = a * ((a + 1) + (a-1)*cos - sqA)
+		a0 = (a + 1) - (a-1)*cos + sqA
+		a1 = 2 * ((a - 1) - (a+1)*cos)
+		a2 = (a + 1) - (a-1)*cos - sqA
+
+	}
+
+	b.b0 = b0 / a0
+	b.b1 = b1 / a0
+	b.b2 = b2 / a0
+	b.a1 = a1 / a0
+	b.a2 = a2 / a0
+
+}
+
+// process filters the given sample for the given channel (0 for left, 1 for right), returning the filtered sample.
+func (b *biquad) process(channel int, x float64) float64 {
+
+	y := b.b0*x + b.b1*b.x1[channel] + b.b2*b.x2[channel] - b.a1*b.y1[channel] - b.a2*b.y2[channel]
+
+	b.x2[channel] = b.x1[channel]
+	b.x1[channel] = x
+	b.y2[channel] = b.y1[channel]
+	b.y1[channel] = y
+
+	return y
+
+}
+
+// reset clears the filter's history.
+func (b *biquad) reset() {
+	b.x1 = [2]float64{}
+	b.x2 = [2]float64{}
+	b.y1 = [2]float64{}
+	b.y2 = [2]float64{}
+}
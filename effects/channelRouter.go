@@ -0,0 +1,144 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// ChannelMode indicates how a ChannelRouter effect routes the left and right channels of its audio.
+type ChannelMode int
+
+const (
+	ChannelSwap        ChannelMode = iota // The left and right channels are swapped.
+	ChannelLeftToBoth                     // The left channel is played on both channels, discarding the right channel.
+	ChannelRightToBoth                    // The right channel is played on both channels, discarding the left channel.
+	ChannelMono                           // The channels are averaged together, so both channels play the same mono mix.
+)
+
+// ChannelRouter is an effect that swaps or copies the channels of stereo audio. This is handy for fixing audio that was
+// authored with its channels the wrong way around or with a silent channel, or for playing specific sounds in mono.
+type ChannelRouter struct {
+	baseEffect
+
+	mode   ChannelMode
+	Source io.ReadSeeker
+}
+
+// NewChannelRouter creates a new ChannelRouter effect, set to swap the left and right channels.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewChannelRouter() *ChannelRouter {
+	return &ChannelRouter{baseEffect: newBaseEffect()}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (router *ChannelRouter) Clone() resound.IEffect {
+	return &ChannelRouter{
+		mode:       router.mode,
+		baseEffect: router.baseEffect.clone(),
+		Source:     router.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (router *ChannelRouter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(router.active),
+		"mix":    router.mix,
+		"mode":   float64(router.mode),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (router *ChannelRouter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { router.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { router.SetMix(x) })
+	setParam(params, "mode", func(x float64) { router.SetMode(ChannelMode(x)) })
+}
+
+func (router *ChannelRouter) Read(p []byte) (n int, err error) {
+
+	if n, err = router.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	router.ApplyEffect(p, n)
+
+	return
+}
+
+func (router *ChannelRouter) ApplyEffect(p []byte, bytesRead int) {
+
+	if !router.active {
+		return
+	}
+
+	if router.blends() {
+		router.storeDry(p, bytesRead)
+		defer router.blendDry(p, bytesRead)
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		switch router.mode {
+		case ChannelSwap:
+			l, r = r, l
+		case ChannelLeftToBoth:
+			r = l
+		case ChannelRightToBoth:
+			l = r
+		case ChannelMono:
+			l = (l + r) / 2
+			r = l
+		}
+
+		audio.Set(i, l, r)
+
+	}
+
+}
+
+func (router *ChannelRouter) Seek(offset int64, whence int) (int64, error) {
+	if router.Source == nil {
+		return 0, nil
+	}
+	return router.Source.Seek(offset, whence)
+}
+
+// SetActive sets the effect to be active.
+func (router *ChannelRouter) SetActive(active bool) *ChannelRouter {
+	router.active = active
+	return router
+}
+
+// Active returns if the effect is active.
+func (router *ChannelRouter) Active() bool {
+	return router.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (router *ChannelRouter) SetMix(mix float64) *ChannelRouter {
+	router.setMix(mix)
+	return router
+}
+
+// SetMode sets how the ChannelRouter routes the channels of its audio. Defaults to ChannelSwap.
+func (router *ChannelRouter) SetMode(mode ChannelMode) *ChannelRouter {
+	router.mode = mode
+	return router
+}
+
+// Mode returns how the ChannelRouter routes the channels of its audio.
+func (router *ChannelRouter) Mode() ChannelMode {
+	return router.mode
+}
+
+// SetSource sets the active source for the effect.
+func (router *ChannelRouter) SetSource(source io.ReadSeeker) {
+	router.Source = source
+}
@@ -0,0 +1,236 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// Compressor is an effect that reduces the dynamic range of audio by turning down the volume whenever it gets louder than a threshold.
+// This is useful for evening out the volume of music and sound effects so they mix together better.
+type Compressor struct {
+	baseEffect
+
+	threshold float64
+	ratio     float64
+	attack    float64
+	release   float64
+	makeup    float64
+	Source    io.ReadSeeker
+
+	envelope      envelopeFollower
+	gainReduction float64
+}
+
+// NewCompressor creates a new Compressor effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewCompressor() *Compressor {
+	return &Compressor{
+		threshold:  -20,
+		ratio:      4,
+		attack:     10,
+		release:    100,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (c *Compressor) Clone() resound.IEffect {
+	return &Compressor{
+		threshold:  c.threshold,
+		ratio:      c.ratio,
+		attack:     c.attack,
+		release:    c.release,
+		makeup:     c.makeup,
+		baseEffect: c.baseEffect.clone(),
+		Source:     c.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (c *Compressor) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":    boolToFloat(c.active),
+		"mix":       c.mix,
+		"threshold": c.threshold,
+		"ratio":     c.ratio,
+		"attack":    c.attack,
+		"release":   c.release,
+		"makeup":    c.makeup,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (c *Compressor) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { c.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { c.SetMix(x) })
+	setParam(params, "threshold", func(x float64) { c.SetThreshold(x) })
+	setParam(params, "ratio", func(x float64) { c.SetRatio(x) })
+	setParam(params, "attack", func(x float64) { c.SetAttack(x) })
+	setParam(params, "release", func(x float64) { c.SetRelease(x) })
+	setParam(params, "makeup", func(x float64) { c.SetMakeupGain(x) })
+}
+
+func (c *Compressor) Read(p []byte) (n int, err error) {
+
+	if n, err = c.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	c.ApplyEffect(p, n)
+
+	return
+}
+
+func (c *Compressor) ApplyEffect(p []byte, bytesRead int) {
+
+	if !c.active {
+		return
+	}
+
+	if c.blends() {
+		c.storeDry(p, bytesRead)
+		defer c.blendDry(p, bytesRead)
+	}
+
+	c.envelope.setTimes(c.attack, c.release, resound.SampleRate())
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		level := c.envelope.process(math.Max(math.Abs(l), math.Abs(r)))
+
+		// Anything over the threshold is reduced according to the ratio; a ratio of 4 means that
+		// for every 4 dB the signal goes over the threshold, only 1 dB makes it through.
+		reduction := 0.0
+		if over := resound.LinearToDB(level) - c.threshold; over > 0 {
+			reduction = over * (1 - 1/c.ratio)
+		}
+
+		c.gainReduction = reduction
+
+		gain := resound.DBToLinear(c.makeup - reduction)
+
+		audio.Set(i, l*gain, r*gain)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's envelope (see Reset()).
+func (c *Compressor) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		c.Reset()
+	}
+	if c.Source == nil {
+		return 0, nil
+	}
+	return c.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's envelope, as though it had just been created. Its settings are left unchanged.
+func (c *Compressor) Reset() {
+	c.envelope.level = 0
+	c.gainReduction = 0
+}
+
+// SetActive sets the effect to be active.
+func (c *Compressor) SetActive(active bool) *Compressor {
+	c.active = active
+	return c
+}
+
+// Active returns if the effect is active.
+func (c *Compressor) Active() bool {
+	return c.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (c *Compressor) SetMix(mix float64) *Compressor {
+	c.setMix(mix)
+	return c
+}
+
+// SetThreshold sets the level in decibels (relative to full scale, so 0 is the loudest possible level) above which the audio is compressed.
+func (c *Compressor) SetThreshold(db float64) *Compressor {
+	if db > 0 {
+		db = 0
+	}
+	c.threshold = db
+	return c
+}
+
+// Threshold returns the level in decibels above which the audio is compressed.
+func (c *Compressor) Threshold() float64 {
+	return c.threshold
+}
+
+// SetRatio sets the compression ratio; for example, a ratio of 4 means that for every 4 dB the audio is over the threshold, only 1 dB
+// makes it through. 1 is the minimum value, which means no compression.
+func (c *Compressor) SetRatio(ratio float64) *Compressor {
+	if ratio < 1 {
+		ratio = 1
+	}
+	c.ratio = ratio
+	return c
+}
+
+// Ratio returns the compression ratio.
+func (c *Compressor) Ratio() float64 {
+	return c.ratio
+}
+
+// SetAttack sets how quickly the Compressor reacts to the audio getting louder, in milliseconds. 0 is the minimum value.
+func (c *Compressor) SetAttack(ms float64) *Compressor {
+	if ms < 0 {
+		ms = 0
+	}
+	c.attack = ms
+	return c
+}
+
+// Attack returns how quickly the Compressor reacts to the audio getting louder, in milliseconds.
+func (c *Compressor) Attack() float64 {
+	return c.attack
+}
+
+// SetRelease sets how quickly the Compressor recovers once the audio gets quieter, in milliseconds. 0 is the minimum value.
+func (c *Compressor) SetRelease(ms float64) *Compressor {
+	if ms < 0 {
+		ms = 0
+	}
+	c.release = ms
+	return c
+}
+
+// Release returns how quickly the Compressor recovers once the audio gets quieter, in milliseconds.
+func (c *Compressor) Release() float64 {
+	return c.release
+}
+
+// SetMakeupGain sets the gain in decibels applied after compression, to make up for the volume lost by compressing the audio.
+func (c *Compressor) SetMakeupGain(db float64) *Compressor {
+	c.makeup = db
+	return c
+}
+
+// MakeupGain returns the gain in decibels applied after compression.
+func (c *Compressor) MakeupGain() float64 {
+	return c.makeup
+}
+
+// GainReductionDB returns how much the Compressor is currently turning the audio down, in decibels.
+// This is useful for displaying a gain reduction meter.
+func (c *Compressor) GainReductionDB() float64 {
+	return c.gainReduction
+}
+
+// SetSource sets the active source for the effect.
+func (c *Compressor) SetSource(source io.ReadSeeker) {
+	c.Source = source
+}
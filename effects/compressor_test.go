@@ -0,0 +1,38 @@
+package effects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestCompressorGainReduction(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	// With no attack time, the Compressor reacts to each level immediately, so its output can be calculated exactly:
+	// 0.9 is about -0.92 dB, which is 19.08 dB over the threshold, and so is turned down by 19.08 * (1 - 1/4) = 14.31 dB.
+	for _, test := range []struct {
+		level, expected float64
+	}{
+		{0.9, 0.9 * math.Pow(10, -(20*math.Log10(0.9)+20)*0.75/20)},
+		{0.05, 0.05},
+	} {
+
+		compressor := NewCompressor().SetThreshold(-20).SetRatio(4).SetAttack(0)
+
+		data := make([]byte, 1024*4)
+		for i := 0; i < 1024; i++ {
+			resound.AudioBuffer(data).Set(i, test.level, test.level)
+		}
+
+		compressor.ApplyEffect(data, len(data))
+
+		if l, r := resound.AudioBuffer(data).Get(1023); math.Abs(l-test.expected) > 0.001 || math.Abs(r-test.expected) > 0.001 {
+			t.Errorf("expected a level of %f to be compressed to %f, got %f, %f", test.level, test.expected, l, r)
+		}
+
+	}
+
+}
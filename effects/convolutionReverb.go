@@ -0,0 +1,326 @@
+package effects
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/solarlune/resound"
+)
+
+const (
+	// convolutionBlockSize is the number of frames the ConvolutionReverb processes at a time, which is also its latency.
+	convolutionBlockSize = 512
+	// MaxImpulseLength is the longest impulse response (in seconds) that a ConvolutionReverb can load.
+	MaxImpulseLength = 10.0
+)
+
+// ErrImpulseTooLong is returned by ConvolutionReverb.LoadIR() when the impulse response is longer than MaxImpulseLength seconds.
+var ErrImpulseTooLong = errors.New("effects: the impulse response is too long")
+
+// ConvolutionReverb is a reverb effect that convolves the audio with an impulse response (IR) - a recording of how a real (or virtual)
+// space responds to a short click. This makes the audio sound like it was played in that space, which can sound more realistic than
+// the algorithmic Reverb effect.
+//
+// The convolution is done in blocks of 512 frames using FFTs (uniformly-partitioned overlap-add convolution), so the effect adds 512 frames
+// (about 12ms at 44100 Hz) of latency to the reverberated signal, which acts like a short pre-delay. The CPU cost grows linearly with
+// the length of the IR; each block of 512 frames takes two FFTs per channel, plus a complex multiply-add per channel for every 512 frames
+// of the IR. A two-second IR at 44100 Hz (about 170 partitions) is comfortably cheap enough for a few instances to run in real time,
+// but long IRs on many Players at once can add up.
+type ConvolutionReverb struct {
+	baseEffect
+
+	Source io.ReadSeeker
+
+	irSpectra [][2][2][]float64 // The spectrum of each partition of the IR, for each channel, as real and imaginary parts
+	irLength  int               // The length of the IR, in frames
+
+	history  [][2][2][]float64 // The spectra of the most recent blocks of input, for each channel, used as a ring buffer
+	newest   int               // The index of the newest spectrum in the history
+	input    [2][]float64      // The block of input currently being collected
+	output   [2][]float64      // The block of output currently being played
+	overlap  [2][]float64      // The tail of the last block's convolution, which is added to the next block
+	position int               // The position in the current blocks
+
+	re, im, accRe, accIm []float64 // Scratch buffers for the FFTs
+}
+
+// NewConvolutionReverb creates a new ConvolutionReverb effect. It passes audio through unchanged until an impulse response is loaded with LoadIR().
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewConvolutionReverb() *ConvolutionReverb {
+	cr := &ConvolutionReverb{
+		baseEffect: baseEffect{active: true, mix: 0.3},
+	}
+	cr.allocate()
+	return cr
+}
+
+// Clone clones the effect, returning an resound.IEffect. The clone shares the original's impulse response (which is never modified),
+// but has its own processing state.
+func (cr *ConvolutionReverb) Clone() resound.IEffect {
+	clone := &ConvolutionReverb{
+		baseEffect: cr.baseEffect.clone(),
+		Source:     cr.Source,
+		irSpectra:  cr.irSpectra,
+		irLength:   cr.irLength,
+	}
+	clone.allocate()
+	return clone
+}
+
+// allocate creates the buffers the effect uses to process audio with its current impulse response.
+func (cr *ConvolutionReverb) allocate() {
+
+	size := convolutionBlockSize * 2
+
+	cr.history = make([][2][2][]float64, len(cr.irSpectra))
+	for i := range cr.history {
+		cr.history[i] = newSpectrumPair(size)
+	}
+
+	for ch := 0; ch < 2; ch++ {
+		cr.input[ch] = make([]float64, convolutionBlockSize)
+		cr.output[ch] = make([]float64, convolutionBlockSize)
+		cr.overlap[ch] = make([]float64, convolutionBlockSize)
+	}
+
+	cr.re = make([]float64, size)
+	cr.im = make([]float64, size)
+	cr.accRe = make([]float64, size)
+	cr.accIm = make([]float64, size)
+
+	cr.newest = 0
+	cr.position = 0
+
+}
+
+func newSpectrumPair(size int) [2][2][]float64 {
+	return [2][2][]float64{
+		{make([]float64, size), make([]float64, size)},
+		{make([]float64, size), make([]float64, size)},
+	}
+}
+
+// LoadIR loads the impulse response to convolve audio with from the given stream, which should be 16-bit stereo audio at the context's
+// sample rate (like a WAV file decoded with wav.DecodeWithSampleRate()). The stream is read from its current position until it ends.
+// The impulse response is normalized, so IRs recorded at different volumes give a similar volume of reverb.
+// If the impulse response is longer than MaxImpulseLength seconds, LoadIR returns ErrImpulseTooLong and leaves the current impulse response loaded.
+// An impulse response should be loaded before the effect is played, rather than while it's playing.
+func (cr *ConvolutionReverb) LoadIR(stream io.ReadSeeker) error {
+
+	maxBytes := int64(MaxImpulseLength*float64(resound.SampleRate())) * 4
+
+	data, err := io.ReadAll(io.LimitReader(stream, maxBytes+4))
+	if err != nil {
+		return err
+	}
+
+	if int64(len(data)) > maxBytes {
+		return ErrImpulseTooLong
+	}
+
+	ir := resound.AudioBuffer(data)
+	frames := ir.Len()
+
+	energy := 0.0
+	for i := 0; i < frames; i++ {
+		l, r := ir.Get(i)
+		energy += (l*l + r*r) / 2
+	}
+
+	scale := 0.0
+	if energy > 0 {
+		scale = 1 / math.Sqrt(energy)
+	}
+
+	size := convolutionBlockSize * 2
+	partitions := (frames + convolutionBlockSize - 1) / convolutionBlockSize
+
+	spectra := make([][2][2][]float64, partitions)
+
+	for p := range spectra {
+
+		spectra[p] = newSpectrumPair(size)
+
+		for i := 0; i < convolutionBlockSize; i++ {
+			l, r := ir.Get(p*convolutionBlockSize + i) // Get returns silence past the end of the IR
+			spectra[p][0][0][i] = l * scale
+			spectra[p][1][0][i] = r * scale
+		}
+
+		for ch := 0; ch < 2; ch++ {
+			resound.FFT(spectra[p][ch][0], spectra[p][ch][1])
+		}
+
+	}
+
+	cr.irSpectra = spectra
+	cr.irLength = frames
+	cr.allocate()
+
+	return nil
+
+}
+
+// IRLength returns the length of the loaded impulse response, in seconds.
+func (cr *ConvolutionReverb) IRLength() float64 {
+	return float64(cr.irLength) / float64(resound.SampleRate())
+}
+
+// Latency returns how long the ConvolutionReverb delays the reverberated audio by, which is the length of one block (512 frames).
+// The dry audio isn't delayed, so this latency only needs compensating for when the effect is fully wet.
+func (cr *ConvolutionReverb) Latency() time.Duration {
+	return time.Duration(float64(convolutionBlockSize) / float64(resound.SampleRate()) * float64(time.Second))
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (cr *ConvolutionReverb) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(cr.active),
+		"mix":    cr.mix,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+// Note that the impulse response isn't a parameter, and so has to be loaded separately.
+func (cr *ConvolutionReverb) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { cr.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { cr.SetMix(x) })
+}
+
+func (cr *ConvolutionReverb) Read(p []byte) (n int, err error) {
+
+	if n, err = cr.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	cr.ApplyEffect(p, n)
+
+	return
+}
+
+func (cr *ConvolutionReverb) ApplyEffect(p []byte, bytesRead int) {
+
+	if !cr.active || len(cr.irSpectra) == 0 {
+		return
+	}
+
+	if cr.blends() {
+		cr.storeDry(p, bytesRead)
+		defer cr.blendDry(p, bytesRead)
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		cr.input[0][cr.position] = l
+		cr.input[1][cr.position] = r
+
+		audio.Set(i, cr.output[0][cr.position], cr.output[1][cr.position])
+
+		cr.position++
+
+		if cr.position >= convolutionBlockSize {
+			cr.processBlock()
+			cr.position = 0
+		}
+
+	}
+
+}
+
+// processBlock convolves the most recently collected block of input with the impulse response, producing the next block of output.
+func (cr *ConvolutionReverb) processBlock() {
+
+	partitions := len(cr.irSpectra)
+
+	// The oldest spectrum in the history is replaced by the new block's.
+	cr.newest = (cr.newest + 1) % partitions
+
+	for ch := 0; ch < 2; ch++ {
+
+		spectrum := cr.history[cr.newest][ch]
+
+		// The block is zero-padded to twice its length, so the convolution's tail doesn't wrap around.
+		for i := range spectrum[0] {
+			spectrum[0][i] = 0
+			spectrum[1][i] = 0
+		}
+		copy(spectrum[0], cr.input[ch])
+
+		resound.FFT(spectrum[0], spectrum[1])
+
+		for i := range cr.accRe {
+			cr.accRe[i] = 0
+			cr.accIm[i] = 0
+		}
+
+		// Each partition of the IR is multiplied with the input block that arrived that many blocks ago.
+		for p := 0; p < partitions; p++ {
+
+			x := cr.history[(cr.newest-p+partitions)%partitions][ch]
+			h := cr.irSpectra[p][ch]
+
+			for i := range cr.accRe {
+				cr.accRe[i] += x[0][i]*h[0][i] - x[1][i]*h[1][i]
+				cr.accIm[i] += x[0][i]*h[1][i] + x[1][i]*h[0][i]
+			}
+
+		}
+
+		copy(cr.re, cr.accRe)
+		copy(cr.im, cr.accIm)
+
+		resound.InverseFFT(cr.re, cr.im)
+
+		for i := 0; i < convolutionBlockSize; i++ {
+			cr.output[ch][i] = cr.re[i] + cr.overlap[ch][i]
+			cr.overlap[ch][i] = cr.re[convolutionBlockSize+i]
+		}
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's reverb tail (see Reset()).
+func (cr *ConvolutionReverb) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		cr.Reset()
+	}
+	if cr.Source == nil {
+		return 0, nil
+	}
+	return cr.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's reverb tail, as though it had just been created. Its settings and impulse response are left unchanged.
+func (cr *ConvolutionReverb) Reset() {
+	cr.allocate()
+}
+
+// SetActive sets the effect to be active.
+func (cr *ConvolutionReverb) SetActive(active bool) *ConvolutionReverb {
+	cr.active = active
+	return cr
+}
+
+// Active returns if the effect is active.
+func (cr *ConvolutionReverb) Active() bool {
+	return cr.active
+}
+
+// SetMix sets how much of the reverberated (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 0.3.
+func (cr *ConvolutionReverb) SetMix(mix float64) *ConvolutionReverb {
+	cr.setMix(mix)
+	return cr
+}
+
+// SetSource sets the active source for the effect.
+func (cr *ConvolutionReverb) SetSource(source io.ReadSeeker) {
+	cr.Source = source
+}
@@ -0,0 +1,59 @@
+package effects
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestConvolutionReverbIdentity(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	// An impulse response that's a single full-scale frame convolves audio into itself.
+	ir := make([]byte, 64*4)
+	resound.AudioBuffer(ir).Set(0, 1, 1)
+
+	reverb := NewConvolutionReverb().SetMix(1)
+
+	if err := reverb.LoadIR(bytes.NewReader(ir)); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := convolutionBlockSize * 4
+
+	input := make([]byte, frames*4)
+	for i := 0; i < frames; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*441*float64(i)/44100)
+		resound.AudioBuffer(input).Set(i, v, -v)
+	}
+
+	output := append([]byte{}, input...)
+
+	// Apply the effect in uneven chunks, to make sure blocks are collected across calls.
+	for start := 0; start < len(output); start += 300 * 4 {
+		chunk := 300 * 4
+		if start+chunk > len(output) {
+			chunk = len(output) - start
+		}
+		reverb.ApplyEffect(output[start:], chunk)
+	}
+
+	for i := 0; i < frames; i++ {
+
+		expectedL, expectedR := 0.0, 0.0
+		if i >= convolutionBlockSize {
+			expectedL, expectedR = resound.AudioBuffer(input).Get(i - convolutionBlockSize)
+		}
+
+		l, r := resound.AudioBuffer(output).Get(i)
+
+		if math.Abs(l-expectedL) > 0.001 || math.Abs(r-expectedR) > 0.001 {
+			t.Fatalf("expected frame %d to be the input delayed by %d frames (%f, %f), got (%f, %f)", i, convolutionBlockSize, expectedL, expectedR, l, r)
+		}
+
+	}
+
+}
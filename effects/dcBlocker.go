@@ -0,0 +1,147 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// DCBlocker is an effect that removes any DC offset (a constant shift away from zero) from audio, using a very gentle one-pole
+// high-pass filter (y[n] = x[n] - x[n-1] + R * y[n-1]). A DC offset wastes headroom and can cause thumps when a sound starts, stops,
+// or loops. Unlike HighpassFilter, a DCBlocker is meant to be left on without being heard, usually as the first effect in a chain.
+type DCBlocker struct {
+	baseEffect
+
+	coefficient float64
+	Source      io.ReadSeeker
+
+	prevInput  [2]float64
+	prevOutput [2]float64
+}
+
+// NewDCBlocker creates a new DCBlocker effect, with a coefficient of 0.995.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewDCBlocker() *DCBlocker {
+	return &DCBlocker{
+		coefficient: 0.995,
+		baseEffect:  newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (dc *DCBlocker) Clone() resound.IEffect {
+	return &DCBlocker{
+		coefficient: dc.coefficient,
+		baseEffect:  dc.baseEffect.clone(),
+		Source:      dc.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (dc *DCBlocker) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":      boolToFloat(dc.active),
+		"mix":         dc.mix,
+		"coefficient": dc.coefficient,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (dc *DCBlocker) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { dc.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { dc.SetMix(x) })
+	setParam(params, "coefficient", func(x float64) { dc.SetCoefficient(x) })
+}
+
+func (dc *DCBlocker) Read(p []byte) (n int, err error) {
+
+	if n, err = dc.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	dc.ApplyEffect(p, n)
+
+	return
+}
+
+func (dc *DCBlocker) ApplyEffect(p []byte, bytesRead int) {
+
+	if !dc.active {
+		return
+	}
+
+	if dc.blends() {
+		dc.storeDry(p, bytesRead)
+		defer dc.blendDry(p, bytesRead)
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		outL := l - dc.prevInput[0] + dc.coefficient*dc.prevOutput[0]
+		outR := r - dc.prevInput[1] + dc.coefficient*dc.prevOutput[1]
+
+		dc.prevInput[0], dc.prevInput[1] = l, r
+		dc.prevOutput[0], dc.prevOutput[1] = outL, outR
+
+		audio.Set(i, outL, outR)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history (see Reset()).
+func (dc *DCBlocker) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		dc.Reset()
+	}
+	if dc.Source == nil {
+		return 0, nil
+	}
+	return dc.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's filter history, as though it had just been created. Its settings are left unchanged.
+func (dc *DCBlocker) Reset() {
+	dc.prevInput = [2]float64{}
+	dc.prevOutput = [2]float64{}
+}
+
+// SetActive sets the effect to be active.
+func (dc *DCBlocker) SetActive(active bool) *DCBlocker {
+	dc.active = active
+	return dc
+}
+
+// Active returns if the effect is active.
+func (dc *DCBlocker) Active() bool {
+	return dc.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (dc *DCBlocker) SetMix(mix float64) *DCBlocker {
+	dc.setMix(mix)
+	return dc
+}
+
+// SetCoefficient sets the filter's coefficient (R), ranging from 0.9 to 0.9999. The closer it is to 1, the lower the filter's cutoff, so
+// less of the audio's low end is affected, but the longer it takes an offset to be removed. The default of 0.995 puts the cutoff at about
+// 35 Hz at 44100 Hz, which removes an offset in a few hundredths of a second without noticeably thinning the audio.
+func (dc *DCBlocker) SetCoefficient(r float64) *DCBlocker {
+	dc.coefficient = clamp(r, 0.9, 0.9999)
+	return dc
+}
+
+// Coefficient returns the filter's coefficient (R).
+func (dc *DCBlocker) Coefficient() float64 {
+	return dc.coefficient
+}
+
+// SetSource sets the active source for the effect.
+func (dc *DCBlocker) SetSource(source io.ReadSeeker) {
+	dc.Source = source
+}
@@ -0,0 +1,205 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// Downsampler is an effect that simulates playing audio back at a lower sample rate, like an old console or sampler would, by holding
+// each sample until the next one at the lower rate is due. Frequencies above half of the target rate can't be represented at that rate,
+// and so "fold back" as harsh, inharmonic aliasing; with anti-aliasing enabled, they're filtered out beforehand with a low-pass filter,
+// giving a cleaner, duller sound. Unlike Bitcrush, the target rate is given directly in hertz, so it's independent of the context's sample rate.
+type Downsampler struct {
+	baseEffect
+
+	targetRate float64
+	antiAlias  bool
+	Source     io.ReadSeeker
+
+	filters    [2]biquad // Two filters are cascaded for a steeper cutoff
+	sampleRate int
+	dirty      bool
+
+	phase float64
+	heldL float64
+	heldR float64
+}
+
+// NewDownsampler creates a new Downsampler effect, with a target rate of 11025 Hz and anti-aliasing enabled.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewDownsampler() *Downsampler {
+	return &Downsampler{
+		targetRate: 11025,
+		antiAlias:  true,
+		baseEffect: newBaseEffect(),
+		dirty:      true,
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (ds *Downsampler) Clone() resound.IEffect {
+	return &Downsampler{
+		targetRate: ds.targetRate,
+		antiAlias:  ds.antiAlias,
+		baseEffect: ds.baseEffect.clone(),
+		Source:     ds.Source,
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (ds *Downsampler) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":     boolToFloat(ds.active),
+		"mix":        ds.mix,
+		"targetRate": ds.targetRate,
+		"antiAlias":  boolToFloat(ds.antiAlias),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (ds *Downsampler) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { ds.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { ds.SetMix(x) })
+	setParam(params, "targetRate", func(x float64) { ds.SetTargetRate(x) })
+	setParam(params, "antiAlias", func(x float64) { ds.SetAntiAlias(x != 0) })
+}
+
+func (ds *Downsampler) Read(p []byte) (n int, err error) {
+
+	if n, err = ds.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	ds.ApplyEffect(p, n)
+
+	return
+}
+
+func (ds *Downsampler) ApplyEffect(p []byte, bytesRead int) {
+
+	if !ds.active {
+		return
+	}
+
+	sampleRate := resound.SampleRate()
+
+	if ds.targetRate >= float64(sampleRate) {
+		return
+	}
+
+	if ds.blends() {
+		ds.storeDry(p, bytesRead)
+		defer ds.blendDry(p, bytesRead)
+	}
+
+	if ds.dirty || sampleRate != ds.sampleRate {
+		ds.sampleRate = sampleRate
+		// The cutoff is a little below the Nyquist frequency of the target rate, as the filter doesn't cut off instantly.
+		for i := range ds.filters {
+			ds.filters[i].set(biquadLowpass, ds.targetRate*0.45, math.Sqrt2/2, 0, sampleRate)
+		}
+		ds.dirty = false
+	}
+
+	step := ds.targetRate / float64(sampleRate)
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		if ds.antiAlias {
+			for f := range ds.filters {
+				l = ds.filters[f].process(0, l)
+				r = ds.filters[f].process(1, r)
+			}
+		}
+
+		// A new sample is taken each time the phase wraps around, at the target rate; it carries over between buffers.
+		if ds.phase <= 0 {
+			ds.heldL, ds.heldR = l, r
+			ds.phase++
+		}
+		ds.phase -= step
+
+		audio.Set(i, ds.heldL, ds.heldR)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history and held sample (see Reset()).
+func (ds *Downsampler) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		ds.Reset()
+	}
+	if ds.Source == nil {
+		return 0, nil
+	}
+	return ds.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's filter history and held sample, as though it had just been created. Its settings are left unchanged.
+func (ds *Downsampler) Reset() {
+	for i := range ds.filters {
+		ds.filters[i].reset()
+	}
+	ds.phase = 0
+	ds.heldL = 0
+	ds.heldR = 0
+}
+
+// SetActive sets the effect to be active.
+func (ds *Downsampler) SetActive(active bool) *Downsampler {
+	ds.active = active
+	return ds
+}
+
+// Active returns if the effect is active.
+func (ds *Downsampler) Active() bool {
+	return ds.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (ds *Downsampler) SetMix(mix float64) *Downsampler {
+	ds.setMix(mix)
+	return ds
+}
+
+// SetTargetRate sets the sample rate to simulate, in hertz (e.g. 8000 for a telephone-like sound, or 4000 for a crunchy retro one).
+// If the target rate is at or above the context's sample rate, the audio is left as is. 100 is the minimum value.
+func (ds *Downsampler) SetTargetRate(hz float64) *Downsampler {
+	if hz < 100 {
+		hz = 100
+	}
+	ds.targetRate = hz
+	ds.dirty = true
+	return ds
+}
+
+// TargetRate returns the sample rate to simulate, in hertz.
+func (ds *Downsampler) TargetRate() float64 {
+	return ds.targetRate
+}
+
+// SetAntiAlias sets whether the audio is low-pass filtered before it's downsampled, to remove the frequencies that would otherwise
+// alias into harsh, inharmonic tones. Defaults to true.
+func (ds *Downsampler) SetAntiAlias(antiAlias bool) *Downsampler {
+	ds.antiAlias = antiAlias
+	return ds
+}
+
+// AntiAlias returns whether the audio is low-pass filtered before it's downsampled.
+func (ds *Downsampler) AntiAlias() bool {
+	return ds.antiAlias
+}
+
+// SetSource sets the active source for the effect.
+func (ds *Downsampler) SetSource(source io.ReadSeeker) {
+	ds.Source = source
+}
@@ -3,29 +3,154 @@ package effects
 import (
 	"io"
 	"math"
+	"time"
 
-	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/solarlune/resound"
 	"github.com/tanema/gween/ease"
 )
 
+// Every effect can be chained and rewired generically through the resound.IEffect interface (including SetSource()),
+// while stream effects (which change the length of the audio) satisfy resound.IStreamEffect instead.
+// Effects that hold internal state (delay lines, filter history, LFO phases, envelopes, and so on) satisfy resound.IResettable.
+// Effects that delay the audio passing through them satisfy resound.ILatency; all other effects have no latency.
+var (
+	_ resound.IEffect = (*Volume)(nil)
+	_ resound.IEffect = (*Pan)(nil)
+	_ resound.IEffect = (*Delay)(nil)
+	_ resound.IEffect = (*Distort)(nil)
+	_ resound.IEffect = (*LowpassFilter)(nil)
+	_ resound.IEffect = (*HighpassFilter)(nil)
+	_ resound.IEffect = (*Bitcrush)(nil)
+	_ resound.IEffect = (*PitchShift)(nil)
+	_ resound.IEffect = (*BandpassFilter)(nil)
+	_ resound.IEffect = (*NotchFilter)(nil)
+	_ resound.IEffect = (*Compressor)(nil)
+	_ resound.IEffect = (*EQ)(nil)
+	_ resound.IEffect = (*Flanger)(nil)
+	_ resound.IEffect = (*Limiter)(nil)
+	_ resound.IEffect = (*Pan3D)(nil)
+	_ resound.IEffect = (*Passthrough)(nil)
+	_ resound.IEffect = (*Reverb)(nil)
+	_ resound.IEffect = (*StereoWidth)(nil)
+	_ resound.IEffect = (*Tremolo)(nil)
+	_ resound.IEffect = (*Vibrato)(nil)
+	_ resound.IEffect = (*RingMod)(nil)
+	_ resound.IEffect = (*Downsampler)(nil)
+	_ resound.IEffect = (*AutoWah)(nil)
+	_ resound.IEffect = (*ConvolutionReverb)(nil)
+	_ resound.IEffect = (*Haas)(nil)
+	_ resound.IEffect = (*ChannelRouter)(nil)
+	_ resound.IEffect = (*DCBlocker)(nil)
+
+	_ resound.IStreamEffect = (*TimeStretch)(nil)
+
+	_ resound.ILatency = (*PitchShift)(nil)
+	_ resound.ILatency = (*Limiter)(nil)
+	_ resound.ILatency = (*ConvolutionReverb)(nil)
+	_ resound.ILatency = (*Vibrato)(nil)
+	_ resound.ILatency = (*TimeStretch)(nil)
+
+	_ resound.IResettable = (*Delay)(nil)
+	_ resound.IResettable = (*LowpassFilter)(nil)
+	_ resound.IResettable = (*HighpassFilter)(nil)
+	_ resound.IResettable = (*BandpassFilter)(nil)
+	_ resound.IResettable = (*NotchFilter)(nil)
+	_ resound.IResettable = (*EQ)(nil)
+	_ resound.IResettable = (*PitchShift)(nil)
+	_ resound.IResettable = (*Reverb)(nil)
+	_ resound.IResettable = (*Flanger)(nil)
+	_ resound.IResettable = (*Tremolo)(nil)
+	_ resound.IResettable = (*Vibrato)(nil)
+	_ resound.IResettable = (*Compressor)(nil)
+	_ resound.IResettable = (*Limiter)(nil)
+	_ resound.IResettable = (*Pan3D)(nil)
+	_ resound.IResettable = (*TimeStretch)(nil)
+	_ resound.IResettable = (*RingMod)(nil)
+	_ resound.IResettable = (*Bitcrush)(nil)
+	_ resound.IResettable = (*Downsampler)(nil)
+	_ resound.IResettable = (*AutoWah)(nil)
+	_ resound.IResettable = (*ConvolutionReverb)(nil)
+	_ resound.IResettable = (*Haas)(nil)
+	_ resound.IResettable = (*DCBlocker)(nil)
+)
+
+// The effects are registered under their type names so they can be serialized with resound.MarshalEffects().
+func init() {
+	resound.RegisterEffect("Volume", func() resound.IEffect { return NewVolume() })
+	resound.RegisterEffect("Pan", func() resound.IEffect { return NewPan() })
+	resound.RegisterEffect("Delay", func() resound.IEffect { return NewDelay() })
+	resound.RegisterEffect("Distort", func() resound.IEffect { return NewDistort() })
+	resound.RegisterEffect("LowpassFilter", func() resound.IEffect { return NewLowpassFilter() })
+	resound.RegisterEffect("HighpassFilter", func() resound.IEffect { return NewHighpassFilter() })
+	resound.RegisterEffect("Bitcrush", func() resound.IEffect { return NewBitcrush() })
+	resound.RegisterEffect("PitchShift", func() resound.IEffect { return NewPitchShift(1024) })
+	resound.RegisterEffect("BandpassFilter", func() resound.IEffect { return NewBandpassFilter() })
+	resound.RegisterEffect("NotchFilter", func() resound.IEffect { return NewNotchFilter() })
+	resound.RegisterEffect("Compressor", func() resound.IEffect { return NewCompressor() })
+	resound.RegisterEffect("EQ", func() resound.IEffect { return NewEQ() })
+	resound.RegisterEffect("Flanger", func() resound.IEffect { return NewFlanger() })
+	resound.RegisterEffect("Limiter", func() resound.IEffect { return NewLimiter() })
+	resound.RegisterEffect("Pan3D", func() resound.IEffect { return NewPan3D() })
+	resound.RegisterEffect("Passthrough", func() resound.IEffect { return NewPassthrough() })
+	resound.RegisterEffect("Reverb", func() resound.IEffect { return NewReverb() })
+	resound.RegisterEffect("StereoWidth", func() resound.IEffect { return NewStereoWidth() })
+	resound.RegisterEffect("Tremolo", func() resound.IEffect { return NewTremolo() })
+	resound.RegisterEffect("Vibrato", func() resound.IEffect { return NewVibrato() })
+	resound.RegisterEffect("RingMod", func() resound.IEffect { return NewRingMod() })
+	resound.RegisterEffect("Downsampler", func() resound.IEffect { return NewDownsampler() })
+	resound.RegisterEffect("AutoWah", func() resound.IEffect { return NewAutoWah() })
+	resound.RegisterEffect("ConvolutionReverb", func() resound.IEffect { return NewConvolutionReverb() })
+	resound.RegisterEffect("Haas", func() resound.IEffect { return NewHaas() })
+	resound.RegisterEffect("ChannelRouter", func() resound.IEffect { return NewChannelRouter() })
+	resound.RegisterEffect("DCBlocker", func() resound.IEffect { return NewDCBlocker() })
+}
+
+// VolumeCurve indicates how a Volume effect maps its strength (from 0 to 1) to the gain it applies to the audio.
+// Strengths over 1 are always applied as-is, regardless of the curve.
+type VolumeCurve int
+
+const (
+	// VolumeCurveSine eases the strength on a sine curve (1 - cos(strength * Pi / 2)), so the volume rises slowly at first and faster
+	// towards full strength. For example, a strength of 0.5 gives a gain of about 0.29 (about -10.7 dB). This is the default.
+	VolumeCurveSine VolumeCurve = iota
+	// VolumeCurveLinear uses the strength as the gain directly, so a strength of 0.5 gives a gain of 0.5 (about -6 dB).
+	VolumeCurveLinear
+	// VolumeCurveLogarithmic maps the strength linearly onto a range of 60 decibels, from -60 dB at a strength just over 0 to
+	// 0 dB at a strength of 1, with a strength of 0 being silent. As loudness is perceived logarithmically, this makes evenly-spaced
+	// strengths sound evenly-spaced in volume, so sliders and fades sound natural. For example, a strength of 0.5 gives -30 dB.
+	VolumeCurveLogarithmic
+)
+
+// volumeCurveRange is the range (in decibels) covered by VolumeCurveLogarithmic.
+const volumeCurveRange = 60
+
 // Volume is an effect that changes the overall volume of the incoming audio byte stream.
 type Volume struct {
+	baseEffect
+
 	strength      float64
+	strengthRamp  smoothedParam
+	curve         VolumeCurve
+	gainDB        float64
 	normalization float64
-	active        bool
 	Source        io.ReadSeeker
 
+	loudnessTarget   float64
+	measuredLoudness float64
+
 	fadeStart  float64
 	fadeChange float64
 	fadeTime   float64
 	fade       float64
+
+	envelope        *resound.Envelope
+	envelopeSamples int64
 }
 
 // NewVolume creates a new Volume effect. source is the source stream to apply this effect to.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewVolume() *Volume {
-	volume := &Volume{strength: 1, active: true, normalization: 1, fadeTime: -1}
+	volume := &Volume{strength: 1, baseEffect: newBaseEffect(), normalization: 1, fadeTime: -1, loudnessTarget: math.NaN(), measuredLoudness: math.NaN()}
 	return volume
 }
 
@@ -33,19 +158,59 @@ func NewVolume() *Volume {
 func (v *Volume) Clone() resound.IEffect {
 	return &Volume{
 		strength:      v.strength,
-		active:        v.active,
+		strengthRamp:  v.strengthRamp,
+		curve:         v.curve,
+		gainDB:        v.gainDB,
+		baseEffect:    v.baseEffect.clone(),
 		Source:        v.Source,
 		normalization: v.normalization,
 		fadeStart:     v.fadeStart,
-		fadeChange:    v.fadeChange,
-		fadeTime:      v.fadeTime,
-		fade:          v.fade,
+
+		loudnessTarget:   v.loudnessTarget,
+		measuredLoudness: v.measuredLoudness,
+		fadeChange:       v.fadeChange,
+		fadeTime:         v.fadeTime,
+		fade:             v.fade,
+		envelope:         v.envelope,
+		envelopeSamples:  v.envelopeSamples,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (v *Volume) Parameters() map[string]float64 {
+	params := map[string]float64{
+		"active":        boolToFloat(v.active),
+		"mix":           v.mix,
+		"strength":      v.strength,
+		"volumeCurve":   float64(v.curve),
+		"gainDB":        v.gainDB,
+		"normalization": v.normalization,
 	}
+	// The loudness settings are only included if they've been set, as setting them enables loudness matching.
+	if !math.IsNaN(v.loudnessTarget) {
+		params["loudnessTarget"] = v.loudnessTarget
+	}
+	if !math.IsNaN(v.measuredLoudness) {
+		params["measuredLoudness"] = v.measuredLoudness
+	}
+	return params
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (v *Volume) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { v.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { v.SetMix(x) })
+	setParam(params, "strength", func(x float64) { v.SetStrength(x) })
+	setParam(params, "volumeCurve", func(x float64) { v.SetVolumeCurve(VolumeCurve(x)) })
+	setParam(params, "gainDB", func(x float64) { v.SetGainDB(x) })
+	setParam(params, "normalization", func(x float64) { v.SetNormalizationFactor(x) })
+	setParam(params, "loudnessTarget", func(x float64) { v.SetLoudnessTarget(x) })
+	setParam(params, "measuredLoudness", func(x float64) { v.SetMeasuredLoudness(x) })
 }
 
 func (v *Volume) Read(p []byte) (n int, err error) {
 
-	if n, err = v.Source.Read(p); err != nil {
+	if n, err = v.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -61,19 +226,26 @@ func (v *Volume) ApplyEffect(p []byte, bytesRead int) {
 		return
 	}
 
-	perc := v.strength
-	if v.strength <= 1 {
-		perc = float64(ease.InSine(float32(v.strength), 0, 1, 1))
+	if v.blends() {
+		v.storeDry(p, bytesRead)
+		defer v.blendDry(p, bytesRead)
 	}
 
-	perc *= v.normalization
+	gain := resound.DBToLinear(v.gainDB) * v.normalization * v.loudnessGain()
+	perc := v.curveGain() * gain
+
+	// If the volume doesn't change over the course of the buffer, the whole buffer can be scaled at once, which is much faster.
+	if v.envelope == nil && !v.strengthRamp.ramping() && !v.IsFading() {
+		resound.AudioBuffer(p[:bytesRead]).Scale(perc * v.fadeVolume())
+		return
+	}
 
 	// Make an audioBuffer buffer for easy stream manipulation.
 	audioBuffer := resound.AudioBuffer(p)
 
 	// Loop through all frames in the stream that are available to be read.
 
-	sampleRate := audio.CurrentContext().SampleRate()
+	sampleRate := resound.SampleRate()
 
 	// We use bytesRead / 4 here because it's PCM audio
 	brf := float64(bytesRead / 4)
@@ -86,6 +258,11 @@ func (v *Volume) ApplyEffect(p []byte, bytesRead int) {
 		// Get the audio value:
 		l, r := audioBuffer.Get(i)
 
+		if v.strengthRamp.ramping() {
+			v.strengthRamp.advance(&v.strength)
+			perc = v.curveGain() * gain
+		}
+
 		if v.fadeTime >= 0 {
 			if v.fade < v.fadeTime {
 				v.fade += time / brf
@@ -95,9 +272,15 @@ func (v *Volume) ApplyEffect(p []byte, bytesRead int) {
 			}
 		}
 
+		envelopeFactor := 1.0
+		if v.envelope != nil {
+			envelopeFactor = v.envelope.ValueAt(v.envelopeSamples)
+			v.envelopeSamples++
+		}
+
 		// Multiply it by the volume strength:
-		l *= perc * fadeFactor
-		r *= perc * fadeFactor
+		l *= perc * fadeFactor * envelopeFactor
+		r *= perc * fadeFactor * envelopeFactor
 
 		// Set it back, and you're done.
 		audioBuffer.Set(i, l, r)
@@ -123,33 +306,139 @@ func (v *Volume) Active() bool {
 	return v.active
 }
 
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (v *Volume) SetMix(mix float64) *Volume {
+	v.setMix(mix)
+	return v
+}
+
 // SetNormalizationFactor sets the normalization factor for the Volume effect.
 // This should be obtained from an AudioProperties Analysis.
 func (v *Volume) SetNormalizationFactor(normalization float64) {
 	v.normalization = normalization
 }
 
+// SetLoudnessTarget sets the loudness (in LUFS) the Volume effect should adjust its audio to match, based on the audio's measured
+// loudness (see SetMeasuredLoudness()). As loudness reflects how loud audio is perceived to be rather than just its peaks, this
+// matches the volume of different tracks more consistently than normalizing them. -16 to -23 LUFS are common targets.
+func (v *Volume) SetLoudnessTarget(lufs float64) *Volume {
+	v.loudnessTarget = lufs
+	return v
+}
+
+// LoudnessTarget returns the loudness (in LUFS) the Volume effect adjusts its audio to match, or NaN if it hasn't been set.
+func (v *Volume) LoudnessTarget() float64 {
+	return v.loudnessTarget
+}
+
+// SetMeasuredLoudness sets the measured loudness (in LUFS) of the audio playing through the Volume effect, used with SetLoudnessTarget().
+// This should be obtained from an AudioProperties Analysis (through AnalysisResult.LUFS).
+func (v *Volume) SetMeasuredLoudness(lufs float64) *Volume {
+	v.measuredLoudness = lufs
+	return v
+}
+
+// MeasuredLoudness returns the measured loudness (in LUFS) of the audio playing through the Volume effect, or NaN if it hasn't been set.
+func (v *Volume) MeasuredLoudness() float64 {
+	return v.measuredLoudness
+}
+
+// loudnessGain returns the gain needed to bring the measured loudness to the loudness target, or 1 if either isn't set.
+func (v *Volume) loudnessGain() float64 {
+	if math.IsNaN(v.loudnessTarget) || math.IsNaN(v.measuredLoudness) || math.IsInf(v.measuredLoudness, 0) {
+		return 1
+	}
+	return resound.DBToLinear(v.loudnessTarget - v.measuredLoudness)
+}
+
 // SetStrength sets the strength of the Volume effect to the specified percentage.
 // The lowest possible value is 0.0, with 1.0 taking a 100% effect.
-// The volume is altered on a sine-based easing curve.
+// The volume is altered on a sine-based easing curve by default; see SetVolumeCurve().
 // At over 100% volume, the sound is clipped as necessary.
 func (v *Volume) SetStrength(strength float64) *Volume {
 	if strength < 0 {
 		strength = 0
 	}
 	v.strength = strength
+	v.strengthRamp.stop()
 	return v
 }
 
-// Strength returns the strength of the Volume effect as a percentage.
+// SetStrengthSmoothed ramps the strength of the Volume effect to the given strength over the given time in seconds.
+// Unlike calling SetStrength() every frame, the ramp advances with every sample of audio played through the effect, so the volume
+// changes smoothly without "zipper" noise. Calling SetStrength() stops the ramp.
+func (v *Volume) SetStrengthSmoothed(strength, rampTime float64) *Volume {
+	if strength < 0 {
+		strength = 0
+	}
+	v.strengthRamp.rampTo(v.strength, strength, rampTime)
+	return v
+}
+
+// Strength returns the strength of the Volume effect as a percentage. While the strength is being smoothed (see SetStrengthSmoothed()),
+// this is the current strength partway through the ramp.
 func (v *Volume) Strength() float64 {
 	return v.strength
 }
 
+// SetVolumeCurve sets how the Volume effect maps its strength to the gain it applies (see the VolumeCurve constants).
+// Defaults to VolumeCurveSine.
+func (v *Volume) SetVolumeCurve(curve VolumeCurve) *Volume {
+	v.curve = curve
+	return v
+}
+
+// VolumeCurve returns how the Volume effect maps its strength to the gain it applies.
+func (v *Volume) VolumeCurve() VolumeCurve {
+	return v.curve
+}
+
+// curveGain returns the gain for the Volume effect's strength, according to its volume curve.
+func (v *Volume) curveGain() float64 {
+
+	if v.strength > 1 {
+		return v.strength
+	}
+
+	switch v.curve {
+	case VolumeCurveLinear:
+		return v.strength
+	case VolumeCurveLogarithmic:
+		if v.strength <= 0 {
+			return 0
+		}
+		return resound.DBToLinear((v.strength - 1) * volumeCurveRange)
+	default:
+		return float64(ease.InSine(float32(v.strength), 0, 1, 1))
+	}
+
+}
+
+// SetGainDB sets a gain (in decibels) for the Volume effect to apply, with 0 dB (the default) leaving the volume unchanged,
+// -6 dB roughly halving it, and +6 dB roughly doubling it.
+// The gain is separate from the strength; it's applied on top of the eased strength rather than replacing it, so the two don't
+// fight each other. This means the strength can be used for fades or a volume slider while the gain sets a fixed level for the sound
+// (e.g. a strength of 1 with a gain of -12 dB plays the sound 12 dB down, and the strength eases from there).
+// To control the volume purely in decibels, leave the strength at 1.
+func (v *Volume) SetGainDB(db float64) *Volume {
+	v.gainDB = db
+	return v
+}
+
+// GainDB returns the gain (in decibels) the Volume effect applies on top of its strength.
+func (v *Volume) GainDB() float64 {
+	return v.gainDB
+}
+
 // StartFade starts a fade going from the provided start volume to the ending volume (in a 0 to 1 range),
-// ranging over the given amount of time in seconds.
-// If startVolume is less than 0, it will be set to the current starting volume.
+// ranging over the given amount of time in seconds. The fade advances as audio plays through the effect, so it's
+// independent of the game's frame rate.
+// If startVolume is less than 0, it will be set to the current fade volume (or 1 if no fade has been started).
 func (v *Volume) StartFade(startVolume, endVolume, fadeDuration float64) *Volume {
+	if startVolume < 0 {
+		startVolume = v.fadeVolume()
+	}
 	startVolume = clamp(startVolume, 0, 1)
 	endVolume = clamp(endVolume, 0, 1)
 	v.fadeChange = endVolume - startVolume
@@ -159,6 +448,48 @@ func (v *Volume) StartFade(startVolume, endVolume, fadeDuration float64) *Volume
 	return v
 }
 
+// IsFading returns if the Volume effect is in the middle of a fade.
+func (v *Volume) IsFading() bool {
+	return v.fadeTime >= 0 && v.fade < v.fadeTime
+}
+
+// FadeProgress returns how far through the current fade the Volume effect is, ranging from 0 (just started) to 1 (finished).
+// If no fade has been started, FadeProgress returns -1.
+func (v *Volume) FadeProgress() float64 {
+	if v.fadeTime < 0 {
+		return -1
+	}
+	if v.fadeTime == 0 {
+		return 1
+	}
+	return clamp(v.fade/v.fadeTime, 0, 1)
+}
+
+// fadeVolume returns the current volume of the fade, or 1 if no fade has been started.
+func (v *Volume) fadeVolume() float64 {
+	if v.fadeTime < 0 {
+		return 1
+	}
+	if v.fade >= v.fadeTime {
+		return v.fadeStart + v.fadeChange
+	}
+	return float64(ease.Linear(float32(v.fade), float32(v.fadeStart), float32(v.fadeChange), float32(v.fadeTime)))
+}
+
+// SetEnvelope sets an Envelope for the Volume effect to multiply its volume by, evaluated for each sample of audio played through it.
+// This allows for volume shapes more complex than a fade, like a swell, a gate, or a looping pulse. The Envelope starts from its
+// beginning when it's set. Setting the envelope to nil removes it.
+func (v *Volume) SetEnvelope(envelope *resound.Envelope) *Volume {
+	v.envelope = envelope
+	v.envelopeSamples = 0
+	return v
+}
+
+// Envelope returns the Envelope the Volume effect multiplies its volume by, or nil if one isn't set.
+func (v *Volume) Envelope() *resound.Envelope {
+	return v.envelope
+}
+
 // StopFade stops a fade in progress.
 func (v *Volume) StopFade() *Volume {
 	v.fadeChange = -1
@@ -199,9 +530,8 @@ func (v *Volume) StopFade() *Volume {
 // }
 
 // SetSource explicitly sets the active source for the effect - this is needed if you play an Effect manually, rather than through its Player or the Player's DSPChannel.
-func (volume *Volume) SetSource(source io.ReadSeeker) *Volume {
+func (volume *Volume) SetSource(source io.ReadSeeker) {
 	volume.Source = source
-	return volume
 }
 
 // // Loop is an effect that loops an incoming audio byte stream.
@@ -268,32 +598,96 @@ func (volume *Volume) SetSource(source io.ReadSeeker) *Volume {
 // 	loop.Source = source
 // }
 
+// PanLaw indicates how a Pan effect scales the volume of each channel as the sound moves across the stereo field.
+type PanLaw int
+
+const (
+	// PanLawLinear leaves both channels at full volume in the middle and linearly lowers the opposite channel as the sound
+	// is panned to one side. This makes sounds seem quieter as they move to the side.
+	PanLawLinear PanLaw = iota
+	// PanLawConstantPower uses sine and cosine curves so the combined power of both channels stays the same,
+	// keeping the apparent loudness steady as the sound moves across the stereo field. Each channel is 3 dB down in the middle.
+	PanLawConstantPower
+	// PanLawCompromise splits the difference (in decibels) between the linear and constant-power laws,
+	// with each channel being 1.5 dB down in the middle.
+	PanLawCompromise
+)
+
+// panGains returns the gain for the left and right channels for the given pan value (ranging from -1 to 1) and pan law.
+func panGains(law PanLaw, pan float64) (float64, float64) {
+
+	// This implementation uses a linear scale, ranging from -1 to 1, for stereo or mono sounds.
+	// If pan = 0.0, the balance for the sound in each speaker is at 100% left and 100% right.
+	// When pan is -1.0, only the left channel of the stereo sound is audible, when pan is 1.0,
+	// only the right channel of the stereo sound is audible.
+	// https://docs.unity3d.com/ScriptReference/AudioSource-panStereo.html
+	ls := math.Min(pan*-1+1, 1)
+	rs := math.Min(pan+1, 1)
+
+	if law == PanLawLinear {
+		return ls, rs
+	}
+
+	angle := (pan + 1) / 2 * math.Pi / 2
+	cl, cr := math.Cos(angle), math.Sin(angle)
+
+	if law == PanLawCompromise {
+		return math.Sqrt(ls * cl), math.Sqrt(rs * cr)
+	}
+
+	return cl, cr
+
+}
+
 // Pan is a panning effect, handling panning the sound between the left and right channels.
 type Pan struct {
-	pan    float64
-	active bool
-	Source io.ReadSeeker
+	baseEffect
+
+	pan     float64
+	panRamp smoothedParam
+	law     PanLaw
+	Source  io.ReadSeeker
 }
 
 // NewPan creates a new Pan effect. Panning defaults to 0 (the middle).
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewPan() *Pan {
-	pan := &Pan{active: true}
+	pan := &Pan{baseEffect: newBaseEffect()}
 	return pan
 }
 
 // Clone clones the effect, returning an resound.IEffect.
 func (pan *Pan) Clone() resound.IEffect {
 	return &Pan{
-		pan:    pan.pan,
-		active: pan.active,
-		Source: pan.Source,
+		pan:        pan.pan,
+		panRamp:    pan.panRamp,
+		law:        pan.law,
+		baseEffect: pan.baseEffect.clone(),
+		Source:     pan.Source,
 	}
 }
 
+// Parameters returns the effect's settings as a map of named parameters.
+func (pan *Pan) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(pan.active),
+		"mix":    pan.mix,
+		"pan":    pan.pan,
+		"law":    float64(pan.law),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (pan *Pan) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { pan.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { pan.SetMix(x) })
+	setParam(params, "pan", func(x float64) { pan.SetPan(x) })
+	setParam(params, "law", func(x float64) { pan.SetPanLaw(PanLaw(x)) })
+}
+
 func (pan *Pan) Read(p []byte) (n int, err error) {
 
-	if n, err = pan.Source.Read(p); err != nil {
+	if n, err = pan.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -308,19 +702,24 @@ func (pan *Pan) ApplyEffect(p []byte, bytesRead int) {
 		return
 	}
 
+	if pan.blends() {
+		pan.storeDry(p, bytesRead)
+		defer pan.blendDry(p, bytesRead)
+	}
+
 	if pan.pan < -1 {
 		pan.pan = -1
 	} else if pan.pan > 1 {
 		pan.pan = 1
 	}
 
-	// This implementation uses a linear scale, ranging from -1 to 1, for stereo or mono sounds.
-	// If pan = 0.0, the balance for the sound in each speaker is at 100% left and 100% right.
-	// When pan is -1.0, only the left channel of the stereo sound is audible, when pan is 1.0,
-	// only the right channel of the stereo sound is audible.
-	// https://docs.unity3d.com/ScriptReference/AudioSource-panStereo.html
-	ls := math.Min(pan.pan*-1+1, 1)
-	rs := math.Min(pan.pan+1, 1)
+	ls, rs := panGains(pan.law, pan.pan)
+
+	// If the panning doesn't change over the course of the buffer, the whole buffer can be scaled at once, which is much faster.
+	if !pan.panRamp.ramping() {
+		resound.AudioBuffer(p[:bytesRead]).ScaleStereo(ls, rs)
+		return
+	}
 
 	audio := resound.AudioBuffer(p)
 
@@ -328,6 +727,11 @@ func (pan *Pan) ApplyEffect(p []byte, bytesRead int) {
 
 		l, r := audio.Get(i)
 
+		if pan.panRamp.ramping() {
+			pan.panRamp.advance(&pan.pan)
+			ls, rs = panGains(pan.law, pan.pan)
+		}
+
 		l *= ls
 		r *= rs
 
@@ -355,6 +759,13 @@ func (pan *Pan) Active() bool {
 	return pan.active
 }
 
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (pan *Pan) SetMix(mix float64) *Pan {
+	pan.setMix(mix)
+	return pan
+}
+
 // SetPan sets the panning percentage for the pan effect.
 // The possible values range from -1 (hard left) to 1 (hard right).
 func (pan *Pan) SetPan(panPercent float64) *Pan {
@@ -364,6 +775,15 @@ func (pan *Pan) SetPan(panPercent float64) *Pan {
 		panPercent = -1
 	}
 	pan.pan = panPercent
+	pan.panRamp.stop()
+	return pan
+}
+
+// SetPanSmoothed ramps the panning of the Pan effect to the given value (ranging from -1 to 1) over the given time in seconds.
+// The ramp advances with every sample of audio played through the effect, so the sound moves smoothly without "zipper" noise.
+// Calling SetPan() stops the ramp.
+func (pan *Pan) SetPanSmoothed(panPercent, rampTime float64) *Pan {
+	pan.panRamp.rampTo(pan.pan, clamp(panPercent, -1, 1), rampTime)
 	return pan
 }
 
@@ -372,21 +792,35 @@ func (pan *Pan) Pan() float64 {
 	return pan.pan
 }
 
+// SetPanLaw sets the pan law used to scale the volume of each channel. Defaults to PanLawLinear.
+func (pan *Pan) SetPanLaw(law PanLaw) *Pan {
+	pan.law = law
+	return pan
+}
+
+// PanLaw returns the pan law used to scale the volume of each channel.
+func (pan *Pan) PanLaw() PanLaw {
+	return pan.law
+}
+
 // SetSource sets the active source for the effect.
-func (pan *Pan) SetSource(source io.ReadSeeker) *Pan {
+func (pan *Pan) SetSource(source io.ReadSeeker) {
 	pan.Source = source
-	return pan
 }
 
-// Delay is an effect that adds a delay to the sound.
+// Delay is an effect that adds a delay to the sound. Its output is the original (dry) signal plus the echoes (the wet signal),
+// each scaled by its own level; the first echo is the original signal scaled by the wet level, and each echo after it is scaled
+// by the feedback again.
 type Delay struct {
+	baseEffect
+
 	wait     float64
-	strength float64
+	dry      float64
+	wet      float64
 	feedback float64
 	Source   io.ReadSeeker
 
-	active bool
-	buffer [][2]float64
+	buffer circularBuffer
 }
 
 // NewDelay creates a new Delay effect.
@@ -394,11 +828,12 @@ type Delay struct {
 func NewDelay() *Delay {
 
 	return &Delay{
-		wait:     0.1,
-		strength: 1.0,
-		feedback: 0.5,
-		buffer:   [][2]float64{},
-		active:   true,
+		wait:       0.1,
+		dry:        1.0,
+		wet:        0.5,
+		feedback:   0.5,
+		buffer:     newCircularBuffer(0),
+		baseEffect: newBaseEffect(),
 	}
 
 }
@@ -406,17 +841,41 @@ func NewDelay() *Delay {
 // Clone creates a clone of the Delay effect.
 func (delay *Delay) Clone() resound.IEffect {
 	return &Delay{
-		wait:     delay.wait,
-		strength: delay.strength,
-		Source:   delay.Source,
-		feedback: delay.feedback,
-		active:   delay.active,
+		wait:       delay.wait,
+		dry:        delay.dry,
+		wet:        delay.wet,
+		Source:     delay.Source,
+		feedback:   delay.feedback,
+		baseEffect: delay.baseEffect.clone(),
+		buffer:     newCircularBuffer(0),
 	}
 }
 
+// Parameters returns the effect's settings as a map of named parameters.
+func (delay *Delay) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":   boolToFloat(delay.active),
+		"wait":     delay.wait,
+		"dry":      delay.dry,
+		"wet":      delay.wet,
+		"feedback": delay.feedback,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+// "strength" is accepted as another name for the wet level.
+func (delay *Delay) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { delay.SetActive(x != 0) })
+	setParam(params, "wait", func(x float64) { delay.SetWait(x) })
+	setParam(params, "dry", func(x float64) { delay.SetDryLevel(x) })
+	setParam(params, "strength", func(x float64) { delay.SetWetLevel(x) })
+	setParam(params, "wet", func(x float64) { delay.SetWetLevel(x) })
+	setParam(params, "feedback", func(x float64) { delay.SetFeedback(x) })
+}
+
 func (delay *Delay) Read(p []byte) (n int, err error) {
 
-	if n, err = delay.Source.Read(p); err != nil {
+	if n, err = delay.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -427,50 +886,56 @@ func (delay *Delay) Read(p []byte) (n int, err error) {
 
 func (delay *Delay) ApplyEffect(p []byte, bytesRead int) {
 
-	sampleRate := audio.CurrentContext().SampleRate()
-
-	audio := resound.AudioBuffer(p)
+	if !delay.active {
+		return
+	}
 
-	for i := 0; i < bytesRead/4; i++ {
+	sampleRate := resound.SampleRate()
 
-		l, r := audio.Get(i)
+	// The wait time is converted to frames using the context's sample rate, so a 0.5 second wait buffers 24000 frames at 48000hz.
+	waitSamples := int(float64(sampleRate) * delay.wait)
+	if waitSamples < 1 {
+		waitSamples = 1
+	}
 
-		bl := l
-		br := r
+	// The delay line is a ring buffer exactly as long as the wait time; it's resized (keeping the most recent audio) if the wait time changes.
+	delay.buffer.resize(waitSamples)
 
-		if len(delay.buffer) > 0 {
+	audio := resound.AudioBuffer(p)
 
-			bl += delay.buffer[0][0] * delay.feedback
-			br += delay.buffer[0][1] * delay.feedback
-			// l = bl
-			// r = br
-			l = mix(l, bl, delay.strength)
-			r = mix(r, br, delay.strength)
+	for i := 0; i < bytesRead/4; i++ {
 
-		}
+		l, r := audio.Get(i)
 
-		delay.buffer = append(delay.buffer, [2]float64{bl, br})
+		// The echo is the oldest signal in the delay line, from the wait time ago. It's fed back into the line scaled by the
+		// feedback, so with the default feedback of 0.5, each echo is half as loud as the one before it.
+		echoL, echoR := delay.buffer.oldest()
 
-		// 44100 For example
-		if len(delay.buffer) > int(float64(sampleRate)*delay.wait) {
-			delay.buffer = delay.buffer[1:]
-		}
+		delay.buffer.write(l+echoL*delay.feedback, r+echoR*delay.feedback)
 
-		if delay.active {
-			audio.Set(i, l, r)
-		}
+		audio.Set(i, l*delay.dry+echoL*delay.wet, r*delay.dry+echoR*delay.wet)
 
 	}
 
 }
 
+// Seek seeks the effect's source. Seeking to the start of the stream empties the delay line, so echoes
+// from before the seek don't bleed into the audio after it (for example, when a loop restarts).
 func (delay *Delay) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		delay.Reset()
+	}
 	if delay.Source == nil {
 		return 0, nil
 	}
 	return delay.Source.Seek(offset, whence)
 }
 
+// Reset clears the effect's delay line, as though it had just been created. Its settings are left unchanged.
+func (delay *Delay) Reset() {
+	delay.buffer.clear()
+}
+
 // SetActive sets the effect to be active.
 func (delay *Delay) SetActive(active bool) *Delay {
 	delay.active = active
@@ -482,6 +947,23 @@ func (delay *Delay) Active() bool {
 	return delay.active
 }
 
+// SetMix sets the dry and wet levels together, ranging from 0 (only the original signal) to 1 (only the echoes);
+// this is a shorthand for setting the dry level to 1 - mix and the wet level to mix.
+func (delay *Delay) SetMix(mix float64) *Delay {
+	mix = clamp(mix, 0, 1)
+	delay.dry = 1 - mix
+	delay.wet = mix
+	return delay
+}
+
+// Mix returns the share of the wet level in the Delay's dry and wet levels combined, ranging from 0 to 1.
+func (delay *Delay) Mix() float64 {
+	if delay.dry+delay.wet == 0 {
+		return 0
+	}
+	return delay.wet / (delay.dry + delay.wet)
+}
+
 // SetWait sets the overall wait time of the Delay effect in seconds as it's added on top of the original signal.
 // 0 is the minimum value.
 func (delay *Delay) SetWait(waitTime float64) *Delay {
@@ -497,22 +979,49 @@ func (delay *Delay) Wait() float64 {
 	return delay.wait
 }
 
-// SetStrength sets the overall volume of the Delay effect as it's added on top of the original signal.
-// 0 is the minimum value.
+// SetStrength sets the volume of the Delay effect's echoes. This is the same as the wet level (see SetWetLevel()).
 func (delay *Delay) SetStrength(strength float64) *Delay {
-	if strength < 0 {
-		strength = 0
+	return delay.SetWetLevel(strength)
+}
+
+// Strength returns the volume of the Delay effect's echoes. This is the same as the wet level.
+func (delay *Delay) Strength() float64 {
+	return delay.wet
+}
+
+// SetWetLevel sets the volume of the delayed signal (the echoes); the first echo is the original signal scaled by the wet level.
+// 0 is the minimum value, and the default is 0.5.
+func (delay *Delay) SetWetLevel(wet float64) *Delay {
+	if wet < 0 {
+		wet = 0
 	}
-	delay.strength = strength
+	delay.wet = wet
 	return delay
 }
 
-// Strength returns the strength of the Delay effect.
-func (delay *Delay) Strength() float64 {
-	return delay.strength
+// WetLevel returns the volume of the delayed signal (the echoes).
+func (delay *Delay) WetLevel() float64 {
+	return delay.wet
+}
+
+// SetDryLevel sets the volume of the original, unaltered signal. 0 is the minimum value, and the default is 1.
+// Setting the dry level to 0 makes the Delay output only the echoes.
+func (delay *Delay) SetDryLevel(dry float64) *Delay {
+	if dry < 0 {
+		dry = 0
+	}
+	delay.dry = dry
+	return delay
+}
+
+// DryLevel returns the volume of the original, unaltered signal.
+func (delay *Delay) DryLevel() float64 {
+	return delay.dry
 }
 
-// SetFeedback sets the feedback percentage of the delay. Each echo's volume is modulated by this percentage.
+// SetFeedback sets the feedback percentage of the delay, which is how much of each echo is fed back into the delay line to
+// be echoed again; each echo is the one before it scaled by the feedback. At 0, the Delay echoes the original signal just once.
+// Defaults to 0.5.
 func (delay *Delay) SetFeedback(feedbackPercentage float64) *Delay {
 	delay.feedback = clamp(feedbackPercentage, 0, 1)
 	return delay
@@ -524,39 +1033,69 @@ func (delay *Delay) Feedback() float64 {
 }
 
 // SetSource sets the active source for the effect.
-func (delay *Delay) SetSource(source io.ReadSeeker) *Delay {
+func (delay *Delay) SetSource(source io.ReadSeeker) {
 	delay.Source = source
-	return delay
 }
 
-// Distort distorts the stream that plays through it, clipping the signal.
+// ClipMode indicates how the Distort effect clips the signal.
+type ClipMode int
+
+const (
+	ClipSoft ClipMode = iota // The signal is rounded off smoothly as it gets louder (using tanh), like an overdriven tube amp.
+	ClipHard                 // The signal is cut off sharply at full volume, which sounds harsher and buzzier.
+)
+
+// Distort distorts the stream that plays through it by boosting the signal and then clipping it (waveshaping).
 type Distort struct {
-	Source          io.ReadSeeker
-	crushPercentage float64
-	active          bool
+	baseEffect
+
+	Source   io.ReadSeeker
+	drive    float64
+	clipMode ClipMode
 }
 
 // NewDistort creates a new Distort effect.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewDistort() *Distort {
 	return &Distort{
-		crushPercentage: 0,
-		active:          true,
+		drive:      0,
+		baseEffect: newBaseEffect(),
 	}
 }
 
 // Clone clones the effect, returning an resound.IEffect.
 func (distort *Distort) Clone() resound.IEffect {
 	return &Distort{
-		crushPercentage: distort.crushPercentage,
-		Source:          distort.Source,
-		active:          distort.active,
+		drive:      distort.drive,
+		clipMode:   distort.clipMode,
+		Source:     distort.Source,
+		baseEffect: distort.baseEffect.clone(),
 	}
 }
 
+// Parameters returns the effect's settings as a map of named parameters.
+func (distort *Distort) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":   boolToFloat(distort.active),
+		"mix":      distort.mix,
+		"drive":    distort.drive,
+		"clipMode": float64(distort.clipMode),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+// The "crushPercentage" parameter used by earlier versions is also accepted, and sets the drive.
+func (distort *Distort) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { distort.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { distort.SetMix(x) })
+	setParam(params, "crushPercentage", func(x float64) { distort.SetDrive(x) })
+	setParam(params, "drive", func(x float64) { distort.SetDrive(x) })
+	setParam(params, "clipMode", func(x float64) { distort.SetClipMode(ClipMode(x)) })
+}
+
 func (distort *Distort) Read(p []byte) (n int, err error) {
 
-	if n, err = distort.Source.Read(p); err != nil {
+	if n, err = distort.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -565,24 +1104,38 @@ func (distort *Distort) Read(p []byte) (n int, err error) {
 	return
 }
 
+// distortMaxGain is the gain applied to the signal before clipping at full drive.
+const distortMaxGain = 50
+
 func (distort *Distort) ApplyEffect(p []byte, bytesRead int) {
 
-	if !distort.active || distort.crushPercentage <= 0 {
+	if !distort.active || distort.drive <= 0 {
 		return
 	}
 
+	if distort.blends() {
+		distort.storeDry(p, bytesRead)
+		defer distort.blendDry(p, bytesRead)
+	}
+
+	// The drive is mapped exponentially to the gain, so the amount of distortion increases evenly across the range.
+	gain := math.Pow(distortMaxGain, distort.drive)
+
+	// Soft clipping is scaled so that a full-volume input stays at full volume, rather than getting quieter at low drive.
+	softScale := 1 / math.Tanh(gain)
+
 	audio := resound.AudioBuffer(p)
 
 	for i := 0; i < bytesRead/4; i++ {
 
 		l, r := audio.Get(i)
 
-		if math.Abs(l) < distort.crushPercentage {
-			l = math.Round(l)
-		}
-
-		if math.Abs(r) < distort.crushPercentage {
-			r = math.Round(r)
+		if distort.clipMode == ClipHard {
+			l = clamp(l*gain, -1, 1)
+			r = clamp(r*gain, -1, 1)
+		} else {
+			l = math.Tanh(l*gain) * softScale
+			r = math.Tanh(r*gain) * softScale
 		}
 
 		audio.Set(i, l, r)
@@ -609,58 +1162,118 @@ func (distort *Distort) Active() bool {
 	return distort.active
 }
 
-// CrushPercentage returns the crush percentage of the Distort effect.
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (distort *Distort) SetMix(mix float64) *Distort {
+	distort.setMix(mix)
+	return distort
+}
+
+// SetDrive sets how hard the signal is driven into clipping, ranging from 0 (no distortion) to 1 (heavy distortion, with the signal
+// boosted by about 34 dB before clipping). Defaults to 0.
+func (distort *Distort) SetDrive(drive float64) *Distort {
+	distort.drive = clamp(drive, 0, 1)
+	return distort
+}
+
+// Drive returns how hard the signal is driven into clipping, ranging from 0 to 1.
+func (distort *Distort) Drive() float64 {
+	return distort.drive
+}
+
+// SetClipMode sets how the signal is clipped once it's driven past full volume. Defaults to ClipSoft.
+func (distort *Distort) SetClipMode(mode ClipMode) *Distort {
+	distort.clipMode = mode
+	return distort
+}
+
+// ClipMode returns how the signal is clipped once it's driven past full volume.
+func (distort *Distort) ClipMode() ClipMode {
+	return distort.clipMode
+}
+
+// CrushPercentage returns the drive of the Distort effect.
+//
+// Deprecated: Use Drive() instead.
 func (distort *Distort) CrushPercentage() float64 {
-	return distort.crushPercentage
+	return distort.drive
 }
 
-// SetCrushPercentage sets the overall crush percentage of the Distort effect.
-// Any value below this in percentage amplitude is rounded off.
-// 0 is the minimum value.
+// SetCrushPercentage sets the drive of the Distort effect, ranging from 0 to 1.
+//
+// Deprecated: Use SetDrive() instead. Earlier versions of Distort rounded quiet samples off to silence rather than distorting them;
+// the crush percentage now sets the drive instead.
 func (distort *Distort) SetCrushPercentage(strength float64) *Distort {
-	strength = clamp(strength, 0, 1)
-	distort.crushPercentage = strength
-	return distort
+	return distort.SetDrive(strength)
 }
 
 // SetSource sets the active source for the effect.
-func (distort *Distort) SetSource(source io.ReadSeeker) *Distort {
+func (distort *Distort) SetSource(source io.ReadSeeker) {
 	distort.Source = source
-	return distort
 }
 
-// LowpassFilter represents a low-pass filter for a source audio stream.
+// LowpassFilter represents a low-pass filter for a source audio stream, which lets frequencies below its cutoff frequency through
+// while cutting out frequencies above it, making audio sound muffled.
 type LowpassFilter struct {
-	Source    io.ReadSeeker
-	active    bool
-	prevLeft  float64
-	prevRight float64
-	strength  float64
+	baseEffect
+
+	Source     io.ReadSeeker
+	cutoff     float64
+	cutoffRamp smoothedParam
+	resonance  float64
+
+	filter     biquad
+	sampleRate int
+	dirty      bool
 }
 
 // NewLowpassFilter creates a new low-pass filter for the given source stream.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewLowpassFilter() *LowpassFilter {
 
-	return &LowpassFilter{
-		strength: 0.5,
-		active:   true,
+	lpf := &LowpassFilter{
+		resonance:  defaultResonance,
+		baseEffect: newBaseEffect(),
 	}
+	lpf.SetStrength(0.5)
+
+	return lpf
 
 }
 
 // Clone clones the effect, returning an resound.IEffect.
 func (lpf *LowpassFilter) Clone() resound.IEffect {
 	return &LowpassFilter{
-		strength: lpf.strength,
-		Source:   lpf.Source,
-		active:   lpf.active,
+		cutoff:     lpf.cutoff,
+		resonance:  lpf.resonance,
+		Source:     lpf.Source,
+		baseEffect: lpf.baseEffect.clone(),
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (lpf *LowpassFilter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":    boolToFloat(lpf.active),
+		"mix":       lpf.mix,
+		"cutoff":    lpf.cutoff,
+		"resonance": lpf.resonance,
 	}
 }
 
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (lpf *LowpassFilter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { lpf.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { lpf.SetMix(x) })
+	setParam(params, "strength", func(x float64) { lpf.SetStrength(x) })
+	setParam(params, "cutoff", func(x float64) { lpf.SetCutoff(x) })
+	setParam(params, "resonance", func(x float64) { lpf.SetResonance(x) })
+}
+
 func (lpf *LowpassFilter) Read(p []byte) (n int, err error) {
 
-	if n, err = lpf.Source.Read(p); err != nil {
+	if n, err = lpf.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -676,33 +1289,53 @@ func (lpf *LowpassFilter) ApplyEffect(p []byte, bytesRead int) {
 		return
 	}
 
-	alpha := math.Sin(lpf.strength * math.Pi / 2)
+	if lpf.blends() {
+		lpf.storeDry(p, bytesRead)
+		defer lpf.blendDry(p, bytesRead)
+	}
+
+	if sampleRate := resound.SampleRate(); lpf.dirty || sampleRate != lpf.sampleRate {
+		lpf.sampleRate = sampleRate
+		lpf.filter.set(biquadLowpass, lpf.cutoff, lpf.resonance, 0, sampleRate)
+		lpf.dirty = false
+	}
+
 	audio := resound.AudioBuffer(p)
 
-	// TODO: Make low-pass / high-pass filters better quality.
 	for i := 0; i < bytesRead/4; i++ {
 
-		l, r := audio.Get(i)
+		// While the cutoff is being smoothed, the coefficients are only updated every few samples, as recalculating them is expensive.
+		if lpf.cutoffRamp.ramping() {
+			lpf.cutoffRamp.advance(&lpf.cutoff)
+			if i%smoothedParamInterval == 0 || !lpf.cutoffRamp.ramping() {
+				lpf.filter.set(biquadLowpass, lpf.cutoff, lpf.resonance, 0, lpf.sampleRate)
+			}
+		}
 
-		l = (1-alpha)*l + (lpf.prevLeft * alpha)
-		r = (1-alpha)*r + (lpf.prevRight * alpha)
+		l, r := audio.Get(i)
 
-		lpf.prevLeft = l
-		lpf.prevRight = r
-
-		audio.Set(i, l, r)
+		audio.Set(i, lpf.filter.process(0, l), lpf.filter.process(1, r))
 
 	}
 
 }
 
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history (see Reset()).
 func (lpf *LowpassFilter) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		lpf.Reset()
+	}
 	if lpf.Source == nil {
 		return 0, nil
 	}
 	return lpf.Source.Seek(offset, whence)
 }
 
+// Reset clears the effect's filter history, as though it had just been created. Its settings are left unchanged.
+func (lpf *LowpassFilter) Reset() {
+	lpf.filter.reset()
+}
+
 // SetActive sets the effect to be active.
 func (lpf *LowpassFilter) SetActive(active bool) *LowpassFilter {
 	lpf.active = active
@@ -714,53 +1347,129 @@ func (lpf *LowpassFilter) Active() bool {
 	return lpf.active
 }
 
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (lpf *LowpassFilter) SetMix(mix float64) *LowpassFilter {
+	lpf.setMix(mix)
+	return lpf
+}
+
+// Strength returns the strength of the LowpassFilter, ranging from 0 to 1, as derived from its cutoff frequency.
+//
+// Deprecated: Use Cutoff() instead.
 func (lpf *LowpassFilter) Strength() float64 {
-	return lpf.strength
+	return 1 - frequencyToStrength(lpf.cutoff)
 }
 
+// SetStrength sets the strength of the LowpassFilter, ranging from 0 (no filtering) to 1 (heavily muffled).
+// The strength is mapped to a cutoff frequency on a logarithmic scale, from 20000hz at 0 to 20hz at 1.
+//
+// Deprecated: Use SetCutoff() instead.
 func (lpf *LowpassFilter) SetStrength(strength float64) *LowpassFilter {
-	strength = clamp(strength, 0, 1)
-	lpf.strength = strength
+	return lpf.SetCutoff(strengthToFrequency(1 - clamp(strength, 0, 1)))
+}
+
+// SetCutoff sets the cutoff frequency of the LowpassFilter in hertz; frequencies above the cutoff are filtered out.
+func (lpf *LowpassFilter) SetCutoff(hz float64) *LowpassFilter {
+	lpf.cutoff = clamp(hz, minFilterFrequency, maxFilterFrequency)
+	lpf.cutoffRamp.stop()
+	lpf.dirty = true
 	return lpf
 }
 
+// SetCutoffSmoothed ramps the cutoff frequency of the LowpassFilter to the given frequency in hertz over the given time in seconds.
+// The ramp advances with the audio played through the effect, so the filter sweeps smoothly without "zipper" noise.
+// The cutoff changes linearly in hertz. Calling SetCutoff() (or SetStrength()) stops the ramp.
+func (lpf *LowpassFilter) SetCutoffSmoothed(hz, rampTime float64) *LowpassFilter {
+	lpf.cutoffRamp.rampTo(lpf.cutoff, clamp(hz, minFilterFrequency, maxFilterFrequency), rampTime)
+	return lpf
+}
+
+// Cutoff returns the cutoff frequency of the LowpassFilter in hertz.
+func (lpf *LowpassFilter) Cutoff() float64 {
+	return lpf.cutoff
+}
+
+// SetResonance sets the resonance (Q) of the LowpassFilter, which boosts the frequencies around the cutoff frequency.
+// The default is about 0.707, which gives a flat response with no boost. The value is clamped from 0.1 to 20.
+func (lpf *LowpassFilter) SetResonance(resonance float64) *LowpassFilter {
+	lpf.resonance = clamp(resonance, 0.1, 20)
+	lpf.dirty = true
+	return lpf
+}
+
+// Resonance returns the resonance (Q) of the LowpassFilter.
+func (lpf *LowpassFilter) Resonance() float64 {
+	return lpf.resonance
+}
+
 // SetSource sets the active source for the effect.
-func (lpf *LowpassFilter) SetSource(source io.ReadSeeker) *LowpassFilter {
+func (lpf *LowpassFilter) SetSource(source io.ReadSeeker) {
 	lpf.Source = source
-	return lpf
 }
 
-// HighpassFilter represents a highpass filter for an audio stream.
+// HighpassFilter represents a highpass filter for an audio stream, which lets frequencies above its cutoff frequency through
+// while cutting out frequencies below it, making audio sound thin (and removing low rumble and DC offset).
 type HighpassFilter struct {
-	Source   io.ReadSeeker
-	active   bool
-	prev     [2]float64
-	strength float64
+	baseEffect
+
+	Source     io.ReadSeeker
+	cutoff     float64
+	cutoffRamp smoothedParam
+	resonance  float64
+
+	filter     biquad
+	sampleRate int
+	dirty      bool
 }
 
 // NewHighpassFilter creates a new high-pass filter for the given source stream.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewHighpassFilter() *HighpassFilter {
 
-	return &HighpassFilter{
-		strength: 0.8,
-		active:   true,
+	h := &HighpassFilter{
+		resonance:  defaultResonance,
+		baseEffect: newBaseEffect(),
 	}
+	h.SetStrength(0.8)
+
+	return h
 
 }
 
 // Clone clones the effect, returning an resound.IEffect.
 func (h *HighpassFilter) Clone() resound.IEffect {
 	return &HighpassFilter{
-		strength: h.strength,
-		Source:   h.Source,
-		active:   h.active,
+		cutoff:     h.cutoff,
+		resonance:  h.resonance,
+		Source:     h.Source,
+		baseEffect: h.baseEffect.clone(),
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (h *HighpassFilter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":    boolToFloat(h.active),
+		"mix":       h.mix,
+		"cutoff":    h.cutoff,
+		"resonance": h.resonance,
 	}
 }
 
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (h *HighpassFilter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { h.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { h.SetMix(x) })
+	setParam(params, "strength", func(x float64) { h.SetStrength(x) })
+	setParam(params, "cutoff", func(x float64) { h.SetCutoff(x) })
+	setParam(params, "resonance", func(x float64) { h.SetResonance(x) })
+}
+
 func (h *HighpassFilter) Read(p []byte) (n int, err error) {
 
-	if n, err = h.Source.Read(p); err != nil {
+	if n, err = h.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -776,37 +1485,53 @@ func (h *HighpassFilter) ApplyEffect(p []byte, bytesRead int) {
 		return
 	}
 
-	alpha := math.Sin(h.strength * math.Pi / 2)
-	audio := resound.AudioBuffer(p)
-
-	for i := 0; i < bytesRead/4; i++ {
+	if h.blends() {
+		h.storeDry(p, bytesRead)
+		defer h.blendDry(p, bytesRead)
+	}
 
-		l, r := audio.Get(i)
+	if sampleRate := resound.SampleRate(); h.dirty || sampleRate != h.sampleRate {
+		h.sampleRate = sampleRate
+		h.filter.set(biquadHighpass, h.cutoff, h.resonance, 0, sampleRate)
+		h.dirty = false
+	}
 
-		nl := (1-alpha)*l + ((l - h.prev[0]) * alpha)
-		nr := (1-alpha)*r + ((r - h.prev[1]) * alpha)
+	audio := resound.AudioBuffer(p)
 
-		// l = (1-alpha)*l + (h.prev[0] * alpha)
-		// r = (1-alpha)*r + (h.prev[1] * alpha)
+	for i := 0; i < bytesRead/4; i++ {
 
-		// fmt.Println(l, r, h.prev)
+		// While the cutoff is being smoothed, the coefficients are only updated every few samples, as recalculating them is expensive.
+		if h.cutoffRamp.ramping() {
+			h.cutoffRamp.advance(&h.cutoff)
+			if i%smoothedParamInterval == 0 || !h.cutoffRamp.ramping() {
+				h.filter.set(biquadHighpass, h.cutoff, h.resonance, 0, h.sampleRate)
+			}
+		}
 
-		audio.Set(i, nl, nr)
+		l, r := audio.Get(i)
 
-		h.prev[0] = l
-		h.prev[1] = r
+		audio.Set(i, h.filter.process(0, l), h.filter.process(1, r))
 
 	}
 
 }
 
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history (see Reset()).
 func (h *HighpassFilter) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		h.Reset()
+	}
 	if h.Source == nil {
 		return 0, nil
 	}
 	return h.Source.Seek(offset, whence)
 }
 
+// Reset clears the effect's filter history, as though it had just been created. Its settings are left unchanged.
+func (h *HighpassFilter) Reset() {
+	h.filter.reset()
+}
+
 // SetActive sets the effect to be active.
 func (h *HighpassFilter) SetActive(active bool) *HighpassFilter {
 	h.active = active
@@ -818,50 +1543,124 @@ func (h *HighpassFilter) Active() bool {
 	return h.active
 }
 
-// SetStrength sets the strength of the HighpassFilter.
-// The values are clamped from 0 to 1 (100%).
-func (h *HighpassFilter) SetStrength(strength float64) *HighpassFilter {
-	h.strength = clamp(strength, 0, 1)
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (h *HighpassFilter) SetMix(mix float64) *HighpassFilter {
+	h.setMix(mix)
 	return h
 }
 
-// Strength returns the strength of the HighpassFilter.
+// SetStrength sets the strength of the HighpassFilter, ranging from 0 (barely any filtering) to 1 (only the highest frequencies pass).
+// The strength is mapped to a cutoff frequency on a logarithmic scale, from 20hz at 0 to 20000hz at 1.
+//
+// Deprecated: Use SetCutoff() instead.
+func (h *HighpassFilter) SetStrength(strength float64) *HighpassFilter {
+	return h.SetCutoff(strengthToFrequency(clamp(strength, 0, 1)))
+}
+
+// Strength returns the strength of the HighpassFilter, ranging from 0 to 1, as derived from its cutoff frequency.
+//
+// Deprecated: Use Cutoff() instead.
 func (h *HighpassFilter) Strength() float64 {
-	return h.strength
+	return frequencyToStrength(h.cutoff)
+}
+
+// SetCutoff sets the cutoff frequency of the HighpassFilter in hertz; frequencies below the cutoff are filtered out.
+func (h *HighpassFilter) SetCutoff(hz float64) *HighpassFilter {
+	h.cutoff = clamp(hz, minFilterFrequency, maxFilterFrequency)
+	h.cutoffRamp.stop()
+	h.dirty = true
+	return h
+}
+
+// SetCutoffSmoothed ramps the cutoff frequency of the HighpassFilter to the given frequency in hertz over the given time in seconds.
+// The ramp advances with the audio played through the effect, so the filter sweeps smoothly without "zipper" noise.
+// The cutoff changes linearly in hertz. Calling SetCutoff() (or SetStrength()) stops the ramp.
+func (h *HighpassFilter) SetCutoffSmoothed(hz, rampTime float64) *HighpassFilter {
+	h.cutoffRamp.rampTo(h.cutoff, clamp(hz, minFilterFrequency, maxFilterFrequency), rampTime)
+	return h
+}
+
+// Cutoff returns the cutoff frequency of the HighpassFilter in hertz.
+func (h *HighpassFilter) Cutoff() float64 {
+	return h.cutoff
+}
+
+// SetResonance sets the resonance (Q) of the HighpassFilter, which boosts the frequencies around the cutoff frequency.
+// The default is about 0.707, which gives a flat response with no boost. The value is clamped from 0.1 to 20.
+func (h *HighpassFilter) SetResonance(resonance float64) *HighpassFilter {
+	h.resonance = clamp(resonance, 0.1, 20)
+	h.dirty = true
+	return h
+}
+
+// Resonance returns the resonance (Q) of the HighpassFilter.
+func (h *HighpassFilter) Resonance() float64 {
+	return h.resonance
 }
 
 // SetSource sets the active source for the effect.
-func (h *HighpassFilter) SetSource(source io.ReadSeeker) *HighpassFilter {
+func (h *HighpassFilter) SetSource(source io.ReadSeeker) {
 	h.Source = source
-	return h
 }
 
-// Bitcrush is an effect that changes the pitch of the incoming audio byte stream.
+// Bitcrush is an effect that lowers the fidelity of the incoming audio byte stream, for a crunchy, lo-fi sound.
+// It does this in two ways: reducing the bit depth (quantizing each sample to fewer levels of volume, which adds a gritty noise),
+// and downsampling (holding each sample for a number of samples, which lowers the sample rate and adds harsh, metallic aliasing).
 type Bitcrush struct {
-	strength float64
-	active   bool
-	Source   io.ReadSeeker
+	baseEffect
+
+	strength   float64
+	bitDepth   int
+	downsample int
+	Source     io.ReadSeeker
+
+	holdPhase float64
+	heldL     float64
+	heldR     float64
 }
 
 // NewBitcrush creates a new Bitcrush effect.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewBitcrush() *Bitcrush {
-	bitcrush := &Bitcrush{active: true, strength: 0.1}
+	bitcrush := &Bitcrush{baseEffect: newBaseEffect(), strength: 0.1, bitDepth: 16}
 	return bitcrush
 }
 
 // Clone clones the effect, returning an resound.IEffect.
 func (bitcrush *Bitcrush) Clone() resound.IEffect {
 	return &Bitcrush{
-		strength: bitcrush.strength,
-		active:   bitcrush.active,
-		Source:   bitcrush.Source,
+		strength:   bitcrush.strength,
+		bitDepth:   bitcrush.bitDepth,
+		downsample: bitcrush.downsample,
+		baseEffect: bitcrush.baseEffect.clone(),
+		Source:     bitcrush.Source,
 	}
 }
 
+// Parameters returns the effect's settings as a map of named parameters.
+func (bitcrush *Bitcrush) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":     boolToFloat(bitcrush.active),
+		"mix":        bitcrush.mix,
+		"strength":   bitcrush.strength,
+		"bitDepth":   float64(bitcrush.bitDepth),
+		"downsample": float64(bitcrush.downsample),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (bitcrush *Bitcrush) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { bitcrush.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { bitcrush.SetMix(x) })
+	setParam(params, "strength", func(x float64) { bitcrush.SetStrength(x) })
+	setParam(params, "bitDepth", func(x float64) { bitcrush.SetBitDepth(int(x)) })
+	setParam(params, "downsample", func(x float64) { bitcrush.SetDownsample(int(x)) })
+}
+
 func (bitcrush *Bitcrush) Read(p []byte) (n int, err error) {
 
-	if n, err = bitcrush.Source.Read(p); err != nil {
+	if n, err = bitcrush.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -870,44 +1669,87 @@ func (bitcrush *Bitcrush) Read(p []byte) (n int, err error) {
 	return
 }
 
+// settings returns the bit depth and the downsampling factor (the number of samples each sample is held for) to crush the audio with.
+// The downsampling factor is derived from the strength unless it's been set explicitly.
+func (bitcrush *Bitcrush) settings() (bits int, factor float64) {
+
+	bits = bitcrush.bitDepth
+	if bits <= 0 {
+		bits = 16
+	}
+
+	factor = float64(bitcrush.downsample)
+	if factor <= 0 {
+		s := ease.InExpo(float32(bitcrush.strength), 0, 1, 1)
+		// The hold length is scaled by the sample rate so the effect sounds the same regardless of the rate the context runs at.
+		factor = float64(s) * 1000 * float64(resound.SampleRate()) / 44100
+	}
+
+	return bits, math.Max(factor, 1)
+
+}
+
 func (bitcrush *Bitcrush) ApplyEffect(p []byte, bytesRead int) {
 
-	if !bitcrush.active || bitcrush.strength == 0 {
+	if !bitcrush.active {
 		return
 	}
 
-	audio := resound.AudioBuffer(p)
+	bits, factor := bitcrush.settings()
 
-	s := ease.InExpo(float32(bitcrush.strength), 0, 1, 1)
+	if bits >= 16 && factor <= 1 {
+		return
+	}
 
-	str := float64(s) * 1000
+	if bitcrush.blends() {
+		bitcrush.storeDry(p, bytesRead)
+		defer bitcrush.blendDry(p, bytesRead)
+	}
 
-	bufferSize := bytesRead / 4
+	levels := math.Pow(2, float64(bits-1))
 
-	// str := (bitcrush.strength) * 1000
+	audio := resound.AudioBuffer(p)
 
-	for i := 0; i < bufferSize; i++ {
+	for i := 0; i < bytesRead/4; i++ {
 
-		ri := int(math.Round(float64(i)/str) * str)
+		// A new sample is taken each time the hold phase wraps around; it carries over between buffers, so the hold length stays even.
+		if bitcrush.holdPhase <= 0 {
+			bitcrush.heldL, bitcrush.heldR = audio.Get(i)
+			bitcrush.holdPhase += factor
+		}
+		bitcrush.holdPhase--
+
+		l, r := bitcrush.heldL, bitcrush.heldR
 
-		if ri >= bufferSize {
-			ri = bufferSize - 1
+		if bits < 16 {
+			l = math.Round(l*levels) / levels
+			r = math.Round(r*levels) / levels
 		}
 
-		l, r := audio.Get(ri)
 		audio.Set(i, l, r)
 
 	}
 
 }
 
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's held sample (see Reset()).
 func (bitcrush *Bitcrush) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		bitcrush.Reset()
+	}
 	if bitcrush.Source == nil {
 		return 0, nil
 	}
 	return bitcrush.Source.Seek(offset, whence)
 }
 
+// Reset clears the effect's held sample, as though it had just been created. Its settings are left unchanged.
+func (bitcrush *Bitcrush) Reset() {
+	bitcrush.holdPhase = 0
+	bitcrush.heldL = 0
+	bitcrush.heldR = 0
+}
+
 // SetActive sets the effect to be active.
 func (bitcrush *Bitcrush) SetActive(active bool) *Bitcrush {
 	bitcrush.active = active
@@ -919,21 +1761,144 @@ func (bitcrush *Bitcrush) Active() bool {
 	return bitcrush.active
 }
 
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (bitcrush *Bitcrush) SetMix(mix float64) *Bitcrush {
+	bitcrush.setMix(mix)
+	return bitcrush
+}
+
 // Strength returns the strength of the Bitcrush effect as a percentage.
 func (bitcrush *Bitcrush) Strength() float64 {
 	return bitcrush.strength
 }
 
-// SetStrength sets the strength of the Bitcrush effect to the specified percentage.
+// SetStrength sets the strength of the Bitcrush effect to the specified percentage, which controls how far the audio is downsampled
+// unless the downsampling factor has been set explicitly with SetDownsample(). At full strength, each sample is held for around 1000 samples.
+// The strength doesn't change the bit depth, which is only reduced when set with SetBitDepth().
 func (bitcrush *Bitcrush) SetStrength(bitcrushFactor float64) *Bitcrush {
 	bitcrush.strength = clamp(bitcrushFactor, 0, 1)
 	return bitcrush
 }
 
+// SetBitDepth sets the number of bits each sample is quantized to, ranging from 1 (extremely harsh) to 16 (no quantization, as
+// the audio is already 16-bit). Defaults to 16, so the bit depth is left alone unless it's set; passing 0 or less also sets it to 16.
+func (bitcrush *Bitcrush) SetBitDepth(bits int) *Bitcrush {
+	if bits > 16 || bits <= 0 {
+		bits = 16
+	}
+	bitcrush.bitDepth = bits
+	return bitcrush
+}
+
+// BitDepth returns the number of bits each sample is quantized to; 16 means the audio isn't quantized.
+func (bitcrush *Bitcrush) BitDepth() int {
+	return bitcrush.bitDepth
+}
+
+// SetDownsample sets the downsampling factor; each sample is held for this many samples, so a factor of 4 plays the audio back
+// at a quarter of the sample rate. A factor of 1 leaves the sample rate as is.
+// Passing 0 or less makes the downsampling follow the strength instead (see SetStrength()).
+func (bitcrush *Bitcrush) SetDownsample(factor int) *Bitcrush {
+	if factor < 0 {
+		factor = 0
+	}
+	bitcrush.downsample = factor
+	return bitcrush
+}
+
+// Downsample returns the downsampling factor, or 0 if the downsampling follows the strength.
+func (bitcrush *Bitcrush) Downsample() int {
+	return bitcrush.downsample
+}
+
 // SetSource sets the active source for the effect.
-func (bitcrush *Bitcrush) SetSource(source io.ReadSeeker) *Bitcrush {
+func (bitcrush *Bitcrush) SetSource(source io.ReadSeeker) {
 	bitcrush.Source = source
-	return bitcrush
+}
+
+// InterpolationMode indicates how an effect reads audio that lies between two samples (e.g. when reading at a different speed
+// than the audio was written, like the PitchShift effect does).
+type InterpolationMode int
+
+const (
+	// InterpolationNone uses the nearest earlier sample without interpolating. This is the cheapest option, but the lowest fidelity.
+	InterpolationNone InterpolationMode = iota
+	// InterpolationLinear interpolates linearly between the two nearest samples. This is cheap and sounds good enough for most sounds.
+	InterpolationLinear
+	// InterpolationCubic interpolates using a cubic (Hermite) curve through the four nearest samples. This is the most expensive option,
+	// but sounds the smoothest, making it a good choice for music.
+	InterpolationCubic
+)
+
+// envelopeFollower tracks the level of a signal over time, rising at its attack speed and falling at its release speed.
+type envelopeFollower struct {
+	attack  float64
+	release float64
+	level   float64
+
+	// The settings the coefficients were last calculated for, so they're only recalculated when the settings change.
+	attackMS, releaseMS float64
+	sampleRate          int
+}
+
+// setTimes sets the attack and release times of the envelope follower in milliseconds. As this is called for every buffer,
+// the coefficients are only recalculated if the times or sample rate have changed since the last call.
+func (e *envelopeFollower) setTimes(attackMS, releaseMS float64, sampleRate int) {
+	if attackMS == e.attackMS && releaseMS == e.releaseMS && sampleRate == e.sampleRate {
+		return
+	}
+	e.attackMS, e.releaseMS, e.sampleRate = attackMS, releaseMS, sampleRate
+	e.attack = timeCoefficient(attackMS, sampleRate)
+	e.release = timeCoefficient(releaseMS, sampleRate)
+}
+
+// process feeds the given (positive) input level into the envelope follower, returning the new level.
+func (e *envelopeFollower) process(input float64) float64 {
+	coef := e.release
+	if input > e.level {
+		coef = e.attack
+	}
+	e.level = input + coef*(e.level-input)
+	return e.level
+}
+
+// timeCoefficient returns the coefficient for a one-pole smoothing filter that takes roughly the given number of milliseconds to respond.
+func timeCoefficient(ms float64, sampleRate int) float64 {
+	if ms <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (ms / 1000 * float64(sampleRate)))
+}
+
+// WaveformType indicates the shape of the wave an oscillator (like an LFO) produces.
+type WaveformType int
+
+const (
+	WaveformSine     WaveformType = iota // A smooth sine wave.
+	WaveformTriangle                     // A triangle wave, which rises and falls linearly.
+	WaveformSquare                       // A square wave, which switches abruptly between its lowest and highest values.
+)
+
+// oscillate returns the value of a wave of the given type at the given phase (in radians), ranging from -1 to 1.
+func oscillate(waveform WaveformType, phase float64) float64 {
+
+	switch waveform {
+	case WaveformTriangle:
+		t := math.Mod(phase/(2*math.Pi), 1)
+		if t < 0 {
+			t++
+		}
+		return 1 - 4*math.Abs(t-0.5)
+	case WaveformSquare:
+		if math.Sin(phase) >= 0 {
+			return 1
+		}
+		return -1
+	}
+
+	return math.Sin(phase)
+
 }
 
 type circularBuffer struct {
@@ -962,6 +1927,76 @@ func (c *circularBuffer) write(l, r float64) {
 
 }
 
+// clear empties the buffer, silencing its contents and resetting its read and write indices.
+func (c *circularBuffer) clear() {
+	for i := range c.buffer {
+		c.buffer[i] = [2]float64{}
+	}
+	c.readIndex = 0
+	c.writeIndex = 0
+}
+
+// oldest returns the oldest sample in the buffer, which is the next one to be overwritten.
+func (c circularBuffer) oldest() (l, r float64) {
+	if c.maxSize == 0 {
+		return 0, 0
+	}
+	return c.buffer[c.writeIndex][0], c.buffer[c.writeIndex][1]
+}
+
+// resize resizes the buffer, keeping as many of the most recently written samples as fit so the audio in the buffer stays continuous.
+func (c *circularBuffer) resize(size int) {
+
+	if size == c.maxSize {
+		return
+	}
+
+	newBuffer := make([][2]float64, size)
+
+	count := size
+	if c.maxSize < count {
+		count = c.maxSize
+	}
+
+	for i := 0; i < count; i++ {
+		src := c.writeIndex - 1 - i
+		if src < 0 {
+			src += c.maxSize
+		}
+		newBuffer[size-1-i] = c.buffer[src]
+	}
+
+	c.buffer = newBuffer
+	c.maxSize = size
+	c.writeIndex = 0
+	c.readIndex = 0
+
+}
+
+// readDelayed reads from the buffer the given number of samples (which can be fractional) behind the most recently written sample,
+// interpolating linearly between samples.
+func (c circularBuffer) readDelayed(delay float64) (l, r float64) {
+
+	if c.maxSize == 0 {
+		return 0, 0
+	}
+
+	pos := float64(c.writeIndex-1) - delay
+	for pos < 0 {
+		pos += float64(c.maxSize)
+	}
+
+	index := int(pos)
+	frac := pos - float64(index)
+	next := index + 1
+	if next >= c.maxSize {
+		next = 0
+	}
+
+	return mix(c.buffer[index][0], c.buffer[next][0], frac), mix(c.buffer[index][1], c.buffer[next][1], frac)
+
+}
+
 // readWriteDistance returns the distance between the write index and read index; note that this is the
 // shortest distance.
 func (c circularBuffer) readWriteDistance() float64 {
@@ -994,50 +2029,117 @@ func (c circularBuffer) read(offset int) (l, r float64) {
 	return c.buffer[readIndex][0], c.buffer[readIndex][1]
 }
 
+// readInterpolated reads from the buffer at the read index plus the given offset, interpolating between samples
+// using the provided interpolation mode.
+func (c circularBuffer) readInterpolated(offset int, mode InterpolationMode) (l, r float64) {
+
+	if mode == InterpolationNone {
+		return c.read(offset)
+	}
+
+	if !c.BufferFull() {
+		return 0, 0
+	}
+
+	index := int(c.readIndex)
+	frac := c.readIndex - float64(index)
+
+	sample := func(i int) [2]float64 {
+		i = (index + offset + i) % c.maxSize
+		if i < 0 {
+			i += c.maxSize
+		}
+		return c.buffer[i]
+	}
+
+	s1 := sample(0)
+	s2 := sample(1)
+
+	if mode == InterpolationLinear {
+		return mix(s1[0], s2[0], frac), mix(s1[1], s2[1], frac)
+	}
+
+	s0 := sample(-1)
+	s3 := sample(2)
+
+	return hermite(s0[0], s1[0], s2[0], s3[0], frac), hermite(s0[1], s1[1], s2[1], s3[1], frac)
+
+}
+
 func (c circularBuffer) BufferFull() bool {
 	return len(c.buffer) == c.maxSize
 }
 
 // PitchShift is an effect that changes the pitch of the incoming audio stream.
 type PitchShift struct {
+	baseEffect
+
 	strength float64
 	pitch    float64
-	active   bool
 	Source   io.ReadSeeker
 
-	pitchBuffer circularBuffer
+	pitchBuffer   circularBuffer
+	interpolation InterpolationMode
 }
 
 // −12log2(t1/t2) = how many semitones
 
 // NewPitchShift creates a new PitchShift effect.
-// bufferSize is the size of the buffer the pitch shift effect operates on.
+// bufferSize is the size of the buffer the pitch shift effect operates on, in frames; note that the same buffer size covers
+// less time at higher sample rates (1024 frames is about 23 milliseconds at 44100hz, but about 21 milliseconds at 48000hz).
 // The larger the buffer, the smoother it will sound, but the more echoing there will be as the effect runs through the buffer.
-// A buffer size of 1024, 2048, or 4096 are good starting points.
+// A buffer size of 1024, 2048, or 4096 are good starting points. The buffer size is clamped to at least 2 frames.
 // You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
 func NewPitchShift(bufferSize int) *PitchShift {
 	pitchShift := &PitchShift{
-		strength:    1,
-		active:      true,
-		pitch:       1,
-		pitchBuffer: newCircularBuffer(bufferSize),
+		strength:   1,
+		baseEffect: newBaseEffect(),
+		pitch:      1,
 	}
+	pitchShift.SetBufferSize(bufferSize)
 	return pitchShift
 }
 
+// minPitchBufferSize is the smallest buffer a PitchShift can use, as it reads from both its buffer's read position and the opposite side.
+const minPitchBufferSize = 2
+
 // Clone clones the effect, returning an resound.IEffect.
 func (p *PitchShift) Clone() resound.IEffect {
 	return &PitchShift{
-		strength: p.strength,
-		pitch:    p.pitch,
-		active:   p.active,
-		Source:   p.Source,
+		strength:      p.strength,
+		pitch:         p.pitch,
+		baseEffect:    p.baseEffect.clone(),
+		Source:        p.Source,
+		pitchBuffer:   newCircularBuffer(p.pitchBuffer.maxSize),
+		interpolation: p.interpolation,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (p *PitchShift) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":        boolToFloat(p.active),
+		"mix":           p.mix,
+		"strength":      p.strength,
+		"pitch":         p.pitch,
+		"interpolation": float64(p.interpolation),
+		"bufferSize":    float64(p.pitchBuffer.maxSize),
 	}
 }
 
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (p *PitchShift) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { p.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { p.SetMix(x) })
+	setParam(params, "strength", func(x float64) { p.SetStrength(x) })
+	setParam(params, "pitch", func(x float64) { p.SetPitch(x) })
+	setParam(params, "interpolation", func(x float64) { p.SetInterpolation(InterpolationMode(x)) })
+	setParam(params, "bufferSize", func(x float64) { p.SetBufferSize(int(x)) })
+}
+
 func (p *PitchShift) Read(byteSlice []byte) (n int, err error) {
 
-	if n, err = p.Source.Read(byteSlice); err != nil {
+	if n, err = p.Source.Read(byteSlice); err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
@@ -1053,6 +2155,11 @@ func (p *PitchShift) ApplyEffect(byteSlice []byte, bytesRead int) {
 		return
 	}
 
+	if p.blends() {
+		p.storeDry(byteSlice, bytesRead)
+		defer p.blendDry(byteSlice, bytesRead)
+	}
+
 	audio := resound.AudioBuffer(byteSlice)
 	bufferLength := bytesRead / 4
 
@@ -1064,7 +2171,7 @@ func (p *PitchShift) ApplyEffect(byteSlice []byte, bytesRead int) {
 		p.pitchBuffer.write(l, r)
 
 		// Reading from the buffer slower or faster than 1 per frame will give us a pitched result.
-		pitchedL, pitchedR := p.pitchBuffer.read(0)
+		pitchedL, pitchedR := p.pitchBuffer.readInterpolated(0, p.interpolation)
 
 		// After we do this, we could just increment the read index by pitch (so higher pitch values increment
 		// faster and lower values slower, giving higher pitch and lower pitch), but this alone would give
@@ -1082,7 +2189,7 @@ func (p *PitchShift) ApplyEffect(byteSlice []byte, bytesRead int) {
 		// https://schaumont.dyn.wpi.edu/ece4703b22/lab5x.html
 		// https://people.ece.cornell.edu/land/courses/ece5760/FinalProjects/s2017/jmt329_swc63_gzm3/jmt329_swc63_gzm3/PitchShifter/index.html
 
-		pitchedL2, pitchedR2 := p.pitchBuffer.read(p.pitchBuffer.maxSize / 2)
+		pitchedL2, pitchedR2 := p.pitchBuffer.readInterpolated(p.pitchBuffer.maxSize/2, p.interpolation)
 		cross := p.pitchBuffer.readWriteDistance() / float64(p.pitchBuffer.maxSize/2)
 		cross2 := 1 - cross
 
@@ -1097,13 +2204,23 @@ func (p *PitchShift) ApplyEffect(byteSlice []byte, bytesRead int) {
 
 }
 
+// Seek seeks the effect's source. Seeking to the start of the stream empties the pitch buffer, so audio
+// from before the seek doesn't bleed into the audio after it (for example, when a loop restarts).
 func (p *PitchShift) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		p.Reset()
+	}
 	if p.Source == nil {
 		return 0, nil
 	}
 	return p.Source.Seek(offset, whence)
 }
 
+// Reset clears the effect's pitch buffer, as though it had just been created. Its settings are left unchanged.
+func (p *PitchShift) Reset() {
+	p.pitchBuffer.clear()
+}
+
 // SetActive sets the effect to be active.
 func (p *PitchShift) SetActive(active bool) *PitchShift {
 	p.active = active
@@ -1115,6 +2232,13 @@ func (p *PitchShift) Active() bool {
 	return p.active
 }
 
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (p *PitchShift) SetMix(mix float64) *PitchShift {
+	p.setMix(mix)
+	return p
+}
+
 // SetStrength sets the strength of the PitchShift effect to the specified percentage.
 // The lowest possible value is 0.0, with 1.0 being the maximum and taking a 100% effect.
 func (p *PitchShift) SetStrength(strength float64) *PitchShift {
@@ -1134,9 +2258,8 @@ func (p *PitchShift) Strength() float64 {
 }
 
 // SetSource sets the active source for the effect.
-func (p *PitchShift) SetSource(source io.ReadSeeker) *PitchShift {
+func (p *PitchShift) SetSource(source io.ReadSeeker) {
 	p.Source = source
-	return p
 }
 
 // SetPitch sets the target pitch of the PitchShift effect to the specified percentage.
@@ -1154,102 +2277,56 @@ func (p *PitchShift) Pitch() float64 {
 	return p.pitch
 }
 
-// type Reverb struct {
-// 	FeedbackLoop bool
-// 	Source       io.ReadSeeker
-
-// 	active     bool
-// 	buffer     [][2]int16
-// 	bufferSize int
-// }
-
-// func NewReverb(source io.ReadSeeker, reverbBufferSize int) *Reverb {
-
-// 	return &Reverb{
-// 		Source:     source,
-// 		buffer:     make([][2]int16, reverbBufferSize),
-// 		bufferSize: reverbBufferSize,
-// 		active:     true,
-// 	}
-
-// }
-
-// func (reverb *Reverb) Clone() resound.IEffect {
-// 	return &Reverb{
-// 		FeedbackLoop: reverb.FeedbackLoop,
-// 		Source:       reverb.Source,
-
-// 		active: reverb.active,
-// 	}
-// }
-
-// func (reverb *Reverb) Read(p []byte) (n int, err error) {
-
-// 	_, err = reverb.Source.Read(p)
-// 	if err != nil {
-// 		return 0, err
-// 	}
-
-// 	reverb.ApplyEffect(p)
-
-// 	return len(p), nil
-
-// }
-
-// func (reverb *Reverb) ApplyEffect(p []byte) {
-
-// 	for i := 0; i < len(p); i += 4 {
-// 		lc := int16(p[i]) | int16(p[i+1])<<8
-// 		rc := int16(p[i+2]) | int16(p[i+3])<<8
-
-// 		reverb.buffer = append(reverb.buffer, [2]int16{lc, rc})
-
-// 		// 44100 For example
-// 		if len(reverb.buffer) > reverb.bufferSize {
-// 			reverb.buffer = reverb.buffer[1:]
-// 		}
-
-// 		l := 0.0
-// 		r := 0.0
-
-// 		for i := 0; i < len(reverb.buffer); i++ {
-// 			l += float64(reverb.buffer[i][0])
-// 			r += float64(reverb.buffer[i][1])
-// 		}
-
-// 		l /= float64(len(reverb.buffer))
-// 		r /= float64(len(reverb.buffer))
-
-// 		if reverb.active {
-
-// 			lc = int16(l)
-// 			rc = int16(r)
-
-// 		}
+// SetBufferSize sets the size of the buffer the PitchShift effect operates on, in frames (see NewPitchShift()).
+// The size is clamped to at least 2 frames. Changing the buffer size empties the buffer.
+func (p *PitchShift) SetBufferSize(bufferSize int) *PitchShift {
+	if bufferSize < minPitchBufferSize {
+		bufferSize = minPitchBufferSize
+	}
+	if bufferSize != p.pitchBuffer.maxSize {
+		p.pitchBuffer = newCircularBuffer(bufferSize)
+	}
+	return p
+}
 
-// 		p[i] = byte(lc)
-// 		p[i+1] = byte(lc >> 8)
-// 		p[i+2] = byte(rc)
-// 		p[i+3] = byte(rc >> 8)
+// BufferSize returns the size of the buffer the PitchShift effect operates on, in frames.
+func (p *PitchShift) BufferSize() int {
+	return p.pitchBuffer.maxSize
+}
 
-// 	}
+// Latency returns how long the PitchShift delays the audio by on average. When the pitch isn't 1, the read position sweeps through
+// the pitch buffer, so the audio is delayed by anywhere from none to the whole buffer (half of the buffer's length on average).
+// At a pitch of 1, the audio isn't delayed.
+func (p *PitchShift) Latency() time.Duration {
+	if p.pitch == 1 {
+		return 0
+	}
+	return time.Duration(float64(p.pitchBuffer.maxSize/2) / float64(resound.SampleRate()) * float64(time.Second))
+}
 
-// }
+// SetSemitones sets the target pitch of the PitchShift effect as an offset in musical semitones; 12 raises the pitch
+// by an octave, -7 lowers it by a fifth, and 0 leaves it unchanged. This stays in sync with SetPitch().
+func (p *PitchShift) SetSemitones(semitones float64) *PitchShift {
+	return p.SetPitch(math.Pow(2, semitones/12))
+}
 
-// func (reverb *Reverb) Seek(offset int64, whence int) (int64, error) {
-// 	if reverb.Source == nil {
-// 		return 0, nil
-// 	}
-// 	return reverb.Source.Seek(offset, whence)
-// }
+// Semitones returns the pitch of the PitchShift effect as an offset in musical semitones.
+// If the pitch is 0, this returns negative infinity.
+func (p *PitchShift) Semitones() float64 {
+	return 12 * math.Log2(p.pitch)
+}
 
-// func (reverb *Reverb) SetActive(active bool) {
-// 	reverb.active = active
-// }
+// SetInterpolation sets the interpolation mode used when reading the pitched audio. Higher quality modes
+// sound smoother, but cost more CPU. Defaults to InterpolationNone.
+func (p *PitchShift) SetInterpolation(mode InterpolationMode) *PitchShift {
+	p.interpolation = mode
+	return p
+}
 
-// func (reverb *Reverb) Active() bool {
-// 	return reverb.active
-// }
+// Interpolation returns the interpolation mode used when reading the pitched audio.
+func (p *PitchShift) Interpolation() InterpolationMode {
+	return p.interpolation
+}
 
 func clamp(v, min, max float64) float64 {
 	if v > max {
@@ -1260,6 +2337,28 @@ func clamp(v, min, max float64) float64 {
 	return v
 }
 
+// setParam calls the set function with the named parameter's value if it exists in the parameter map.
+func setParam(params map[string]float64, name string, set func(float64)) {
+	if value, ok := params[name]; ok {
+		set(value)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func mix(v1, v2, perc float64) float64 {
 	return v1 + ((v2 - v1) * perc)
 }
+
+// hermite returns the value at t (ranging from 0 to 1) between y1 and y2 on a cubic Hermite curve passing through y0, y1, y2, and y3.
+func hermite(y0, y1, y2, y3, t float64) float64 {
+	c1 := 0.5 * (y2 - y0)
+	c2 := y0 - 2.5*y1 + 2*y2 - 0.5*y3
+	c3 := 0.5*(y3-y0) + 1.5*(y1-y2)
+	return ((c3*t+c2)*t+c1)*t + y1
+}
@@ -0,0 +1,884 @@
+package effects
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+// testSine returns the given number of frames of a stereo sine wave with the given frequency and amplitude, at the default sample rate.
+func testSine(frames int, freq, amplitude float64) []byte {
+	data := make([]byte, frames*4)
+	buffer := resound.AudioBuffer(data)
+	for i := 0; i < frames; i++ {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(resound.SampleRate()))
+		buffer.Set(i, v, v)
+	}
+	return data
+}
+
+func TestPitchShiftBufferSize(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	for _, size := range []int{-1, 0, 1} {
+
+		p := NewPitchShift(size).SetPitch(1.5)
+
+		if p.BufferSize() < 1 {
+			t.Errorf("expected NewPitchShift(%d) to clamp its buffer size, got %d", size, p.BufferSize())
+		}
+
+		data := testSine(256, 440, 0.5)
+		p.ApplyEffect(data, len(data))
+
+		for i := 0; i < 256; i++ {
+			if l, r := resound.AudioBuffer(data).Get(i); math.IsNaN(l) || math.IsNaN(r) || math.Abs(l) > 1 || math.Abs(r) > 1 {
+				t.Fatalf("expected NewPitchShift(%d) to output valid audio, got %f, %f", size, l, r)
+			}
+		}
+
+	}
+
+	p := NewPitchShift(1024)
+	p.SetParameters(map[string]float64{"bufferSize": 2048})
+
+	if p.BufferSize() != 2048 || p.Parameters()["bufferSize"] != 2048 {
+		t.Errorf("expected the buffer size to be settable as a parameter, got %d", p.BufferSize())
+	}
+
+}
+
+// testImpulse returns the given number of frames of silence, with a single frame at the given level at the start.
+func testImpulse(frames int, level float64) []byte {
+	data := make([]byte, frames*4)
+	resound.AudioBuffer(data).Set(0, level, level)
+	return data
+}
+
+// TestDelayDefaults pins the output of a Delay with its default settings, where each echo is scaled by the feedback of 0.5.
+func TestDelayDefaults(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	delay := NewDelay().SetWait(0.01) // 441 frames
+
+	data := testImpulse(1024, 0.8)
+	delay.ApplyEffect(data, len(data))
+
+	expected := map[int]float64{0: 0.8, 441: 0.4, 882: 0.2}
+
+	for i := 0; i < 1024; i++ {
+		l, r := resound.AudioBuffer(data).Get(i)
+		if math.Abs(l-expected[i]) > 0.001 || math.Abs(r-expected[i]) > 0.001 {
+			t.Errorf("expected frame %d to be %f, got %f, %f", i, expected[i], l, r)
+		}
+	}
+
+	// Without feedback, a fully wet Delay echoes the original signal once, at full volume, with no dry signal.
+	delay = NewDelay().SetWait(0.01).SetFeedback(0).SetMix(1)
+
+	data = testImpulse(1024, 0.8)
+	delay.ApplyEffect(data, len(data))
+
+	expected = map[int]float64{441: 0.8}
+
+	for i := 0; i < 1024; i++ {
+		if l, _ := resound.AudioBuffer(data).Get(i); math.Abs(l-expected[i]) > 0.001 {
+			t.Errorf("expected the fully wet Delay's frame %d to be %f, got %f", i, expected[i], l)
+		}
+	}
+
+}
+
+// TestDryBlendSkipped checks that effects only store their dry signal when they blend it back in: when they're active and not fully wet.
+func TestDryBlendSkipped(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	tremolo := NewTremolo()
+	limiter := NewLimiter()
+
+	for name, e := range map[string]struct {
+		effect resound.IEffect
+		base   *baseEffect
+	}{
+		"Tremolo": {tremolo, &tremolo.baseEffect},
+		"Limiter": {limiter, &limiter.baseEffect},
+	} {
+
+		for _, c := range []struct {
+			active bool
+			mix    float64
+			stored bool
+		}{{true, 1, false}, {false, 0.5, false}, {true, 0.5, true}} {
+
+			e.base.active = c.active
+			e.base.setMix(c.mix)
+			e.base.dry = e.base.dry[:0]
+
+			data := testSine(256, 440, 0.5)
+			e.effect.ApplyEffect(data, len(data))
+
+			if stored := len(e.base.dry) > 0; stored != c.stored {
+				t.Errorf("%s (active %t, mix %f): expected the dry signal to be stored: %t, got %t", name, c.active, c.mix, c.stored, stored)
+			}
+
+		}
+
+	}
+
+}
+
+// TestBitcrushBitDepth checks that the bit depth is left at 16 unless set, and quantizes without downsampling when it's set on its own.
+func TestBitcrushBitDepth(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	if bits := NewBitcrush().SetStrength(1).BitDepth(); bits != 16 {
+		t.Errorf("expected the bit depth to stay at 16 regardless of the strength, got %d", bits)
+	}
+
+	bitcrush := NewBitcrush().SetDownsample(1).SetBitDepth(3)
+
+	original := testSine(256, 440, 0.9)
+	data := append([]byte{}, original...)
+	bitcrush.ApplyEffect(data, len(data))
+
+	changed := false
+
+	for i := 0; i < 256; i++ {
+
+		l, _ := resound.AudioBuffer(data).Get(i)
+		ol, _ := resound.AudioBuffer(original).Get(i)
+
+		// 3 bits gives 4 levels on either side of 0.
+		if q := l * 4; math.Abs(q-math.Round(q)) > 0.001 {
+			t.Fatalf("expected frame %d to be quantized to 3 bits, got %f", i, l)
+		}
+
+		// Without downsampling, each frame stays within a quantization step of the original.
+		if math.Abs(l-ol) > 0.125+0.001 {
+			t.Fatalf("expected frame %d not to be downsampled, got %f from %f", i, l, ol)
+		}
+
+		changed = changed || l != ol
+
+	}
+
+	if !changed {
+		t.Error("expected the audio to be quantized")
+	}
+
+}
+
+// TestBitcrushDownsample checks that downsampling holds each sample without quantizing it when the bit depth isn't set.
+func TestBitcrushDownsample(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	bitcrush := NewBitcrush().SetDownsample(4)
+
+	original := testSine(256, 440, 0.9)
+	data := append([]byte{}, original...)
+	bitcrush.ApplyEffect(data, len(data))
+
+	for i := 0; i < 256; i++ {
+		l, r := resound.AudioBuffer(data).Get(i)
+		hl, hr := resound.AudioBuffer(original).Get(i / 4 * 4)
+		if l != hl || r != hr {
+			t.Fatalf("expected frame %d to hold frame %d unquantized (%f), got %f", i, i/4*4, hl, l)
+		}
+	}
+
+}
+
+// BenchmarkDelay measures a Delay processing buffers of audio; its delay line is a preallocated ring buffer, so once
+// it's sized, processing shouldn't allocate at all, however long the wait time is.
+func BenchmarkDelay(b *testing.B) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	for _, wait := range []float64{0.1, 1} {
+
+		b.Run(fmt.Sprintf("wait=%gs", wait), func(b *testing.B) {
+
+			delay := NewDelay().SetWait(wait)
+			data := testSine(512, 440, 0.5)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				delay.ApplyEffect(data, len(data))
+			}
+
+		})
+
+	}
+
+}
+
+// TestDelayAllocations checks that a Delay doesn't allocate while processing audio once its delay line has been sized.
+func TestDelayAllocations(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	delay := NewDelay().SetWait(1)
+	data := testSine(512, 440, 0.5)
+
+	// The first buffer sizes the delay line.
+	delay.ApplyEffect(data, len(data))
+
+	if allocs := testing.AllocsPerRun(100, func() { delay.ApplyEffect(data, len(data)) }); allocs != 0 {
+		t.Errorf("expected processing audio through a Delay not to allocate, got %f allocations per buffer", allocs)
+	}
+
+}
+
+// testGain returns the gain a tone of the given frequency and amplitude comes out of the given effect with, measured from the RMS
+// level of the effect's output once the filter has settled. A frequency of 0 is a DC offset at the given amplitude.
+func testGain(effect resound.IEffect, freq, amplitude float64) float64 {
+
+	frames := resound.SampleRate() / 2
+
+	data := make([]byte, frames*4)
+	if freq > 0 {
+		data = testSine(frames, freq, amplitude)
+	} else {
+		for i := 0; i < frames; i++ {
+			resound.AudioBuffer(data).Set(i, amplitude, amplitude)
+		}
+	}
+
+	effect.ApplyEffect(data, len(data))
+
+	input, output := 0.0, 0.0
+	for i := frames / 2; i < frames; i++ {
+		l, _ := resound.AudioBuffer(data).Get(i)
+		output += l * l
+		in := amplitude
+		if freq > 0 {
+			in *= math.Sin(2 * math.Pi * freq * float64(i) / float64(resound.SampleRate()))
+		}
+		input += in * in
+	}
+
+	return math.Sqrt(output / input)
+
+}
+
+func TestLowpassFilter(t *testing.T) {
+
+	tests := []struct {
+		freq     float64
+		min, max float64
+	}{
+		{1000, 0.89, 1.01}, // Within 1 dB
+		{2000, 0.65, 0.76}, // About -3 dB at the cutoff
+		{15000, 0, 0.05},   // At least 26 dB down
+	}
+
+	for _, sampleRate := range []int{44100, 48000} {
+
+		resound.SetDefaultSampleRate(sampleRate)
+
+		for _, test := range tests {
+			if gain := testGain(NewLowpassFilter().SetCutoff(2000), test.freq, 0.5); gain < test.min || gain > test.max {
+				t.Errorf("at %d Hz, expected a %g Hz tone to pass a 2 kHz low-pass filter with a gain from %g to %g, got %f", sampleRate, test.freq, test.min, test.max, gain)
+			}
+		}
+
+	}
+
+	resound.SetDefaultSampleRate(44100)
+
+}
+
+func TestHighpassFilter(t *testing.T) {
+
+	tests := []struct {
+		freq     float64
+		min, max float64
+	}{
+		{0, 0, 0.001},     // DC offset
+		{40, 0, 0.06},     // Low rumble, at least 24 dB down
+		{200, 0.65, 0.76}, // About -3 dB at the cutoff
+		{5000, 0.98, 1.01},
+	}
+
+	for _, sampleRate := range []int{44100, 48000} {
+
+		resound.SetDefaultSampleRate(sampleRate)
+
+		for _, test := range tests {
+			if gain := testGain(NewHighpassFilter().SetCutoff(200), test.freq, 0.5); gain < test.min || gain > test.max {
+				t.Errorf("at %d Hz, expected a %g Hz tone to pass a 200 Hz high-pass filter with a gain from %g to %g, got %f", sampleRate, test.freq, test.min, test.max, gain)
+			}
+		}
+
+	}
+
+	resound.SetDefaultSampleRate(44100)
+
+}
+
+// eofReader is a source that returns all of its data in a single read, along with io.EOF, like some decoders do for their final chunk.
+type eofReader struct {
+	data []byte
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, io.EOF
+}
+
+func (r *eofReader) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+func TestFinalChunkProcessed(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	effects := map[string]resound.IEffect{
+		"Volume":         NewVolume().SetStrength(0.5),
+		"Pan":            NewPan().SetPan(-1),
+		"Delay":          NewDelay().SetWait(0.001),
+		"Distort":        NewDistort().SetDrive(0.8),
+		"LowpassFilter":  NewLowpassFilter().SetCutoff(200),
+		"HighpassFilter": NewHighpassFilter().SetCutoff(2000),
+		"Bitcrush":       NewBitcrush().SetBitDepth(4),
+		"PitchShift":     NewPitchShift(1024).SetPitch(1.5),
+	}
+
+	for name, effect := range effects {
+
+		input := testSine(1024, 440, 0.5)
+
+		expected := append([]byte{}, input...)
+		effect.Clone().ApplyEffect(expected, len(expected))
+
+		if bytes.Equal(expected, input) {
+			t.Fatalf("expected %s to change the test audio", name)
+		}
+
+		effect.SetSource(&eofReader{data: input})
+
+		output := make([]byte, len(input))
+		n, err := effect.Read(output)
+
+		if n != len(input) || err != io.EOF {
+			t.Errorf("expected %s to return the final chunk's %d bytes with io.EOF, got %d, %v", name, len(input), n, err)
+		}
+
+		if !bytes.Equal(output, expected) {
+			t.Errorf("expected %s to be applied to the final chunk of audio", name)
+		}
+
+	}
+
+}
+
+func TestFlangerBounded(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	flanger := NewFlanger().SetFeedback(2).SetRate(5).SetMix(1)
+
+	if flanger.Feedback() >= 1 {
+		t.Fatalf("expected the Flanger's feedback to be clamped below 1, got %f", flanger.Feedback())
+	}
+
+	// A second of a loud tone, followed by a second of silence that the feedback should die away in.
+	data := append(testSine(44100, 220, 1), make([]byte, 44100*4)...)
+	flanger.ApplyEffect(data, len(data))
+
+	for i := 0; i < len(data)/4; i++ {
+		if l, r := resound.AudioBuffer(data).Get(i); math.IsNaN(l) || math.IsNaN(r) || math.Abs(l) > 1 || math.Abs(r) > 1 {
+			t.Fatalf("expected the Flanger's output to stay within range, got %f, %f at frame %d", l, r, i)
+		}
+	}
+
+	for i := 88100; i < 88200; i++ {
+		if l, _ := resound.AudioBuffer(data).Get(i); math.Abs(l) > 0.001 {
+			t.Fatalf("expected the Flanger's feedback to die away, got %f at frame %d", l, i)
+		}
+	}
+
+}
+
+func TestLimiterCeiling(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	for _, ceiling := range []float64{-0.3, -6, -12} {
+
+		limiter := NewLimiter().SetCeiling(ceiling)
+
+		// A sine that's already clipping, followed by a sudden jump from quiet to loud, which the lookahead has to catch.
+		data := append(testSine(4410, 440, 1), testSine(4410, 440, 0.05)...)
+		data = append(data, testSine(4410, 440, 1)...)
+
+		limiter.ApplyEffect(data, len(data))
+
+		max := resound.DBToLinear(ceiling)
+		peak := 0.0
+
+		for i := 0; i < len(data)/4; i++ {
+			l, r := resound.AudioBuffer(data).Get(i)
+			peak = math.Max(peak, math.Max(math.Abs(l), math.Abs(r)))
+		}
+
+		// The tolerance allows for the output being rounded to 16 bits.
+		if peak > max+1.0/math.MaxInt16 {
+			t.Errorf("expected the Limiter's output to stay under its %g dB ceiling (%f), got a peak of %f", ceiling, max, peak)
+		}
+
+		if peak < max*0.8 {
+			t.Errorf("expected the Limiter's output to reach near its %g dB ceiling (%f), got a peak of %f", ceiling, max, peak)
+		}
+
+	}
+
+}
+
+func TestBandpassAndNotchFilters(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	tests := []struct {
+		name     string
+		effect   func() resound.IEffect
+		freq     float64
+		min, max float64
+	}{
+		{"BandpassFilter", func() resound.IEffect { return NewBandpassFilter().SetCenter(1000).SetQ(2) }, 1000, 0.95, 1.01},
+		{"BandpassFilter", func() resound.IEffect { return NewBandpassFilter().SetCenter(1000).SetQ(2) }, 100, 0, 0.1},
+		{"BandpassFilter", func() resound.IEffect { return NewBandpassFilter().SetCenter(1000).SetQ(2) }, 10000, 0, 0.1},
+		{"NotchFilter", func() resound.IEffect { return NewNotchFilter().SetCenter(60).SetQ(2) }, 60, 0, 0.01},
+		{"NotchFilter", func() resound.IEffect { return NewNotchFilter().SetCenter(60).SetQ(2) }, 1000, 0.98, 1.01},
+	}
+
+	for _, test := range tests {
+		if gain := testGain(test.effect(), test.freq, 0.5); gain < test.min || gain > test.max {
+			t.Errorf("expected a %g Hz tone to pass the %s with a gain from %g to %g, got %f", test.freq, test.name, test.min, test.max, gain)
+		}
+	}
+
+}
+
+func TestStereoWidth(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	// A stereo signal with a different tone in each channel.
+	original := make([]byte, 256*4)
+	left, right := testSine(256, 440, 0.4), testSine(256, 660, 0.3)
+	for i := 0; i < 256; i++ {
+		l, _ := resound.AudioBuffer(left).Get(i)
+		r, _ := resound.AudioBuffer(right).Get(i)
+		resound.AudioBuffer(original).Set(i, l, r)
+	}
+
+	mono := append([]byte{}, original...)
+	NewStereoWidth().SetWidth(0).ApplyEffect(mono, len(mono))
+
+	unchanged := append([]byte{}, original...)
+	NewStereoWidth().SetWidth(1).ApplyEffect(unchanged, len(unchanged))
+
+	for i := 0; i < 256; i++ {
+
+		if l, r := resound.AudioBuffer(mono).Get(i); l != r {
+			t.Fatalf("expected a width of 0 to make both channels identical, got %f, %f at frame %d", l, r, i)
+		}
+
+		ol, or := resound.AudioBuffer(original).Get(i)
+		if l, r := resound.AudioBuffer(unchanged).Get(i); math.Abs(l-ol) > 0.0001 || math.Abs(r-or) > 0.0001 {
+			t.Fatalf("expected a width of 1 to leave the audio unchanged, got %f, %f from %f, %f at frame %d", l, r, ol, or, i)
+		}
+
+	}
+
+}
+
+func TestPanLaws(t *testing.T) {
+
+	for pan := -1.0; pan <= 1; pan += 0.05 {
+
+		l, r := panGains(PanLawConstantPower, pan)
+		if power := l*l + r*r; math.Abs(power-1) > 0.0001 {
+			t.Errorf("expected constant-power panning to keep the total power at 1, got %f at a pan of %f", power, pan)
+		}
+
+		// The compromise law sits between the linear and constant-power laws.
+		ll, lr := panGains(PanLawLinear, pan)
+		cl, cr := panGains(PanLawCompromise, pan)
+		if cl < l-0.0001 || cl > ll+0.0001 || cr < r-0.0001 || cr > lr+0.0001 {
+			t.Errorf("expected the compromise law's gains (%f, %f) to be between the constant-power and linear laws at a pan of %f", cl, cr, pan)
+		}
+
+	}
+
+	if l, r := panGains(PanLawLinear, 0); l != 1 || r != 1 {
+		t.Errorf("expected the linear law to leave both channels at full volume in the middle, got %f, %f", l, r)
+	}
+
+	// The Pan effect applies the gains of its pan law.
+	data := testImpulse(1, 0.8)
+	NewPan().SetPanLaw(PanLawConstantPower).ApplyEffect(data, len(data))
+
+	if l, r := resound.AudioBuffer(data).Get(0); math.Abs(l-0.8*math.Sqrt2/2) > 0.001 || math.Abs(r-0.8*math.Sqrt2/2) > 0.001 {
+		t.Errorf("expected a centered constant-power Pan to scale each channel by -3 dB, got %f, %f", l, r)
+	}
+
+}
+
+func TestDelaySampleRate(t *testing.T) {
+
+	// No audio context is created by the tests, so SampleRate() returns the default sample rate, which is restored afterwards.
+	previous := resound.SampleRate()
+	t.Cleanup(func() { resound.SetDefaultSampleRate(previous) })
+
+	for _, sampleRate := range []int{44100, 48000} {
+
+		resound.SetDefaultSampleRate(sampleRate)
+
+		delay := NewDelay().SetWait(0.5)
+
+		data := testImpulse(sampleRate, 0.8)
+		delay.ApplyEffect(data, len(data))
+
+		// The echo lands half a second later at the context's sample rate (24000 frames at 48000 Hz).
+		echo := sampleRate / 2
+
+		for i := 1; i < sampleRate; i++ {
+			l, _ := resound.AudioBuffer(data).Get(i)
+			if i == echo && math.Abs(l-0.4) > 0.001 {
+				t.Errorf("at %d Hz, expected the echo at frame %d, got %f", sampleRate, i, l)
+			} else if i != echo && l != 0 {
+				t.Errorf("at %d Hz, expected silence at frame %d, got %f", sampleRate, i, l)
+				break
+			}
+		}
+
+	}
+
+}
+
+func TestSeekStartClearsBuffers(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	effects := map[string]resound.IEffect{
+		"Delay":             NewDelay().SetWait(0.01),
+		"PitchShift":        NewPitchShift(1024).SetPitch(1.5),
+		"LowpassFilter":     NewLowpassFilter(),
+		"HighpassFilter":    NewHighpassFilter(),
+		"BandpassFilter":    NewBandpassFilter(),
+		"NotchFilter":       NewNotchFilter(),
+		"DCBlocker":         NewDCBlocker(),
+		"EQ":                NewEQ(),
+		"Bitcrush":          NewBitcrush(),
+		"Downsampler":       NewDownsampler(),
+		"Compressor":        NewCompressor(),
+		"Limiter":           NewLimiter(),
+		"AutoWah":           NewAutoWah(),
+		"Flanger":           NewFlanger(),
+		"Vibrato":           NewVibrato(),
+		"Tremolo":           NewTremolo(),
+		"RingMod":           NewRingMod(),
+		"Haas":              NewHaas(),
+		"Pan3D":             NewPan3D(),
+		"Reverb":            NewReverb(),
+		"ConvolutionReverb": NewConvolutionReverb(),
+	}
+
+	for name, effect := range effects {
+		if _, ok := effect.(resound.IResettable); !ok {
+			t.Fatalf("expected %s to implement IResettable", name)
+		}
+	}
+
+	for name, effect := range effects {
+
+		effect.SetSource(bytes.NewReader(testSine(2048, 440, 0.5)))
+
+		// After playing through the audio, seeking back to the start should play it exactly as it did the first time,
+		// without the echoes, filter history, or other state left over from the audio that was played before.
+		first := make([]byte, 2048*4)
+		if _, err := effect.Read(first); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := effect.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+
+		second := make([]byte, 2048*4)
+		if _, err := effect.Read(second); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(first, second) {
+			t.Errorf("expected %s's output after seeking to the start not to be affected by the audio played before", name)
+		}
+
+	}
+
+}
+
+func TestDistortFlattensPeaks(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	for _, mode := range []ClipMode{ClipSoft, ClipHard} {
+
+		data := testSine(4410, 100, 0.5)
+		NewDistort().SetClipMode(mode).SetDrive(1).ApplyEffect(data, len(data))
+
+		// A sine's RMS level is about 0.707 of its peak; flattening its peaks pushes it towards a square wave, where they're equal.
+		peak, _ := resound.AudioBuffer(data).Peak()
+		rms, _ := resound.AudioBuffer(data).RMS()
+
+		if peak < 0.95 || rms/peak < 0.9 {
+			t.Errorf("expected a high drive to flatten the sine's peaks with clip mode %d, got a peak of %f and an RMS of %f", mode, peak, rms)
+		}
+
+	}
+
+	original := testSine(4410, 100, 0.5)
+	data := append([]byte{}, original...)
+	NewDistort().SetDrive(0).ApplyEffect(data, len(data))
+
+	if !bytes.Equal(data, original) {
+		t.Error("expected a drive of 0 to leave the audio unchanged")
+	}
+
+}
+
+func TestHaasOffset(t *testing.T) {
+
+	for _, sampleRate := range []int{44100, 48000} {
+
+		resound.SetDefaultSampleRate(sampleRate)
+
+		for _, side := range []HaasSide{HaasLeft, HaasRight} {
+
+			data := testImpulse(2048, 0.8)
+			NewHaas().SetDelay(20).SetSide(side).ApplyEffect(data, len(data))
+
+			// 20 milliseconds is 882 frames at 44100 Hz, or 960 frames at 48000 Hz.
+			offset := sampleRate / 50
+
+			for i := 0; i < 2048; i++ {
+
+				l, r := resound.AudioBuffer(data).Get(i)
+
+				delayed, direct := r, l
+				if side == HaasLeft {
+					delayed, direct = l, r
+				}
+
+				expectedDirect, expectedDelayed := 0.0, 0.0
+				if i == 0 {
+					expectedDirect = 0.8
+				}
+				if i == offset {
+					expectedDelayed = 0.8
+				}
+
+				if math.Abs(direct-expectedDirect) > 0.001 {
+					t.Fatalf("at %d Hz, expected the undelayed channel to be %f at frame %d, got %f", sampleRate, expectedDirect, i, direct)
+				}
+
+				if math.Abs(delayed-expectedDelayed) > 0.001 {
+					t.Fatalf("at %d Hz, expected the delayed channel (side %d) to be offset by %d frames, got %f at frame %d", sampleRate, side, offset, delayed, i)
+				}
+
+			}
+
+		}
+
+	}
+
+	resound.SetDefaultSampleRate(44100)
+
+}
+
+func TestDCBlocker(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	// A constant offset should die away, with the output's mean trending to zero.
+	data := make([]byte, 44100*4)
+	for i := 0; i < 44100; i++ {
+		resound.AudioBuffer(data).Set(i, 0.5, -0.25)
+	}
+
+	NewDCBlocker().ApplyEffect(data, len(data))
+
+	for _, window := range []struct{ start, end int }{{4410, 4851}, {44100 - 441, 44100}} {
+
+		meanL, meanR := 0.0, 0.0
+		for i := window.start; i < window.end; i++ {
+			l, r := resound.AudioBuffer(data).Get(i)
+			meanL += l / float64(window.end-window.start)
+			meanR += r / float64(window.end-window.start)
+		}
+
+		if math.Abs(meanL) > 0.001 || math.Abs(meanR) > 0.001 {
+			t.Errorf("expected the offset to be removed by frame %d, got a mean of %f, %f", window.start, meanL, meanR)
+		}
+
+	}
+
+	// Audible frequencies pass through untouched.
+	if gain := testGain(NewDCBlocker(), 440, 0.5); math.Abs(gain-1) > 0.01 {
+		t.Errorf("expected a 440 Hz tone to pass through the DCBlocker, got a gain of %f", gain)
+	}
+
+}
+
+// BenchmarkCoefficients measures effects that derive coefficients from their settings, comparing buffers processed with static
+// settings (where the cached coefficients are reused) against buffers where a setting changes each time (so they're recalculated).
+func BenchmarkCoefficients(b *testing.B) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	lowpass := NewLowpassFilter()
+	limiter := NewLimiter()
+	compressor := NewCompressor()
+
+	benchmarks := []struct {
+		name   string
+		effect resound.IEffect
+		change func(i int)
+	}{
+		{"LowpassFilter", lowpass, func(i int) { lowpass.SetCutoff(2000 + float64(i%2)) }},
+		{"Limiter", limiter, func(i int) { limiter.SetLookahead(5 + float64(i%2)*0.001) }}, // Not enough to resize its delay line
+		{"Compressor", compressor, func(i int) { compressor.SetAttack(10 + float64(i%2)) }},
+	}
+
+	for _, bm := range benchmarks {
+
+		for _, static := range []bool{true, false} {
+
+			name := bm.name + "/static"
+			if !static {
+				name = bm.name + "/changing"
+			}
+
+			b.Run(name, func(b *testing.B) {
+
+				// Short buffers make the cost of recalculating the coefficients stand out. The buffer is refilled each time,
+				// as processing it over and over in place would fade it to silence.
+				source := testSine(64, 440, 0.5)
+				data := make([]byte, len(source))
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					if !static {
+						bm.change(i)
+					}
+					copy(data, source)
+					bm.effect.ApplyEffect(data, len(data))
+				}
+
+			})
+
+		}
+
+	}
+
+}
+
+// BenchmarkVolume compares a Volume with a static strength, which scales the whole buffer at once with ScaleStereo(), against one
+// that's ramping its strength, which has to scale each frame with Get() and Set().
+func BenchmarkVolume(b *testing.B) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	for _, ramping := range []bool{false, true} {
+
+		name := "static"
+		if ramping {
+			name = "ramping"
+		}
+
+		b.Run(name, func(b *testing.B) {
+
+			volume := NewVolume().SetStrength(0.5)
+			source := testSine(512, 440, 0.5)
+			data := make([]byte, len(source))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if ramping {
+					// A ramp long enough that it never finishes during the benchmark.
+					volume.SetStrengthSmoothed(float64(i%2), 1000)
+				}
+				copy(data, source)
+				volume.ApplyEffect(data, len(data))
+			}
+
+		})
+
+	}
+
+}
+
+func TestMarshalEffects(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	original := []resound.IEffect{
+		NewDelay().SetWait(0.3).SetFeedback(0.25),
+		NewReverb().SetRoomSize(0.8).SetActive(false),
+		NewCompressor().SetThreshold(-12).SetRatio(8),
+		NewEQ().SetActive(false),
+	}
+
+	data, err := resound.MarshalEffects(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := resound.UnmarshalEffects(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(restored) != len(original) {
+		t.Fatalf("expected %d effects to be restored, got %d", len(original), len(restored))
+	}
+
+	for i := range original {
+
+		if reflect.TypeOf(restored[i]) != reflect.TypeOf(original[i]) {
+			t.Errorf("expected effect %d to be restored as a %T, got a %T", i, original[i], restored[i])
+			continue
+		}
+
+		expected := original[i].(resound.IParameterized).Parameters()
+		if params := restored[i].(resound.IParameterized).Parameters(); !reflect.DeepEqual(params, expected) {
+			t.Errorf("expected the %T's parameters to be restored as %v, got %v", original[i], expected, params)
+		}
+
+	}
+
+	if _, err := resound.UnmarshalEffects([]byte(`[{"type": "Unregistered", "parameters": {}}]`)); err == nil {
+		t.Errorf("expected an error when unmarshaling an effect type that hasn't been registered")
+	}
+
+}
@@ -0,0 +1,220 @@
+package effects
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/solarlune/resound"
+)
+
+// BandKind indicates the kind of filter an EQ band uses.
+type BandKind int
+
+const (
+	BandPeaking   BandKind = iota // Boosts or cuts a range of frequencies around the band's frequency.
+	BandLowShelf                  // Boosts or cuts all frequencies below the band's frequency.
+	BandHighShelf                 // Boosts or cuts all frequencies above the band's frequency.
+)
+
+// EQBand represents a single band of an EQ effect.
+type EQBand struct {
+	Frequency float64  // The center (for peaking bands) or corner (for shelving bands) frequency of the band, in hertz
+	Q         float64  // How narrow the band is; higher values affect a narrower range of frequencies
+	GainDB    float64  // How much the band boosts (positive values) or cuts (negative values) its frequencies, in decibels
+	Kind      BandKind // The kind of filter the band uses
+}
+
+func (band EQBand) biquadType() biquadType {
+	switch band.Kind {
+	case BandLowShelf:
+		return biquadLowShelf
+	case BandHighShelf:
+		return biquadHighShelf
+	}
+	return biquadPeaking
+}
+
+// EQ is a multi-band parametric equalizer, which boosts or cuts specific ranges of frequencies.
+// Each band is a biquad filter calculated from the context's sample rate.
+type EQ struct {
+	baseEffect
+
+	Source io.ReadSeeker
+
+	bands      []EQBand
+	filters    []biquad
+	sampleRate int
+	dirty      bool
+}
+
+// NewEQ creates a new EQ effect with no bands; add bands using AddBand().
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewEQ() *EQ {
+	return &EQ{baseEffect: newBaseEffect()}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (eq *EQ) Clone() resound.IEffect {
+	return &EQ{
+		baseEffect: eq.baseEffect.clone(),
+		Source:     eq.Source,
+		bands:      append([]EQBand{}, eq.bands...),
+		filters:    make([]biquad, len(eq.bands)),
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+// Each band's settings are named with the band's index (e.g. "band0.frequency").
+func (eq *EQ) Parameters() map[string]float64 {
+	params := map[string]float64{
+		"active": boolToFloat(eq.active),
+		"mix":    eq.mix,
+	}
+	for i, band := range eq.bands {
+		prefix := "band" + strconv.Itoa(i) + "."
+		params[prefix+"frequency"] = band.Frequency
+		params[prefix+"q"] = band.Q
+		params[prefix+"gain"] = band.GainDB
+		params[prefix+"kind"] = float64(band.Kind)
+	}
+	return params
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+// Only bands that already exist on the EQ are set.
+func (eq *EQ) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { eq.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { eq.SetMix(x) })
+	for i := range eq.bands {
+		band := eq.bands[i]
+		prefix := "band" + strconv.Itoa(i) + "."
+		setParam(params, prefix+"frequency", func(x float64) { band.Frequency = x })
+		setParam(params, prefix+"q", func(x float64) { band.Q = x })
+		setParam(params, prefix+"gain", func(x float64) { band.GainDB = x })
+		setParam(params, prefix+"kind", func(x float64) { band.Kind = BandKind(x) })
+		eq.SetBand(i, band)
+	}
+}
+
+func (eq *EQ) Read(p []byte) (n int, err error) {
+
+	if n, err = eq.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	eq.ApplyEffect(p, n)
+
+	return
+}
+
+func (eq *EQ) ApplyEffect(p []byte, bytesRead int) {
+
+	if !eq.active || len(eq.bands) == 0 {
+		return
+	}
+
+	if eq.blends() {
+		eq.storeDry(p, bytesRead)
+		defer eq.blendDry(p, bytesRead)
+	}
+
+	// Coefficients are only recalculated when a band changes; the filters' histories are left alone so that changes don't click.
+	if sampleRate := resound.SampleRate(); eq.dirty || sampleRate != eq.sampleRate {
+		eq.sampleRate = sampleRate
+		for i, band := range eq.bands {
+			eq.filters[i].set(band.biquadType(), band.Frequency, band.Q, band.GainDB, sampleRate)
+		}
+		eq.dirty = false
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		for f := range eq.filters {
+			l = eq.filters[f].process(0, l)
+			r = eq.filters[f].process(1, r)
+		}
+
+		audio.Set(i, l, r)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history for every band (see Reset()).
+func (eq *EQ) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		eq.Reset()
+	}
+	if eq.Source == nil {
+		return 0, nil
+	}
+	return eq.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's filter history for every band, as though it had just been created. Its settings are left unchanged.
+func (eq *EQ) Reset() {
+	for i := range eq.filters {
+		eq.filters[i].reset()
+	}
+}
+
+// SetActive sets the effect to be active.
+func (eq *EQ) SetActive(active bool) *EQ {
+	eq.active = active
+	return eq
+}
+
+// Active returns if the effect is active.
+func (eq *EQ) Active() bool {
+	return eq.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (eq *EQ) SetMix(mix float64) *EQ {
+	eq.setMix(mix)
+	return eq
+}
+
+// AddBand adds a band to the EQ with the given frequency (in hertz), Q, gain (in decibels), and kind.
+// Bands are indexed in the order they're added.
+func (eq *EQ) AddBand(freq, q, gainDB float64, kind BandKind) *EQ {
+	eq.bands = append(eq.bands, EQBand{Frequency: freq, Q: q, GainDB: gainDB, Kind: kind})
+	eq.filters = append(eq.filters, biquad{})
+	eq.dirty = true
+	return eq
+}
+
+// SetBand updates the band at the given index. The band's filter history is kept, so bands can be changed while audio is playing without clicking.
+// If the index is out of range, SetBand does nothing.
+func (eq *EQ) SetBand(index int, band EQBand) *EQ {
+	if index < 0 || index >= len(eq.bands) {
+		return eq
+	}
+	eq.bands[index] = band
+	eq.dirty = true
+	return eq
+}
+
+// Band returns the band at the given index. If the index is out of range, an empty EQBand is returned.
+func (eq *EQ) Band(index int) EQBand {
+	if index < 0 || index >= len(eq.bands) {
+		return EQBand{}
+	}
+	return eq.bands[index]
+}
+
+// BandCount returns the number of bands in the EQ.
+func (eq *EQ) BandCount() int {
+	return len(eq.bands)
+}
+
+// SetSource sets the active source for the effect.
+func (eq *EQ) SetSource(source io.ReadSeeker) {
+	eq.Source = source
+}
@@ -0,0 +1,66 @@
+package effects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestEQBands(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	// gain returns the gain a sine of the given frequency comes out of the given EQ with, once its filters have settled.
+	gain := func(eq *EQ, freq float64) float64 {
+
+		frames := 44100 / 2
+		data := make([]byte, frames*4)
+
+		for i := 0; i < frames; i++ {
+			v := 0.1 * math.Sin(2*math.Pi*freq*float64(i)/44100)
+			resound.AudioBuffer(data).Set(i, v, v)
+		}
+
+		eq.ApplyEffect(data, len(data))
+
+		sum := 0.0
+		for i := frames / 2; i < frames; i++ {
+			l, _ := resound.AudioBuffer(data).Get(i)
+			sum += l * l
+		}
+
+		return math.Sqrt(sum/float64(frames/2)) / (0.1 / math.Sqrt2)
+
+	}
+
+	for _, test := range []struct {
+		name     string
+		band     float64
+		freq     float64
+		expected float64
+	}{
+		{"boost at the band", 12, 1000, 12},
+		{"cut at the band", -12, 1000, -12},
+		{"boost away from the band", 12, 100, 0},
+	} {
+
+		eq := NewEQ().AddBand(1000, 1, test.band, BandPeaking)
+
+		if db := 20 * math.Log10(gain(eq, test.freq)); math.Abs(db-test.expected) > 1 {
+			t.Errorf("%s: expected a %.0f hz sine to change by %.0f dB, got %.2f dB", test.name, test.freq, test.expected, db)
+		}
+
+	}
+
+	eq := NewEQ().AddBand(200, 0.7, -12, BandLowShelf).AddBand(5000, 0.7, 6, BandHighShelf)
+
+	if db := 20 * math.Log10(gain(eq, 50)); math.Abs(db+12) > 1 {
+		t.Errorf("expected the low shelf to cut a 50 hz sine by 12 dB, got %.2f dB", db)
+	}
+
+	if db := 20 * math.Log10(gain(eq.Clone().(*EQ), 15000)); math.Abs(db-6) > 1 {
+		t.Errorf("expected the high shelf to boost a 15 khz sine by 6 dB, got %.2f dB", db)
+	}
+
+}
@@ -0,0 +1,203 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+const (
+	flangerMinDelay = 0.001 // The shortest delay (in seconds) the Flanger sweeps to
+	flangerMaxDelay = 0.01  // The longest delay (in seconds) the Flanger sweeps to
+)
+
+// Flanger is an effect that mixes the signal with a very slightly delayed copy of itself, sweeping the delay time
+// back and forth and feeding the delayed signal back into itself for a characteristic "jet-sweep" sound.
+type Flanger struct {
+	baseEffect
+
+	rate     float64
+	depth    float64
+	feedback float64
+	Source   io.ReadSeeker
+
+	phase      float64
+	sampleRate int
+	buffer     circularBuffer
+}
+
+// NewFlanger creates a new Flanger effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewFlanger() *Flanger {
+	return &Flanger{
+		rate:       0.25,
+		depth:      1,
+		feedback:   0.5,
+		baseEffect: baseEffect{active: true, mix: 0.5},
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (flanger *Flanger) Clone() resound.IEffect {
+	return &Flanger{
+		rate:       flanger.rate,
+		depth:      flanger.depth,
+		feedback:   flanger.feedback,
+		baseEffect: flanger.baseEffect.clone(),
+		Source:     flanger.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (flanger *Flanger) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":   boolToFloat(flanger.active),
+		"rate":     flanger.rate,
+		"depth":    flanger.depth,
+		"feedback": flanger.feedback,
+		"mix":      flanger.mix,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (flanger *Flanger) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { flanger.SetActive(x != 0) })
+	setParam(params, "rate", func(x float64) { flanger.SetRate(x) })
+	setParam(params, "depth", func(x float64) { flanger.SetDepth(x) })
+	setParam(params, "feedback", func(x float64) { flanger.SetFeedback(x) })
+	setParam(params, "mix", func(x float64) { flanger.SetMix(x) })
+}
+
+func (flanger *Flanger) Read(p []byte) (n int, err error) {
+
+	if n, err = flanger.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	flanger.ApplyEffect(p, n)
+
+	return
+}
+
+func (flanger *Flanger) ApplyEffect(p []byte, bytesRead int) {
+
+	if !flanger.active {
+		return
+	}
+
+	sampleRate := resound.SampleRate()
+
+	if sampleRate != flanger.sampleRate {
+		flanger.sampleRate = sampleRate
+		flanger.buffer = newCircularBuffer(int(flangerMaxDelay*float64(sampleRate)) + 2)
+	}
+
+	phaseStep := 2 * math.Pi * flanger.rate / float64(sampleRate)
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		// The delay sweeps between the minimum and maximum delay times, with the depth controlling how far it sweeps.
+		sweep := (math.Sin(flanger.phase) + 1) / 2
+		delaySeconds := flangerMinDelay + (flangerMaxDelay-flangerMinDelay)*flanger.depth*sweep
+
+		dl, dr := flanger.buffer.readDelayed(delaySeconds * float64(sampleRate))
+
+		flanger.buffer.write(l+dl*flanger.feedback, r+dr*flanger.feedback)
+
+		audio.Set(i, mix(l, dl, flanger.mix), mix(r, dr, flanger.mix))
+
+		flanger.phase += phaseStep
+		if flanger.phase >= 2*math.Pi {
+			flanger.phase -= 2 * math.Pi
+		}
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's delay line and LFO phase (see Reset()).
+func (flanger *Flanger) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		flanger.Reset()
+	}
+	if flanger.Source == nil {
+		return 0, nil
+	}
+	return flanger.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's delay line and LFO phase, as though it had just been created. Its settings are left unchanged.
+func (flanger *Flanger) Reset() {
+	flanger.buffer.clear()
+	flanger.phase = 0
+}
+
+// SetActive sets the effect to be active.
+func (flanger *Flanger) SetActive(active bool) *Flanger {
+	flanger.active = active
+	return flanger
+}
+
+// Active returns if the effect is active.
+func (flanger *Flanger) Active() bool {
+	return flanger.active
+}
+
+// SetRate sets how quickly the Flanger sweeps back and forth, in hertz (sweeps per second). 0 is the minimum value.
+func (flanger *Flanger) SetRate(rate float64) *Flanger {
+	if rate < 0 {
+		rate = 0
+	}
+	flanger.rate = rate
+	return flanger
+}
+
+// Rate returns how quickly the Flanger sweeps back and forth, in hertz.
+func (flanger *Flanger) Rate() float64 {
+	return flanger.rate
+}
+
+// SetDepth sets how far the Flanger sweeps through its delay range (roughly 1 to 10 milliseconds), ranging from 0 to 1.
+func (flanger *Flanger) SetDepth(depth float64) *Flanger {
+	flanger.depth = clamp(depth, 0, 1)
+	return flanger
+}
+
+// Depth returns how far the Flanger sweeps through its delay range, ranging from 0 to 1.
+func (flanger *Flanger) Depth() float64 {
+	return flanger.depth
+}
+
+// SetFeedback sets how much of the delayed signal is fed back into the Flanger's delay line, which intensifies the effect.
+// The value is clamped from 0 to 0.95 to keep the feedback from running away.
+func (flanger *Flanger) SetFeedback(feedback float64) *Flanger {
+	flanger.feedback = clamp(feedback, 0, 0.95)
+	return flanger
+}
+
+// Feedback returns how much of the delayed signal is fed back into the Flanger's delay line.
+func (flanger *Flanger) Feedback() float64 {
+	return flanger.feedback
+}
+
+// SetMix sets the mix between the original signal and the delayed signal, ranging from 0 (only the original) to 1 (only the delayed signal).
+// The default of 0.5 gives the strongest flanging.
+func (flanger *Flanger) SetMix(mix float64) *Flanger {
+	flanger.mix = clamp(mix, 0, 1)
+	return flanger
+}
+
+// Mix returns the mix between the original signal and the delayed signal.
+func (flanger *Flanger) Mix() float64 {
+	return flanger.mix
+}
+
+// SetSource sets the active source for the effect.
+func (flanger *Flanger) SetSource(source io.ReadSeeker) {
+	flanger.Source = source
+}
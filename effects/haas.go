@@ -0,0 +1,201 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// HaasSide indicates which channel the Haas effect delays.
+type HaasSide int
+
+const (
+	HaasLeft  HaasSide = iota // The left channel is delayed, so the sound seems to come slightly more from the right.
+	HaasRight                 // The right channel is delayed, so the sound seems to come slightly more from the left.
+)
+
+// haasMaxDelay is the longest delay (in milliseconds) the Haas effect allows; longer delays are heard as a distinct echo rather than as width.
+const haasMaxDelay = 40.0
+
+// Haas is an effect that widens audio by delaying one channel slightly (by 5 to 30 milliseconds or so) relative to the other.
+// Because of the Haas (or precedence) effect, the ear hears the two channels as a single, wider sound rather than as an echo.
+// This is a cheap way to give mono sound effects some width without the wash of a reverb. Note that as the channels no
+// longer line up, summing them to mono can make the audio sound hollow.
+type Haas struct {
+	baseEffect
+
+	delay  float64
+	side   HaasSide
+	Source io.ReadSeeker
+
+	buffer     []float64
+	writeIndex int
+}
+
+// NewHaas creates a new Haas effect, delaying the right channel by 15 milliseconds.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewHaas() *Haas {
+	return &Haas{
+		delay:      15,
+		side:       HaasRight,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (haas *Haas) Clone() resound.IEffect {
+	return &Haas{
+		delay:      haas.delay,
+		side:       haas.side,
+		baseEffect: haas.baseEffect.clone(),
+		Source:     haas.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (haas *Haas) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(haas.active),
+		"mix":    haas.mix,
+		"delay":  haas.delay,
+		"side":   float64(haas.side),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (haas *Haas) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { haas.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { haas.SetMix(x) })
+	setParam(params, "delay", func(x float64) { haas.SetDelay(x) })
+	setParam(params, "side", func(x float64) { haas.SetSide(HaasSide(x)) })
+}
+
+func (haas *Haas) Read(p []byte) (n int, err error) {
+
+	if n, err = haas.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	haas.ApplyEffect(p, n)
+
+	return
+}
+
+func (haas *Haas) ApplyEffect(p []byte, bytesRead int) {
+
+	if !haas.active {
+		return
+	}
+
+	if haas.blends() {
+		haas.storeDry(p, bytesRead)
+		defer haas.blendDry(p, bytesRead)
+	}
+
+	sampleRate := resound.SampleRate()
+
+	// The buffer is large enough to hold the longest allowed delay at the current sample rate.
+	if size := int(haasMaxDelay/1000*float64(sampleRate)) + 1; len(haas.buffer) != size {
+		haas.buffer = make([]float64, size)
+		haas.writeIndex = 0
+	}
+
+	delay := int(math.Round(haas.delay / 1000 * float64(sampleRate)))
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		input := r
+		if haas.side == HaasLeft {
+			input = l
+		}
+
+		haas.buffer[haas.writeIndex] = input
+
+		readIndex := haas.writeIndex - delay
+		if readIndex < 0 {
+			readIndex += len(haas.buffer)
+		}
+
+		if haas.side == HaasLeft {
+			l = haas.buffer[readIndex]
+		} else {
+			r = haas.buffer[readIndex]
+		}
+
+		audio.Set(i, l, r)
+
+		haas.writeIndex = (haas.writeIndex + 1) % len(haas.buffer)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's delay line (see Reset()).
+func (haas *Haas) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		haas.Reset()
+	}
+	if haas.Source == nil {
+		return 0, nil
+	}
+	return haas.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's delay line, as though it had just been created. Its settings are left unchanged.
+func (haas *Haas) Reset() {
+	for i := range haas.buffer {
+		haas.buffer[i] = 0
+	}
+	haas.writeIndex = 0
+}
+
+// SetActive sets the effect to be active.
+func (haas *Haas) SetActive(active bool) *Haas {
+	haas.active = active
+	return haas
+}
+
+// Active returns if the effect is active.
+func (haas *Haas) Active() bool {
+	return haas.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (haas *Haas) SetMix(mix float64) *Haas {
+	haas.setMix(mix)
+	return haas
+}
+
+// SetDelay sets how long the delayed channel is delayed by, in milliseconds, ranging from 0 to 40. Delays of around 5 to 30 milliseconds
+// work best; shorter delays sound more like panning, while longer ones start to be heard as an echo. Defaults to 15.
+func (haas *Haas) SetDelay(ms float64) *Haas {
+	haas.delay = clamp(ms, 0, haasMaxDelay)
+	return haas
+}
+
+// Delay returns how long the delayed channel is delayed by, in milliseconds.
+func (haas *Haas) Delay() float64 {
+	return haas.delay
+}
+
+// SetSide sets which channel is delayed. Defaults to HaasRight.
+func (haas *Haas) SetSide(side HaasSide) *Haas {
+	haas.side = side
+	return haas
+}
+
+// Side returns which channel is delayed.
+func (haas *Haas) Side() HaasSide {
+	return haas.side
+}
+
+// SetSource sets the active source for the effect.
+func (haas *Haas) SetSource(source io.ReadSeeker) {
+	haas.Source = source
+}
@@ -0,0 +1,231 @@
+package effects
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/solarlune/resound"
+)
+
+const (
+	limiterRelease   = 50  // How long (in milliseconds) it takes for the Limiter to recover once the audio gets quieter
+	limiterKneeWidth = 0.1 // How far below the ceiling (as a fraction of the ceiling) the Limiter's soft knee starts
+)
+
+// Limiter is an effect that keeps the audio from ever going over a ceiling volume. It looks ahead at the audio coming in
+// (by delaying the output slightly) so it can turn the volume down before a loud sound arrives, rather than after.
+// A Limiter is a good choice for the last effect on a master DSPChannel.
+type Limiter struct {
+	baseEffect
+
+	ceiling   float64
+	lookahead float64
+	Source    io.ReadSeeker
+
+	gain         float64
+	buffer       circularBuffer
+	coefficients envelopeFollower // Only used to calculate and cache the attack and release coefficients
+	ceilingGain  float64          // The ceiling as a linear gain
+}
+
+// NewLimiter creates a new Limiter effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewLimiter() *Limiter {
+	limiter := &Limiter{
+		lookahead:  5,
+		baseEffect: newBaseEffect(),
+		gain:       1,
+		buffer:     newCircularBuffer(0),
+	}
+	limiter.SetCeiling(-0.3)
+	return limiter
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (limiter *Limiter) Clone() resound.IEffect {
+	return &Limiter{
+		ceiling:     limiter.ceiling,
+		ceilingGain: limiter.ceilingGain,
+		lookahead:   limiter.lookahead,
+		baseEffect:  limiter.baseEffect.clone(),
+		Source:      limiter.Source,
+		gain:        1,
+		buffer:      newCircularBuffer(0),
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (limiter *Limiter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":    boolToFloat(limiter.active),
+		"mix":       limiter.mix,
+		"ceiling":   limiter.ceiling,
+		"lookahead": limiter.lookahead,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (limiter *Limiter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { limiter.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { limiter.SetMix(x) })
+	setParam(params, "ceiling", func(x float64) { limiter.SetCeiling(x) })
+	setParam(params, "lookahead", func(x float64) { limiter.SetLookahead(x) })
+}
+
+func (limiter *Limiter) Read(p []byte) (n int, err error) {
+
+	if n, err = limiter.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	limiter.ApplyEffect(p, n)
+
+	return
+}
+
+func (limiter *Limiter) ApplyEffect(p []byte, bytesRead int) {
+
+	if !limiter.active {
+		return
+	}
+
+	if limiter.blends() {
+		limiter.storeDry(p, bytesRead)
+		defer limiter.blendDry(p, bytesRead)
+	}
+
+	sampleRate := resound.SampleRate()
+
+	lookaheadSamples := int(limiter.lookahead / 1000 * float64(sampleRate))
+	if lookaheadSamples < 1 {
+		lookaheadSamples = 1
+	}
+
+	limiter.buffer.resize(lookaheadSamples)
+
+	// The gain drops quickly enough to reach its target within the lookahead time, so it's already turned down by the time a loud sound comes out.
+	limiter.coefficients.setTimes(limiter.lookahead/5, limiterRelease, sampleRate)
+	attack := limiter.coefficients.attack
+	release := limiter.coefficients.release
+
+	ceiling := limiter.ceilingGain
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		target := 1.0
+		if peak := math.Max(math.Abs(l), math.Abs(r)); peak > ceiling {
+			target = ceiling / peak
+		}
+
+		coef := release
+		if target < limiter.gain {
+			coef = attack
+		}
+
+		limiter.gain = target + coef*(limiter.gain-target)
+
+		dl, dr := limiter.buffer.oldest()
+		limiter.buffer.write(l, r)
+
+		audio.Set(i, softLimit(dl*limiter.gain, ceiling), softLimit(dr*limiter.gain, ceiling))
+
+	}
+
+}
+
+// softLimit smoothly bends values that approach the ceiling so they never go over it, rather than hard-clipping them.
+func softLimit(v, ceiling float64) float64 {
+
+	knee := ceiling * (1 - limiterKneeWidth)
+	abs := math.Abs(v)
+
+	if abs <= knee {
+		return v
+	}
+
+	width := ceiling - knee
+	limited := knee + width*math.Tanh((abs-knee)/width)
+
+	return math.Copysign(limited, v)
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's lookahead buffer and gain reduction (see Reset()).
+func (limiter *Limiter) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		limiter.Reset()
+	}
+	if limiter.Source == nil {
+		return 0, nil
+	}
+	return limiter.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's lookahead buffer and gain reduction, as though it had just been created. Its settings are left unchanged.
+func (limiter *Limiter) Reset() {
+	limiter.buffer.clear()
+	limiter.gain = 1
+}
+
+// SetActive sets the effect to be active.
+func (limiter *Limiter) SetActive(active bool) *Limiter {
+	limiter.active = active
+	return limiter
+}
+
+// Active returns if the effect is active.
+func (limiter *Limiter) Active() bool {
+	return limiter.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (limiter *Limiter) SetMix(mix float64) *Limiter {
+	limiter.setMix(mix)
+	return limiter
+}
+
+// SetCeiling sets the maximum level of the audio in decibels relative to full scale (so 0 is the loudest possible level).
+func (limiter *Limiter) SetCeiling(db float64) *Limiter {
+	if db > 0 {
+		db = 0
+	}
+	limiter.ceiling = db
+	limiter.ceilingGain = resound.DBToLinear(db)
+	return limiter
+}
+
+// Ceiling returns the maximum level of the audio in decibels.
+func (limiter *Limiter) Ceiling() float64 {
+	return limiter.ceiling
+}
+
+// SetLookahead sets how far ahead the Limiter looks for loud sounds, in milliseconds. This also delays the audio by the same amount.
+// 0 is the minimum value.
+func (limiter *Limiter) SetLookahead(ms float64) *Limiter {
+	if ms < 0 {
+		ms = 0
+	}
+	limiter.lookahead = ms
+	return limiter
+}
+
+// Lookahead returns how far ahead the Limiter looks for loud sounds, in milliseconds.
+func (limiter *Limiter) Lookahead() float64 {
+	return limiter.lookahead
+}
+
+// Latency returns how long the Limiter delays the audio by, which is its lookahead time.
+func (limiter *Limiter) Latency() time.Duration {
+	return time.Duration(limiter.lookahead * float64(time.Millisecond))
+}
+
+// SetSource sets the active source for the effect.
+func (limiter *Limiter) SetSource(source io.ReadSeeker) {
+	limiter.Source = source
+}
@@ -0,0 +1,166 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// NotchFilter is a filter that removes a narrow band of frequencies around its center frequency, leaving everything else.
+// This is useful for removing a specific unwanted frequency, like electrical hum.
+type NotchFilter struct {
+	baseEffect
+
+	Source io.ReadSeeker
+	center float64
+	q      float64
+
+	filter     biquad
+	sampleRate int
+	dirty      bool
+}
+
+// NewNotchFilter creates a new NotchFilter effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewNotchFilter() *NotchFilter {
+	return &NotchFilter{
+		center:     60,
+		q:          10,
+		baseEffect: newBaseEffect(),
+		dirty:      true,
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (nf *NotchFilter) Clone() resound.IEffect {
+	return &NotchFilter{
+		center:     nf.center,
+		q:          nf.q,
+		Source:     nf.Source,
+		baseEffect: nf.baseEffect.clone(),
+		dirty:      true,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (nf *NotchFilter) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(nf.active),
+		"mix":    nf.mix,
+		"center": nf.center,
+		"q":      nf.q,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (nf *NotchFilter) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { nf.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { nf.SetMix(x) })
+	setParam(params, "center", func(x float64) { nf.SetCenter(x) })
+	setParam(params, "q", func(x float64) { nf.SetQ(x) })
+}
+
+func (nf *NotchFilter) Read(p []byte) (n int, err error) {
+
+	if n, err = nf.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	nf.ApplyEffect(p, n)
+
+	return
+
+}
+
+func (nf *NotchFilter) ApplyEffect(p []byte, bytesRead int) {
+
+	if !nf.active {
+		return
+	}
+
+	if nf.blends() {
+		nf.storeDry(p, bytesRead)
+		defer nf.blendDry(p, bytesRead)
+	}
+
+	if sampleRate := resound.SampleRate(); nf.dirty || sampleRate != nf.sampleRate {
+		nf.sampleRate = sampleRate
+		nf.filter.set(biquadNotch, nf.center, nf.q, 0, sampleRate)
+		nf.dirty = false
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		audio.Set(i, nf.filter.process(0, l), nf.filter.process(1, r))
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's filter history (see Reset()).
+func (nf *NotchFilter) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		nf.Reset()
+	}
+	if nf.Source == nil {
+		return 0, nil
+	}
+	return nf.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's filter history, as though it had just been created. Its settings are left unchanged.
+func (nf *NotchFilter) Reset() {
+	nf.filter.reset()
+}
+
+// SetActive sets the effect to be active.
+func (nf *NotchFilter) SetActive(active bool) *NotchFilter {
+	nf.active = active
+	return nf
+}
+
+// Active returns if the effect is active.
+func (nf *NotchFilter) Active() bool {
+	return nf.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (nf *NotchFilter) SetMix(mix float64) *NotchFilter {
+	nf.setMix(mix)
+	return nf
+}
+
+// SetCenter sets the center frequency of the NotchFilter in hertz.
+func (nf *NotchFilter) SetCenter(hz float64) *NotchFilter {
+	nf.center = clamp(hz, minFilterFrequency, maxFilterFrequency)
+	nf.dirty = true
+	return nf
+}
+
+// Center returns the center frequency of the NotchFilter in hertz.
+func (nf *NotchFilter) Center() float64 {
+	return nf.center
+}
+
+// SetQ sets the Q of the NotchFilter, which controls how wide the band of removed frequencies is; higher values remove a narrower band.
+// The value is clamped from 0.1 to 100.
+func (nf *NotchFilter) SetQ(q float64) *NotchFilter {
+	nf.q = clamp(q, 0.1, 100)
+	nf.dirty = true
+	return nf
+}
+
+// Q returns the Q of the NotchFilter.
+func (nf *NotchFilter) Q() float64 {
+	return nf.q
+}
+
+// SetSource sets the active source for the effect.
+func (nf *NotchFilter) SetSource(source io.ReadSeeker) {
+	nf.Source = source
+}
@@ -0,0 +1,347 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// RolloffMode indicates how a Pan3D effect attenuates a sound as it moves away from the listener.
+type RolloffMode int
+
+const (
+	RolloffLinear      RolloffMode = iota // The volume falls linearly from full at the minimum distance to silence at the maximum distance.
+	RolloffInverse                        // The volume falls off inversely with distance, like sound does in the real world.
+	RolloffExponential                    // The volume falls off exponentially with distance, dropping more sharply than inverse rolloff.
+)
+
+// Pan3D is an effect that pans and attenuates a sound based on the position of the sound relative to a listener in 3D space.
+// The listener faces along its forward vector (defaulting to (0, 0, -1)), with (0, 1, 0) being up, so positive X is to the listener's right by default.
+// For 2D games, you can leave the Z coordinates at 0 and just set the X and Y positions.
+type Pan3D struct {
+	baseEffect
+
+	listener        [3]float64
+	listenerForward [3]float64
+	position        [3]float64
+	minDistance     float64
+	maxDistance     float64
+	rolloff         RolloffMode
+	rolloffFactor   float64
+	law             PanLaw
+	Source          io.ReadSeeker
+
+	prevGains [2]float64
+	started   bool
+}
+
+// NewPan3D creates a new Pan3D effect. The sound and listener both start at the origin.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewPan3D() *Pan3D {
+	return &Pan3D{
+		listenerForward: [3]float64{0, 0, -1},
+		minDistance:     1,
+		maxDistance:     100,
+		rolloff:         RolloffInverse,
+		rolloffFactor:   1,
+		baseEffect:      newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (pan *Pan3D) Clone() resound.IEffect {
+	return &Pan3D{
+		listener:        pan.listener,
+		listenerForward: pan.listenerForward,
+		position:        pan.position,
+		minDistance:     pan.minDistance,
+		maxDistance:     pan.maxDistance,
+		rolloff:         pan.rolloff,
+		rolloffFactor:   pan.rolloffFactor,
+		law:             pan.law,
+		baseEffect:      pan.baseEffect.clone(),
+		Source:          pan.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (pan *Pan3D) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":        boolToFloat(pan.active),
+		"mix":           pan.mix,
+		"listenerX":     pan.listener[0],
+		"listenerY":     pan.listener[1],
+		"listenerZ":     pan.listener[2],
+		"forwardX":      pan.listenerForward[0],
+		"forwardY":      pan.listenerForward[1],
+		"forwardZ":      pan.listenerForward[2],
+		"x":             pan.position[0],
+		"y":             pan.position[1],
+		"z":             pan.position[2],
+		"minDistance":   pan.minDistance,
+		"maxDistance":   pan.maxDistance,
+		"rolloff":       float64(pan.rolloff),
+		"rolloffFactor": pan.rolloffFactor,
+		"law":           float64(pan.law),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (pan *Pan3D) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { pan.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { pan.SetMix(x) })
+	setParam(params, "listenerX", func(x float64) { pan.listener[0] = x })
+	setParam(params, "listenerY", func(x float64) { pan.listener[1] = x })
+	setParam(params, "listenerZ", func(x float64) { pan.listener[2] = x })
+	setParam(params, "forwardX", func(x float64) { pan.listenerForward[0] = x })
+	setParam(params, "forwardY", func(x float64) { pan.listenerForward[1] = x })
+	setParam(params, "forwardZ", func(x float64) { pan.listenerForward[2] = x })
+	setParam(params, "x", func(x float64) { pan.position[0] = x })
+	setParam(params, "y", func(x float64) { pan.position[1] = x })
+	setParam(params, "z", func(x float64) { pan.position[2] = x })
+	setParam(params, "minDistance", func(x float64) { pan.SetMinDistance(x) })
+	setParam(params, "maxDistance", func(x float64) { pan.SetMaxDistance(x) })
+	setParam(params, "rolloff", func(x float64) { pan.SetRolloff(RolloffMode(x)) })
+	setParam(params, "rolloffFactor", func(x float64) { pan.SetRolloffFactor(x) })
+	setParam(params, "law", func(x float64) { pan.SetPanLaw(PanLaw(x)) })
+}
+
+func (pan *Pan3D) Read(p []byte) (n int, err error) {
+
+	if n, err = pan.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	pan.ApplyEffect(p, n)
+
+	return
+}
+
+func (pan *Pan3D) ApplyEffect(p []byte, bytesRead int) {
+
+	if !pan.active {
+		return
+	}
+
+	if pan.blends() {
+		pan.storeDry(p, bytesRead)
+		defer pan.blendDry(p, bytesRead)
+	}
+
+	panValue, attenuation := pan.PanAndAttenuation()
+
+	ls, rs := panGains(pan.law, panValue)
+	ls *= attenuation
+	rs *= attenuation
+
+	if !pan.started {
+		pan.prevGains = [2]float64{ls, rs}
+		pan.started = true
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	frames := bytesRead / 4
+
+	// The gains are ramped across the buffer from where they were last buffer to avoid clicks when the sound or listener moves.
+	for i := 0; i < frames; i++ {
+
+		t := float64(i+1) / float64(frames)
+
+		l, r := audio.Get(i)
+
+		l *= mix(pan.prevGains[0], ls, t)
+		r *= mix(pan.prevGains[1], rs, t)
+
+		audio.Set(i, l, r)
+
+	}
+
+	pan.prevGains = [2]float64{ls, rs}
+
+}
+
+// PanAndAttenuation returns the pan value (ranging from -1 for hard left to 1 for hard right) and the volume multiplier
+// (ranging from 0 to 1) for the sound given its current position relative to the listener.
+func (pan *Pan3D) PanAndAttenuation() (panValue float64, attenuation float64) {
+
+	dx := pan.position[0] - pan.listener[0]
+	dy := pan.position[1] - pan.listener[1]
+	dz := pan.position[2] - pan.listener[2]
+
+	distance := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+	// The listener's right vector is the cross product of its forward vector and the up vector, (0, 1, 0).
+	fx, fz := pan.listenerForward[0], pan.listenerForward[2]
+	rx, rz := -fz, fx
+
+	if rightLength := math.Sqrt(rx*rx + rz*rz); distance > 0 && rightLength > 0 {
+		panValue = clamp((dx*rx+dz*rz)/rightLength/distance, -1, 1)
+	}
+
+	return panValue, pan.attenuation(distance)
+
+}
+
+func (pan *Pan3D) attenuation(distance float64) float64 {
+
+	minDist := pan.minDistance
+	maxDist := math.Max(pan.maxDistance, minDist)
+
+	if distance <= minDist {
+		return 1
+	}
+
+	switch pan.rolloff {
+
+	case RolloffLinear:
+		if maxDist == minDist {
+			return 0
+		}
+		return clamp(1-(distance-minDist)/(maxDist-minDist), 0, 1)
+
+	case RolloffExponential:
+		distance = math.Min(distance, maxDist)
+		if minDist <= 0 {
+			return 0
+		}
+		return math.Pow(distance/minDist, -pan.rolloffFactor)
+
+	}
+
+	// Inverse rolloff stops attenuating past the maximum distance, like OpenAL's clamped inverse distance model.
+	distance = math.Min(distance, maxDist)
+	return minDist / (minDist + pan.rolloffFactor*(distance-minDist))
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's gain ramp (see Reset()).
+func (pan *Pan3D) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		pan.Reset()
+	}
+	if pan.Source == nil {
+		return 0, nil
+	}
+	return pan.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's gain ramp, so the next buffer starts at the current position's gains, as though it had just been created. Its settings are left unchanged.
+func (pan *Pan3D) Reset() {
+	pan.started = false
+}
+
+// SetActive sets the effect to be active.
+func (pan *Pan3D) SetActive(active bool) *Pan3D {
+	pan.active = active
+	return pan
+}
+
+// Active returns if the effect is active.
+func (pan *Pan3D) Active() bool {
+	return pan.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (pan *Pan3D) SetMix(mix float64) *Pan3D {
+	pan.setMix(mix)
+	return pan
+}
+
+// SetListener sets the position of the listener.
+func (pan *Pan3D) SetListener(x, y, z float64) *Pan3D {
+	pan.listener = [3]float64{x, y, z}
+	return pan
+}
+
+// Listener returns the position of the listener.
+func (pan *Pan3D) Listener() (x, y, z float64) {
+	return pan.listener[0], pan.listener[1], pan.listener[2]
+}
+
+// SetListenerForward sets the direction the listener is facing. Only the horizontal (X and Z) components
+// affect panning, as the listener is assumed to be upright. Defaults to (0, 0, -1).
+func (pan *Pan3D) SetListenerForward(x, y, z float64) *Pan3D {
+	pan.listenerForward = [3]float64{x, y, z}
+	return pan
+}
+
+// ListenerForward returns the direction the listener is facing.
+func (pan *Pan3D) ListenerForward() (x, y, z float64) {
+	return pan.listenerForward[0], pan.listenerForward[1], pan.listenerForward[2]
+}
+
+// SetSourcePosition sets the position of the sound.
+func (pan *Pan3D) SetSourcePosition(x, y, z float64) *Pan3D {
+	pan.position = [3]float64{x, y, z}
+	return pan
+}
+
+// SourcePosition returns the position of the sound.
+func (pan *Pan3D) SourcePosition() (x, y, z float64) {
+	return pan.position[0], pan.position[1], pan.position[2]
+}
+
+// SetMinDistance sets the distance within which the sound plays at full volume. Defaults to 1. 0 is the minimum value.
+func (pan *Pan3D) SetMinDistance(distance float64) *Pan3D {
+	pan.minDistance = math.Max(distance, 0)
+	return pan
+}
+
+// MinDistance returns the distance within which the sound plays at full volume.
+func (pan *Pan3D) MinDistance() float64 {
+	return pan.minDistance
+}
+
+// SetMaxDistance sets the distance past which the sound stops being attenuated any further; for RolloffLinear,
+// this is the distance at which the sound becomes silent. Defaults to 100. 0 is the minimum value.
+func (pan *Pan3D) SetMaxDistance(distance float64) *Pan3D {
+	pan.maxDistance = math.Max(distance, 0)
+	return pan
+}
+
+// MaxDistance returns the distance past which the sound stops being attenuated any further.
+func (pan *Pan3D) MaxDistance() float64 {
+	return pan.maxDistance
+}
+
+// SetRolloff sets how the sound is attenuated as it moves away from the listener. Defaults to RolloffInverse.
+func (pan *Pan3D) SetRolloff(rolloff RolloffMode) *Pan3D {
+	pan.rolloff = rolloff
+	return pan
+}
+
+// Rolloff returns how the sound is attenuated as it moves away from the listener.
+func (pan *Pan3D) Rolloff() RolloffMode {
+	return pan.rolloff
+}
+
+// SetRolloffFactor sets how quickly the sound is attenuated for RolloffInverse and RolloffExponential.
+// Defaults to 1. 0 is the minimum value.
+func (pan *Pan3D) SetRolloffFactor(factor float64) *Pan3D {
+	pan.rolloffFactor = math.Max(factor, 0)
+	return pan
+}
+
+// RolloffFactor returns how quickly the sound is attenuated for RolloffInverse and RolloffExponential.
+func (pan *Pan3D) RolloffFactor() float64 {
+	return pan.rolloffFactor
+}
+
+// SetPanLaw sets the pan law used to scale the volume of each channel. Defaults to PanLawLinear.
+func (pan *Pan3D) SetPanLaw(law PanLaw) *Pan3D {
+	pan.law = law
+	return pan
+}
+
+// PanLaw returns the pan law used to scale the volume of each channel.
+func (pan *Pan3D) PanLaw() PanLaw {
+	return pan.law
+}
+
+// SetSource sets the active source for the effect.
+func (pan *Pan3D) SetSource(source io.ReadSeeker) {
+	pan.Source = source
+}
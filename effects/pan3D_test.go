@@ -0,0 +1,45 @@
+package effects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestPan3DPosition(t *testing.T) {
+
+	// The listener faces along -Z by default, so a sound along +X is to its right.
+	pan := NewPan3D().SetSourcePosition(10, 0, 0)
+
+	panValue, attenuation := pan.PanAndAttenuation()
+
+	if panValue != 1 {
+		t.Errorf("expected a sound to the listener's right to be panned hard right, got %f", panValue)
+	}
+
+	// Inverse rolloff from a minimum distance of 1: 1 / (1 + (10 - 1)).
+	if math.Abs(attenuation-0.1) > 0.0001 {
+		t.Errorf("expected a sound 10 units away to be attenuated to 0.1, got %f", attenuation)
+	}
+
+	data := make([]byte, 256*4)
+	for i := 0; i < 256; i++ {
+		resound.AudioBuffer(data).Set(i, 0.5, 0.5)
+	}
+
+	pan.ApplyEffect(data, len(data))
+
+	if l, r := resound.AudioBuffer(data).Get(255); math.Abs(l) > 0.001 || math.Abs(r-0.05) > 0.001 {
+		t.Errorf("expected the sound to only play on the right at a tenth of its volume, got %f, %f", l, r)
+	}
+
+	if panValue, _ := pan.SetListenerForward(0, 0, 1).PanAndAttenuation(); panValue != -1 {
+		t.Errorf("expected the sound to move to the left when the listener turns around, got %f", panValue)
+	}
+
+	if _, attenuation := pan.SetSourcePosition(0.5, 0, 0).PanAndAttenuation(); attenuation != 1 {
+		t.Errorf("expected a sound within the minimum distance not to be attenuated, got %f", attenuation)
+	}
+
+}
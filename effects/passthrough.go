@@ -0,0 +1,80 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// Passthrough is an effect that does nothing to the audio that plays through it.
+// It's useful as a placeholder to reserve a position in a chain of effects that can be swapped out or configured later.
+type Passthrough struct {
+	baseEffect
+
+	Source io.ReadSeeker
+}
+
+// NewPassthrough creates a new Passthrough effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewPassthrough() *Passthrough {
+	return &Passthrough{baseEffect: newBaseEffect()}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (pass *Passthrough) Clone() resound.IEffect {
+	return &Passthrough{
+		baseEffect: pass.baseEffect.clone(),
+		Source:     pass.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (pass *Passthrough) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(pass.active),
+		"mix":    pass.mix,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (pass *Passthrough) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { pass.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { pass.SetMix(x) })
+}
+
+func (pass *Passthrough) Read(p []byte) (n int, err error) {
+	return pass.Source.Read(p)
+}
+
+// ApplyEffect does nothing, as the Passthrough effect leaves the audio as it is.
+func (pass *Passthrough) ApplyEffect(p []byte, bytesRead int) {}
+
+func (pass *Passthrough) Seek(offset int64, whence int) (int64, error) {
+	if pass.Source == nil {
+		return 0, nil
+	}
+	return pass.Source.Seek(offset, whence)
+}
+
+// SetActive sets the effect to be active.
+func (pass *Passthrough) SetActive(active bool) *Passthrough {
+	pass.active = active
+	return pass
+}
+
+// Active returns if the effect is active.
+func (pass *Passthrough) Active() bool {
+	return pass.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (pass *Passthrough) SetMix(mix float64) *Passthrough {
+	pass.setMix(mix)
+	return pass
+}
+
+// SetSource sets the active source for the effect.
+func (pass *Passthrough) SetSource(source io.ReadSeeker) {
+	pass.Source = source
+}
@@ -0,0 +1,105 @@
+package effects
+
+import "sort"
+
+// Preset is a named bundle of settings for an effect, given as parameters (see resound.IParameterized). Applying a Preset only changes
+// the settings it includes, so presets can be layered or tweaked afterwards. Presets can also be applied to any effect with SetParameters().
+type Preset map[string]float64
+
+// Reverb presets.
+var (
+	ReverbSmallRoom = Preset{"roomSize": 0.3, "damping": 0.6, "wet": 0.25, "dry": 1}
+	ReverbHall      = Preset{"roomSize": 0.75, "damping": 0.4, "wet": 0.35, "dry": 0.9}
+	ReverbCathedral = Preset{"roomSize": 0.95, "damping": 0.2, "wet": 0.5, "dry": 0.8}
+)
+
+// Delay presets. The first echo's level is the wet level, and each echo after it is scaled by the feedback.
+var (
+	DelaySlapback = Preset{"wait": 0.08, "wet": 0.6, "dry": 1, "feedback": 0.2}
+	DelayEcho     = Preset{"wait": 0.35, "wet": 0.5, "dry": 1, "feedback": 0.45}
+	DelayCanyon   = Preset{"wait": 0.6, "wet": 0.6, "dry": 1, "feedback": 0.65}
+)
+
+// BandpassFilter presets.
+var (
+	BandpassTelephone = Preset{"center": 1700, "q": 1.2}
+	BandpassRadio     = Preset{"center": 1200, "q": 0.7}
+	BandpassMegaphone = Preset{"center": 2000, "q": 2}
+)
+
+// Compressor presets.
+var (
+	CompressorGentle   = Preset{"threshold": -18, "ratio": 2, "attack": 20, "release": 200, "makeup": 2}
+	CompressorVocal    = Preset{"threshold": -20, "ratio": 4, "attack": 5, "release": 100, "makeup": 4}
+	CompressorSquashed = Preset{"threshold": -30, "ratio": 10, "attack": 1, "release": 50, "makeup": 8}
+)
+
+// presets maps the names of effect types to their named presets.
+var presets = map[string]map[string]Preset{
+	"Reverb": {
+		"SmallRoom": ReverbSmallRoom,
+		"Hall":      ReverbHall,
+		"Cathedral": ReverbCathedral,
+	},
+	"Delay": {
+		"Slapback": DelaySlapback,
+		"Echo":     DelayEcho,
+		"Canyon":   DelayCanyon,
+	},
+	"BandpassFilter": {
+		"Telephone": BandpassTelephone,
+		"Radio":     BandpassRadio,
+		"Megaphone": BandpassMegaphone,
+	},
+	"Compressor": {
+		"Gentle":   CompressorGentle,
+		"Vocal":    CompressorVocal,
+		"Squashed": CompressorSquashed,
+	},
+}
+
+// Presets returns the names of the built-in presets available for each effect type, keyed by the effect's type name
+// (the same name it's registered under for resound.MarshalEffects(), e.g. "Reverb"). The names are sorted alphabetically.
+func Presets() map[string][]string {
+	out := map[string][]string{}
+	for effect, named := range presets {
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out[effect] = names
+	}
+	return out
+}
+
+// PresetByName returns the built-in preset with the given name for the given effect type (e.g. PresetByName("Reverb", "Hall")),
+// and whether it exists. This is useful for choosing presets from data files or menus.
+func PresetByName(effect, name string) (Preset, bool) {
+	preset, ok := presets[effect][name]
+	return preset, ok
+}
+
+// ApplyPreset applies the settings of the given preset to the Reverb (e.g. ReverbHall).
+func (reverb *Reverb) ApplyPreset(preset Preset) *Reverb {
+	reverb.SetParameters(preset)
+	return reverb
+}
+
+// ApplyPreset applies the settings of the given preset to the Delay (e.g. DelayEcho).
+func (delay *Delay) ApplyPreset(preset Preset) *Delay {
+	delay.SetParameters(preset)
+	return delay
+}
+
+// ApplyPreset applies the settings of the given preset to the BandpassFilter (e.g. BandpassTelephone).
+func (bpf *BandpassFilter) ApplyPreset(preset Preset) *BandpassFilter {
+	bpf.SetParameters(preset)
+	return bpf
+}
+
+// ApplyPreset applies the settings of the given preset to the Compressor (e.g. CompressorVocal).
+func (c *Compressor) ApplyPreset(preset Preset) *Compressor {
+	c.SetParameters(preset)
+	return c
+}
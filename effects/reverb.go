@@ -0,0 +1,301 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// The delay line lengths (in samples at 44100hz) for the Reverb effect's comb and allpass filters. These are the
+// tunings used in Freeverb; they're scaled to the current sample rate when the Reverb's buffers are created.
+var (
+	reverbCombTunings    = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpassTunings = []int{556, 441, 341, 225}
+)
+
+const (
+	reverbStereoSpread = 23    // How many more samples the right channel's delay lines are than the left's, to widen the stereo image
+	reverbInputGain    = 0.015 // The gain of the signal going into the comb filters, to keep the summed output from clipping
+	reverbWetScale     = 3.0
+)
+
+// reverbComb is a feedback comb filter with a low-pass filter in its feedback loop for damping.
+type reverbComb struct {
+	buffer      []float64
+	index       int
+	filterStore float64
+}
+
+func (c *reverbComb) process(input, feedback, damp float64) float64 {
+	output := c.buffer[c.index]
+	c.filterStore = output*(1-damp) + c.filterStore*damp
+	c.buffer[c.index] = input + c.filterStore*feedback
+	c.index++
+	if c.index >= len(c.buffer) {
+		c.index = 0
+	}
+	return output
+}
+
+// reverbAllpass is an allpass filter used to diffuse the echoes produced by the comb filters.
+type reverbAllpass struct {
+	buffer []float64
+	index  int
+}
+
+func (a *reverbAllpass) process(input float64) float64 {
+	buffered := a.buffer[a.index]
+	output := buffered - input
+	a.buffer[a.index] = input + buffered*0.5
+	a.index++
+	if a.index >= len(a.buffer) {
+		a.index = 0
+	}
+	return output
+}
+
+// Reverb is an effect that simulates the reflections of sound in a space, like a room or a hall.
+// It's built on parallel comb filters feeding into a series of allpass filters, like the Schroeder / Freeverb reverb designs.
+type Reverb struct {
+	baseEffect
+
+	roomSize float64
+	damping  float64
+	wet      float64
+	dryLevel float64
+	Source   io.ReadSeeker
+
+	sampleRate int
+	combs      [2][]reverbComb
+	allpasses  [2][]reverbAllpass
+}
+
+// NewReverb creates a new Reverb effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewReverb() *Reverb {
+	return &Reverb{
+		roomSize:   0.5,
+		damping:    0.5,
+		wet:        0.33,
+		dryLevel:   1,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (reverb *Reverb) Clone() resound.IEffect {
+	return &Reverb{
+		roomSize:   reverb.roomSize,
+		damping:    reverb.damping,
+		wet:        reverb.wet,
+		dryLevel:   reverb.dryLevel,
+		baseEffect: reverb.baseEffect.clone(),
+		Source:     reverb.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (reverb *Reverb) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":   boolToFloat(reverb.active),
+		"mix":      reverb.mix,
+		"roomSize": reverb.roomSize,
+		"damping":  reverb.damping,
+		"wet":      reverb.wet,
+		"dry":      reverb.dryLevel,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (reverb *Reverb) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { reverb.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { reverb.SetMix(x) })
+	setParam(params, "roomSize", func(x float64) { reverb.SetRoomSize(x) })
+	setParam(params, "damping", func(x float64) { reverb.SetDamping(x) })
+	setParam(params, "wet", func(x float64) { reverb.SetWet(x) })
+	setParam(params, "dry", func(x float64) { reverb.SetDry(x) })
+}
+
+func (reverb *Reverb) Read(p []byte) (n int, err error) {
+
+	if n, err = reverb.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	reverb.ApplyEffect(p, n)
+
+	return
+}
+
+// createBuffers creates the delay lines for the Reverb's filters, sized for the given sample rate.
+func (reverb *Reverb) createBuffers(sampleRate int) {
+
+	reverb.sampleRate = sampleRate
+	scale := float64(sampleRate) / 44100
+
+	for channel := 0; channel < 2; channel++ {
+
+		spread := reverbStereoSpread * channel
+
+		reverb.combs[channel] = make([]reverbComb, len(reverbCombTunings))
+		for i, tuning := range reverbCombTunings {
+			reverb.combs[channel][i].buffer = make([]float64, int(float64(tuning+spread)*scale))
+		}
+
+		reverb.allpasses[channel] = make([]reverbAllpass, len(reverbAllpassTunings))
+		for i, tuning := range reverbAllpassTunings {
+			reverb.allpasses[channel][i].buffer = make([]float64, int(float64(tuning+spread)*scale))
+		}
+
+	}
+
+}
+
+func (reverb *Reverb) ApplyEffect(p []byte, bytesRead int) {
+
+	if !reverb.active {
+		return
+	}
+
+	if reverb.blends() {
+		reverb.storeDry(p, bytesRead)
+		defer reverb.blendDry(p, bytesRead)
+	}
+
+	if sampleRate := resound.SampleRate(); sampleRate != reverb.sampleRate {
+		reverb.createBuffers(sampleRate)
+	}
+
+	// The room size controls how long the echoes ring out for, while the damping controls how quickly high frequencies die off.
+	feedback := reverb.roomSize*0.28 + 0.7
+	damp := reverb.damping * 0.4
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		input := (l + r) * reverbInputGain
+
+		var out [2]float64
+
+		for channel := 0; channel < 2; channel++ {
+
+			for c := range reverb.combs[channel] {
+				out[channel] += reverb.combs[channel][c].process(input, feedback, damp)
+			}
+
+			for a := range reverb.allpasses[channel] {
+				out[channel] = reverb.allpasses[channel][a].process(out[channel])
+			}
+
+		}
+
+		wet := reverb.wet * reverbWetScale
+
+		audio.Set(i, l*reverb.dryLevel+out[0]*wet, r*reverb.dryLevel+out[1]*wet)
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's comb and allpass filter buffers (see Reset()).
+func (reverb *Reverb) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		reverb.Reset()
+	}
+	if reverb.Source == nil {
+		return 0, nil
+	}
+	return reverb.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's comb and allpass filter buffers, silencing any reverb tail, as though it had just been created. Its settings are left unchanged.
+func (reverb *Reverb) Reset() {
+	for ch := range reverb.combs {
+		for i := range reverb.combs[ch] {
+			comb := &reverb.combs[ch][i]
+			for j := range comb.buffer {
+				comb.buffer[j] = 0
+			}
+			comb.index = 0
+			comb.filterStore = 0
+		}
+		for i := range reverb.allpasses[ch] {
+			allpass := &reverb.allpasses[ch][i]
+			for j := range allpass.buffer {
+				allpass.buffer[j] = 0
+			}
+			allpass.index = 0
+		}
+	}
+}
+
+// SetActive sets the effect to be active.
+func (reverb *Reverb) SetActive(active bool) *Reverb {
+	reverb.active = active
+	return reverb
+}
+
+// Active returns if the effect is active.
+func (reverb *Reverb) Active() bool {
+	return reverb.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (reverb *Reverb) SetMix(mix float64) *Reverb {
+	reverb.setMix(mix)
+	return reverb
+}
+
+// SetRoomSize sets the size of the simulated room, ranging from 0 (a small room) to 1 (a huge hall).
+// Larger rooms make echoes ring out for longer.
+func (reverb *Reverb) SetRoomSize(roomSize float64) *Reverb {
+	reverb.roomSize = clamp(roomSize, 0, 1)
+	return reverb
+}
+
+// RoomSize returns the size of the simulated room, ranging from 0 to 1.
+func (reverb *Reverb) RoomSize() float64 {
+	return reverb.roomSize
+}
+
+// SetDamping sets how much high frequencies are absorbed by the simulated room, ranging from 0 (bright) to 1 (muffled).
+func (reverb *Reverb) SetDamping(damping float64) *Reverb {
+	reverb.damping = clamp(damping, 0, 1)
+	return reverb
+}
+
+// Damping returns how much high frequencies are absorbed by the simulated room, ranging from 0 to 1.
+func (reverb *Reverb) Damping() float64 {
+	return reverb.damping
+}
+
+// SetWet sets the volume of the reverberated signal, ranging from 0 to 1.
+func (reverb *Reverb) SetWet(wet float64) *Reverb {
+	reverb.wet = clamp(wet, 0, 1)
+	return reverb
+}
+
+// Wet returns the volume of the reverberated signal.
+func (reverb *Reverb) Wet() float64 {
+	return reverb.wet
+}
+
+// SetDry sets the volume of the original, unaltered signal, ranging from 0 to 1.
+func (reverb *Reverb) SetDry(dry float64) *Reverb {
+	reverb.dryLevel = clamp(dry, 0, 1)
+	return reverb
+}
+
+// Dry returns the volume of the original, unaltered signal.
+func (reverb *Reverb) Dry() float64 {
+	return reverb.dryLevel
+}
+
+// SetSource sets the active source for the effect.
+func (reverb *Reverb) SetSource(source io.ReadSeeker) {
+	reverb.Source = source
+}
@@ -0,0 +1,57 @@
+package effects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestReverbTail(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	frames := 44100 / 2
+
+	data := make([]byte, frames*4)
+	resound.AudioBuffer(data).Set(0, 0.8, 0.8)
+
+	NewReverb().ApplyEffect(data, len(data))
+
+	// level returns the RMS level of the left channel between the given times, in seconds.
+	level := func(from, to float64) float64 {
+		sum := 0.0
+		start, end := int(from*44100), int(to*44100)
+		for i := start; i < end; i++ {
+			l, _ := resound.AudioBuffer(data).Get(i)
+			sum += l * l
+		}
+		return math.Sqrt(sum / float64(end-start))
+	}
+
+	early, late := level(0.05, 0.15), level(0.35, 0.45)
+
+	if early < 0.001 {
+		t.Errorf("expected an impulse to leave a reverb tail, got a level of %f", early)
+	}
+
+	if late >= early {
+		t.Errorf("expected the reverb tail to die away, got a level of %f after %f", late, early)
+	}
+
+	data = make([]byte, frames*4)
+	resound.AudioBuffer(data).Set(0, 0.8, 0.8)
+
+	NewReverb().SetWet(0).ApplyEffect(data, len(data))
+
+	if l, r := resound.AudioBuffer(data).Get(0); math.Abs(l-0.8) > 0.001 || math.Abs(r-0.8) > 0.001 {
+		t.Errorf("expected a Reverb with no wet signal to pass the impulse through, got %f, %f", l, r)
+	}
+
+	for i := 1; i < frames; i++ {
+		if l, r := resound.AudioBuffer(data).Get(i); l != 0 || r != 0 {
+			t.Fatalf("expected a Reverb with no wet signal to add no tail, got %f, %f at frame %d", l, r, i)
+		}
+	}
+
+}
@@ -0,0 +1,167 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// RingMod is a ring modulator effect, which multiplies the audio by a carrier wave. This produces metallic, bell-like,
+// or robotic tones, as each frequency in the audio is replaced by the sum and difference of itself and the carrier's frequency.
+type RingMod struct {
+	baseEffect
+
+	frequency float64
+	waveform  WaveformType
+	Source    io.ReadSeeker
+
+	phase float64
+}
+
+// NewRingMod creates a new RingMod effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewRingMod() *RingMod {
+	return &RingMod{
+		frequency:  440,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (ringMod *RingMod) Clone() resound.IEffect {
+	return &RingMod{
+		frequency:  ringMod.frequency,
+		waveform:   ringMod.waveform,
+		baseEffect: ringMod.baseEffect.clone(),
+		Source:     ringMod.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (ringMod *RingMod) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":    boolToFloat(ringMod.active),
+		"mix":       ringMod.mix,
+		"frequency": ringMod.frequency,
+		"waveform":  float64(ringMod.waveform),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (ringMod *RingMod) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { ringMod.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { ringMod.SetMix(x) })
+	setParam(params, "frequency", func(x float64) { ringMod.SetFrequency(x) })
+	setParam(params, "waveform", func(x float64) { ringMod.SetWaveform(WaveformType(x)) })
+}
+
+func (ringMod *RingMod) Read(p []byte) (n int, err error) {
+
+	if n, err = ringMod.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	ringMod.ApplyEffect(p, n)
+
+	return
+}
+
+func (ringMod *RingMod) ApplyEffect(p []byte, bytesRead int) {
+
+	if !ringMod.active {
+		return
+	}
+
+	if ringMod.blends() {
+		ringMod.storeDry(p, bytesRead)
+		defer ringMod.blendDry(p, bytesRead)
+	}
+
+	// The carrier advances per sample read, so its frequency stays accurate regardless of the size of the buffer.
+	phaseStep := 2 * math.Pi * ringMod.frequency / float64(resound.SampleRate())
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		carrier := oscillate(ringMod.waveform, ringMod.phase)
+
+		audio.Set(i, l*carrier, r*carrier)
+
+		ringMod.phase += phaseStep
+		if ringMod.phase >= 2*math.Pi {
+			ringMod.phase -= 2 * math.Pi
+		}
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's carrier phase (see Reset()).
+func (ringMod *RingMod) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		ringMod.Reset()
+	}
+	if ringMod.Source == nil {
+		return 0, nil
+	}
+	return ringMod.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's carrier phase, as though it had just been created. Its settings are left unchanged.
+func (ringMod *RingMod) Reset() {
+	ringMod.phase = 0
+}
+
+// SetActive sets the effect to be active.
+func (ringMod *RingMod) SetActive(active bool) *RingMod {
+	ringMod.active = active
+	return ringMod
+}
+
+// Active returns if the effect is active.
+func (ringMod *RingMod) Active() bool {
+	return ringMod.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (ringMod *RingMod) SetMix(mix float64) *RingMod {
+	ringMod.setMix(mix)
+	return ringMod
+}
+
+// SetFrequency sets the frequency of the carrier wave, in hertz. Low frequencies (below about 20 Hz) sound like a tremolo,
+// while higher frequencies produce metallic or robotic tones. 0 is the minimum value. Defaults to 440.
+func (ringMod *RingMod) SetFrequency(hz float64) *RingMod {
+	if hz < 0 {
+		hz = 0
+	}
+	ringMod.frequency = hz
+	return ringMod
+}
+
+// Frequency returns the frequency of the carrier wave, in hertz.
+func (ringMod *RingMod) Frequency() float64 {
+	return ringMod.frequency
+}
+
+// SetWaveform sets the shape of the carrier wave. A sine wave gives a smooth, bell-like tone, while a square wave
+// gives a harsher, buzzier one. Defaults to WaveformSine.
+func (ringMod *RingMod) SetWaveform(waveform WaveformType) *RingMod {
+	ringMod.waveform = waveform
+	return ringMod
+}
+
+// Waveform returns the shape of the carrier wave.
+func (ringMod *RingMod) Waveform() WaveformType {
+	return ringMod.waveform
+}
+
+// SetSource sets the active source for the effect.
+func (ringMod *RingMod) SetSource(source io.ReadSeeker) {
+	ringMod.Source = source
+}
@@ -0,0 +1,50 @@
+package effects
+
+import "github.com/solarlune/resound"
+
+// smoothedParamInterval is how many samples a filter processes between updates of its coefficients while its cutoff is being smoothed.
+const smoothedParamInterval = 32
+
+// smoothedParam ramps a parameter linearly towards a target value as audio is processed, one sample at a time, so that
+// changing the parameter doesn't cause "zipper" noise (the clicks and buzzing of a value jumping at the start of each buffer).
+// The parameter's value itself is kept by the effect; the smoothedParam only knows how to step it towards the target.
+type smoothedParam struct {
+	target    float64
+	step      float64
+	remaining int
+}
+
+// rampTo starts ramping from the given current value to the target value over the given time in seconds.
+// If the time is too short to span a sample, the ramp finishes on the next sample.
+func (s *smoothedParam) rampTo(current, target, seconds float64) {
+	samples := int(seconds * float64(resound.SampleRate()))
+	if samples < 1 {
+		samples = 1
+	}
+	s.target = target
+	s.step = (target - current) / float64(samples)
+	s.remaining = samples
+}
+
+// stop stops the ramp, leaving the parameter where it is.
+func (s *smoothedParam) stop() {
+	s.remaining = 0
+}
+
+// ramping returns if the parameter is still being ramped towards its target.
+func (s *smoothedParam) ramping() bool {
+	return s.remaining > 0
+}
+
+// advance steps the given value one sample further towards the target, if it's still being ramped.
+func (s *smoothedParam) advance(value *float64) {
+	if s.remaining <= 0 {
+		return
+	}
+	s.remaining--
+	if s.remaining == 0 {
+		*value = s.target
+	} else {
+		*value += s.step
+	}
+}
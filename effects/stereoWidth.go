@@ -0,0 +1,134 @@
+package effects
+
+import (
+	"io"
+
+	"github.com/solarlune/resound"
+)
+
+// StereoWidth is an effect that widens or narrows the stereo image of the audio using mid/side processing.
+// Narrowing the audio all the way to mono is also handy for checking how a mix sounds when played back in mono.
+type StereoWidth struct {
+	baseEffect
+
+	width  float64
+	Source io.ReadSeeker
+}
+
+// NewStereoWidth creates a new StereoWidth effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewStereoWidth() *StereoWidth {
+	return &StereoWidth{
+		width:      1,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (sw *StereoWidth) Clone() resound.IEffect {
+	return &StereoWidth{
+		width:      sw.width,
+		baseEffect: sw.baseEffect.clone(),
+		Source:     sw.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (sw *StereoWidth) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(sw.active),
+		"mix":    sw.mix,
+		"width":  sw.width,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (sw *StereoWidth) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { sw.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { sw.SetMix(x) })
+	setParam(params, "width", func(x float64) { sw.SetWidth(x) })
+}
+
+func (sw *StereoWidth) Read(p []byte) (n int, err error) {
+
+	if n, err = sw.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	sw.ApplyEffect(p, n)
+
+	return
+}
+
+func (sw *StereoWidth) ApplyEffect(p []byte, bytesRead int) {
+
+	if !sw.active {
+		return
+	}
+
+	if sw.blends() {
+		sw.storeDry(p, bytesRead)
+		defer sw.blendDry(p, bytesRead)
+	}
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		// The mid signal is what both channels have in common, while the side signal is what differs between them;
+		// scaling the side signal changes how far apart the channels sound.
+		mid := (l + r) / 2
+		side := (l - r) / 2 * sw.width
+
+		audio.Set(i, mid+side, mid-side)
+
+	}
+
+}
+
+func (sw *StereoWidth) Seek(offset int64, whence int) (int64, error) {
+	if sw.Source == nil {
+		return 0, nil
+	}
+	return sw.Source.Seek(offset, whence)
+}
+
+// SetActive sets the effect to be active.
+func (sw *StereoWidth) SetActive(active bool) *StereoWidth {
+	sw.active = active
+	return sw
+}
+
+// Active returns if the effect is active.
+func (sw *StereoWidth) Active() bool {
+	return sw.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (sw *StereoWidth) SetMix(mix float64) *StereoWidth {
+	sw.setMix(mix)
+	return sw
+}
+
+// SetWidth sets the width of the stereo image. 0 collapses the audio to mono, 1 leaves it unchanged,
+// and values above 1 widen it. 0 is the minimum value.
+func (sw *StereoWidth) SetWidth(width float64) *StereoWidth {
+	if width < 0 {
+		width = 0
+	}
+	sw.width = width
+	return sw
+}
+
+// Width returns the width of the stereo image.
+func (sw *StereoWidth) Width() float64 {
+	return sw.width
+}
+
+// SetSource sets the active source for the effect.
+func (sw *StereoWidth) SetSource(source io.ReadSeeker) {
+	sw.Source = source
+}
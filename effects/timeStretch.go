@@ -0,0 +1,13 @@
+package effects
+
+import "github.com/solarlune/resound"
+
+// TimeStretch is a stream effect that changes the length (and so the speed) of audio without changing its pitch, using the
+// WSOLA (waveform-similarity overlap-add) algorithm. It's the same as resound.TimeStretcher, which Players also use to change
+// their playback rate while preserving their pitch; see there for details.
+type TimeStretch = resound.TimeStretcher
+
+// NewTimeStretch creates a new TimeStretch effect.
+func NewTimeStretch() *TimeStretch {
+	return resound.NewTimeStretcher()
+}
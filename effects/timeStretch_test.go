@@ -0,0 +1,60 @@
+package effects
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/solarlune/resound"
+)
+
+func TestTimeStretchKeepsPitch(t *testing.T) {
+
+	resound.SetDefaultSampleRate(44100)
+
+	frames := 44100
+
+	data := make([]byte, frames*4)
+	for i := 0; i < frames; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*441*float64(i)/44100)
+		resound.AudioBuffer(data).Set(i, v, v)
+	}
+
+	stretch := NewTimeStretch().SetStretch(2)
+	stretch.SetSource(bytes.NewReader(data))
+
+	output := []byte{}
+	buffer := make([]byte, 4096)
+
+	for {
+		n, err := stretch.Read(buffer)
+		output = append(output, buffer[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if length := len(output) / 4; math.Abs(float64(length)/float64(frames)-2) > 0.05 {
+		t.Errorf("expected stretching by 2 to double the length of the audio, got %d frames from %d", length, frames)
+	}
+
+	// The pitch is measured by counting how often the signal crosses zero in the middle of the output, away from the ends.
+	crossings := 0
+	start, end := len(output)/4/4, len(output)/4*3/4
+	prev, _ := resound.AudioBuffer(output).Get(start)
+	for i := start + 1; i < end; i++ {
+		l, _ := resound.AudioBuffer(output).Get(i)
+		if (l >= 0) != (prev >= 0) {
+			crossings++
+		}
+		prev = l
+	}
+
+	if freq := float64(crossings) / 2 / (float64(end-start) / 44100); math.Abs(freq-441) > 441*0.02 {
+		t.Errorf("expected stretching to keep a 441 hz sine at the same pitch, got %f hz", freq)
+	}
+
+}
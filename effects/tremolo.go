@@ -0,0 +1,182 @@
+package effects
+
+import (
+	"io"
+	"math"
+
+	"github.com/solarlune/resound"
+)
+
+// Tremolo is an effect that periodically raises and lowers the volume of the audio using a low-frequency oscillator (LFO).
+type Tremolo struct {
+	baseEffect
+
+	rate     float64
+	depth    float64
+	waveform WaveformType
+	Source   io.ReadSeeker
+
+	phase float64
+}
+
+// NewTremolo creates a new Tremolo effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewTremolo() *Tremolo {
+	return &Tremolo{
+		rate:       4,
+		depth:      0.5,
+		baseEffect: newBaseEffect(),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (tremolo *Tremolo) Clone() resound.IEffect {
+	return &Tremolo{
+		rate:       tremolo.rate,
+		depth:      tremolo.depth,
+		waveform:   tremolo.waveform,
+		baseEffect: tremolo.baseEffect.clone(),
+		Source:     tremolo.Source,
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (tremolo *Tremolo) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active":   boolToFloat(tremolo.active),
+		"mix":      tremolo.mix,
+		"rate":     tremolo.rate,
+		"depth":    tremolo.depth,
+		"waveform": float64(tremolo.waveform),
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (tremolo *Tremolo) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { tremolo.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { tremolo.SetMix(x) })
+	setParam(params, "rate", func(x float64) { tremolo.SetRate(x) })
+	setParam(params, "depth", func(x float64) { tremolo.SetDepth(x) })
+	setParam(params, "waveform", func(x float64) { tremolo.SetWaveform(WaveformType(x)) })
+}
+
+func (tremolo *Tremolo) Read(p []byte) (n int, err error) {
+
+	if n, err = tremolo.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	tremolo.ApplyEffect(p, n)
+
+	return
+}
+
+func (tremolo *Tremolo) ApplyEffect(p []byte, bytesRead int) {
+
+	if !tremolo.active {
+		return
+	}
+
+	if tremolo.blends() {
+		tremolo.storeDry(p, bytesRead)
+		defer tremolo.blendDry(p, bytesRead)
+	}
+
+	// The LFO advances per sample read, so the rate stays accurate regardless of the size of the buffer.
+	phaseStep := 2 * math.Pi * tremolo.rate / float64(resound.SampleRate())
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		// The gain ranges from 1 - depth (at the bottom of the wave) to 1 (at the top).
+		lfo := (oscillate(tremolo.waveform, tremolo.phase) + 1) / 2
+		gain := 1 - tremolo.depth*(1-lfo)
+
+		audio.Set(i, l*gain, r*gain)
+
+		tremolo.phase += phaseStep
+		if tremolo.phase >= 2*math.Pi {
+			tremolo.phase -= 2 * math.Pi
+		}
+
+	}
+
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's LFO phase (see Reset()).
+func (tremolo *Tremolo) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		tremolo.Reset()
+	}
+	if tremolo.Source == nil {
+		return 0, nil
+	}
+	return tremolo.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's LFO phase, as though it had just been created. Its settings are left unchanged.
+func (tremolo *Tremolo) Reset() {
+	tremolo.phase = 0
+}
+
+// SetActive sets the effect to be active.
+func (tremolo *Tremolo) SetActive(active bool) *Tremolo {
+	tremolo.active = active
+	return tremolo
+}
+
+// Active returns if the effect is active.
+func (tremolo *Tremolo) Active() bool {
+	return tremolo.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (tremolo *Tremolo) SetMix(mix float64) *Tremolo {
+	tremolo.setMix(mix)
+	return tremolo
+}
+
+// SetRate sets how quickly the volume rises and falls, in hertz (cycles per second). 0 is the minimum value.
+func (tremolo *Tremolo) SetRate(rate float64) *Tremolo {
+	if rate < 0 {
+		rate = 0
+	}
+	tremolo.rate = rate
+	return tremolo
+}
+
+// Rate returns how quickly the volume rises and falls, in hertz.
+func (tremolo *Tremolo) Rate() float64 {
+	return tremolo.rate
+}
+
+// SetDepth sets how much the volume changes, ranging from 0 (no effect) to 1 (the volume drops to silence at the bottom of each cycle).
+func (tremolo *Tremolo) SetDepth(depth float64) *Tremolo {
+	tremolo.depth = clamp(depth, 0, 1)
+	return tremolo
+}
+
+// Depth returns how much the volume changes, ranging from 0 to 1.
+func (tremolo *Tremolo) Depth() float64 {
+	return tremolo.depth
+}
+
+// SetWaveform sets the shape of the wave used to change the volume. Defaults to WaveformSine.
+func (tremolo *Tremolo) SetWaveform(waveform WaveformType) *Tremolo {
+	tremolo.waveform = waveform
+	return tremolo
+}
+
+// Waveform returns the shape of the wave used to change the volume.
+func (tremolo *Tremolo) Waveform() WaveformType {
+	return tremolo.waveform
+}
+
+// SetSource sets the active source for the effect.
+func (tremolo *Tremolo) SetSource(source io.ReadSeeker) {
+	tremolo.Source = source
+}
@@ -0,0 +1,202 @@
+package effects
+
+import (
+	"io"
+	"math"
+	"time"
+
+	"github.com/solarlune/resound"
+)
+
+// Vibrato is an effect that periodically raises and lowers the pitch of the audio using a low-frequency oscillator (LFO).
+// Unlike PitchShift, Vibrato is meant for small, oscillating pitch changes. It works by reading from a delay line whose
+// delay time is swept back and forth, which speeds up and slows down the audio slightly.
+type Vibrato struct {
+	baseEffect
+
+	rate   float64
+	depth  float64
+	Source io.ReadSeeker
+
+	phase  float64
+	buffer circularBuffer
+}
+
+// NewVibrato creates a new Vibrato effect.
+// You'll need to manually set the source if you want to play the effect manually as a Player's source, rather than by adding it as an effect to the Player.
+func NewVibrato() *Vibrato {
+	return &Vibrato{
+		rate:       5,
+		depth:      25,
+		baseEffect: newBaseEffect(),
+		buffer:     newCircularBuffer(0),
+	}
+}
+
+// Clone clones the effect, returning an resound.IEffect.
+func (vibrato *Vibrato) Clone() resound.IEffect {
+	return &Vibrato{
+		rate:       vibrato.rate,
+		depth:      vibrato.depth,
+		baseEffect: vibrato.baseEffect.clone(),
+		Source:     vibrato.Source,
+		buffer:     newCircularBuffer(0),
+	}
+}
+
+// Parameters returns the effect's settings as a map of named parameters.
+func (vibrato *Vibrato) Parameters() map[string]float64 {
+	return map[string]float64{
+		"active": boolToFloat(vibrato.active),
+		"mix":    vibrato.mix,
+		"rate":   vibrato.rate,
+		"depth":  vibrato.depth,
+	}
+}
+
+// SetParameters sets the effect's settings from a map of named parameters, like one returned from Parameters().
+func (vibrato *Vibrato) SetParameters(params map[string]float64) {
+	setParam(params, "active", func(x float64) { vibrato.SetActive(x != 0) })
+	setParam(params, "mix", func(x float64) { vibrato.SetMix(x) })
+	setParam(params, "rate", func(x float64) { vibrato.SetRate(x) })
+	setParam(params, "depth", func(x float64) { vibrato.SetDepth(x) })
+}
+
+func (vibrato *Vibrato) Read(p []byte) (n int, err error) {
+
+	if n, err = vibrato.Source.Read(p); err != nil && (err != io.EOF || n == 0) {
+		return
+	}
+
+	vibrato.ApplyEffect(p, n)
+
+	return
+}
+
+func (vibrato *Vibrato) ApplyEffect(p []byte, bytesRead int) {
+
+	if !vibrato.active {
+		return
+	}
+
+	if vibrato.blends() {
+		vibrato.storeDry(p, bytesRead)
+		defer vibrato.blendDry(p, bytesRead)
+	}
+
+	sampleRate := float64(resound.SampleRate())
+
+	baseDelay, amplitude := vibrato.sweep(sampleRate)
+
+	vibrato.buffer.resize(int(baseDelay+amplitude) + 4)
+
+	phaseStep := 2 * math.Pi * vibrato.rate / sampleRate
+
+	audio := resound.AudioBuffer(p)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		l, r := audio.Get(i)
+
+		vibrato.buffer.write(l, r)
+
+		vl, vr := vibrato.buffer.readDelayed(baseDelay + amplitude*math.Sin(vibrato.phase))
+
+		audio.Set(i, vl, vr)
+
+		vibrato.phase += phaseStep
+		if vibrato.phase >= 2*math.Pi {
+			vibrato.phase -= 2 * math.Pi
+		}
+
+	}
+
+}
+
+// sweep returns the delay (in samples) the Vibrato's delay line is swept around, and how far (in samples) it's swept.
+func (vibrato *Vibrato) sweep(sampleRate float64) (baseDelay, amplitude float64) {
+
+	// Sweeping a delay line's delay time by amplitude * sin(ωt) changes the pitch by a ratio of up to 1 + amplitude * ω;
+	// we work backwards from the depth in cents to get the sweep amplitude in samples.
+	if vibrato.rate > 0 {
+		maxRatio := math.Pow(2, vibrato.depth/1200)
+		amplitude = math.Min((maxRatio-1)*sampleRate/(2*math.Pi*vibrato.rate), sampleRate)
+	}
+
+	return amplitude + 2, amplitude
+
+}
+
+// Latency returns how long the Vibrato delays the audio by on average, which is the delay its delay line is swept around.
+// This depends on the rate and depth; slower and deeper vibratos need a longer delay line.
+func (vibrato *Vibrato) Latency() time.Duration {
+	sampleRate := float64(resound.SampleRate())
+	baseDelay, _ := vibrato.sweep(sampleRate)
+	return time.Duration(baseDelay / sampleRate * float64(time.Second))
+}
+
+// Seek seeks the effect's source. Seeking to the start of the stream also clears the effect's delay line and LFO phase (see Reset()).
+func (vibrato *Vibrato) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekStart {
+		vibrato.Reset()
+	}
+	if vibrato.Source == nil {
+		return 0, nil
+	}
+	return vibrato.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's delay line and LFO phase, as though it had just been created. Its settings are left unchanged.
+func (vibrato *Vibrato) Reset() {
+	vibrato.buffer.clear()
+	vibrato.phase = 0
+}
+
+// SetActive sets the effect to be active.
+func (vibrato *Vibrato) SetActive(active bool) *Vibrato {
+	vibrato.active = active
+	return vibrato
+}
+
+// Active returns if the effect is active.
+func (vibrato *Vibrato) Active() bool {
+	return vibrato.active
+}
+
+// SetMix sets how much of the processed (wet) signal is blended with the original (dry) signal, ranging from 0 (fully dry)
+// to 1 (fully wet). Defaults to 1.
+func (vibrato *Vibrato) SetMix(mix float64) *Vibrato {
+	vibrato.setMix(mix)
+	return vibrato
+}
+
+// SetRate sets how quickly the pitch rises and falls, in hertz (cycles per second). 0 is the minimum value.
+func (vibrato *Vibrato) SetRate(rate float64) *Vibrato {
+	if rate < 0 {
+		rate = 0
+	}
+	vibrato.rate = rate
+	return vibrato
+}
+
+// Rate returns how quickly the pitch rises and falls, in hertz.
+func (vibrato *Vibrato) Rate() float64 {
+	return vibrato.rate
+}
+
+// SetDepth sets how far the pitch moves up and down from the original pitch, in cents (hundredths of a semitone).
+// The value is clamped from 0 to 1200 (an octave); subtle vibratos are usually somewhere between 10 and 50 cents.
+func (vibrato *Vibrato) SetDepth(cents float64) *Vibrato {
+	vibrato.depth = clamp(cents, 0, 1200)
+	return vibrato
+}
+
+// Depth returns how far the pitch moves up and down from the original pitch, in cents.
+func (vibrato *Vibrato) Depth() float64 {
+	return vibrato.depth
+}
+
+// SetSource sets the active source for the effect.
+func (vibrato *Vibrato) SetSource(source io.ReadSeeker) {
+	vibrato.Source = source
+}
@@ -0,0 +1,123 @@
+package resound
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tanema/gween/ease"
+)
+
+// envelopePoint is a breakpoint in an Envelope.
+type envelopePoint struct {
+	time  float64
+	value float64
+	curve ease.TweenFunc
+}
+
+// Envelope is a breakpoint envelope: a value that changes over time by moving from one point to the next, like the attack, decay,
+// sustain, and release of a note. Unlike an Automation, which steps a setter along as the game updates, an Envelope is evaluated
+// for a given point in the audio (see ValueAt()), so effects can evaluate it for every sample they process to drive a parameter
+// (like a gain, a filter's cutoff, or a pitch) smoothly and exactly in time with the audio.
+// An Envelope's points should be added before it's used to process audio, as it isn't safe to change while it's being evaluated.
+type Envelope struct {
+	points []envelopePoint
+	loop   bool
+}
+
+// NewEnvelope creates a new, empty Envelope.
+func NewEnvelope() *Envelope {
+	return &Envelope{}
+}
+
+// AddPoint adds a point to the Envelope with the given value at the given time in seconds. The value moves linearly from the
+// previous point to this one. Points can be added in any order; adding a point at the same time as an existing one replaces it.
+func (e *Envelope) AddPoint(timeSeconds, value float64) *Envelope {
+	return e.AddPointCurve(timeSeconds, value, nil)
+}
+
+// AddPointCurve adds a point to the Envelope with the given value at the given time in seconds, using the given easing function to
+// move from the previous point to this one. Easing into and out of points gives the Envelope soft knees, rather than sharp corners.
+// If curve is nil, the value moves linearly.
+func (e *Envelope) AddPointCurve(timeSeconds, value float64, curve ease.TweenFunc) *Envelope {
+
+	if curve == nil {
+		curve = ease.Linear
+	}
+
+	point := envelopePoint{time: math.Max(timeSeconds, 0), value: value, curve: curve}
+
+	index := sort.Search(len(e.points), func(i int) bool { return e.points[i].time >= point.time })
+
+	if index < len(e.points) && e.points[index].time == point.time {
+		e.points[index] = point
+	} else {
+		e.points = append(e.points, envelopePoint{})
+		copy(e.points[index+1:], e.points[index:])
+		e.points[index] = point
+	}
+
+	return e
+
+}
+
+// ClearPoints removes all of the Envelope's points.
+func (e *Envelope) ClearPoints() *Envelope {
+	e.points = nil
+	return e
+}
+
+// Loop sets whether the Envelope loops, starting over from its first point after its last point has been reached.
+func (e *Envelope) Loop(loop bool) *Envelope {
+	e.loop = loop
+	return e
+}
+
+// Looping returns whether the Envelope loops.
+func (e *Envelope) Looping() bool {
+	return e.loop
+}
+
+// Duration returns the length of the Envelope in seconds; that is, the time of its last point.
+func (e *Envelope) Duration() float64 {
+	if len(e.points) == 0 {
+		return 0
+	}
+	return e.points[len(e.points)-1].time
+}
+
+// ValueAt returns the Envelope's value after the given number of samples (frames) have elapsed, based on the sample rate given by
+// SampleRate(). Before the first point, the Envelope has the first point's value, and after the last point (if it doesn't loop),
+// it holds the last point's value. An Envelope with no points always has a value of 1.
+// Finding the current point is a binary search, so ValueAt is cheap enough to call for every sample.
+func (e *Envelope) ValueAt(samplesElapsed int64) float64 {
+	return e.Value(float64(samplesElapsed) / float64(SampleRate()))
+}
+
+// Value returns the Envelope's value at the given time in seconds. See ValueAt() for details.
+func (e *Envelope) Value(timeSeconds float64) float64 {
+
+	if len(e.points) == 0 {
+		return 1
+	}
+
+	if duration := e.Duration(); e.loop && duration > 0 {
+		timeSeconds = math.Mod(timeSeconds, duration)
+		if timeSeconds < 0 {
+			timeSeconds += duration
+		}
+	}
+
+	index := sort.Search(len(e.points), func(i int) bool { return e.points[i].time > timeSeconds })
+
+	if index == 0 {
+		return e.points[0].value
+	} else if index >= len(e.points) {
+		return e.points[len(e.points)-1].value
+	}
+
+	from := e.points[index-1]
+	to := e.points[index]
+
+	return float64(to.curve(float32(timeSeconds-from.time), float32(from.value), float32(to.value-from.value), float32(to.time-from.time)))
+
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"image/color"
+	"io"
 	"time"
 
 	_ "embed"
@@ -20,8 +21,9 @@ import (
 )
 
 type Game struct {
-	DSP  *resound.DSPChannel
-	Time float64
+	DSP   *resound.DSPChannel
+	Steps *resound.SoundPool
+	Time  float64
 }
 
 //go:embed song.ogg
@@ -48,7 +50,7 @@ func NewGame() *Game {
 	// Now we add effects; we don't have to specify a source because a DSPChannel applies effects
 	// to all streams played through the channel.
 
-	game.DSP.AddEffect("delay", effects.NewDelay().SetWait(0.1).SetStrength(0.9))
+	game.DSP.AddEffect("delay", effects.NewDelay().SetWait(0.1).SetWetLevel(0.45))
 	game.DSP.AddEffect("pan", effects.NewPan())
 	game.DSP.AddEffect("volume", effects.NewVolume())
 
@@ -73,7 +75,22 @@ func NewGame() *Game {
 	// We set the DSP channel so the sound player takes on the effects set on the DSP channe.
 	player.SetDSPChannel(game.DSP)
 	player.Play()
-	player.SetBufferSize(time.Millisecond * 50)
+
+	// Players playing through DSPChannels are mixed into the master channel's stream, so we lower its buffer size
+	// to hear changes to the effects sooner.
+	resound.SetMasterBufferSize(time.Millisecond * 50)
+
+	// For sounds that play often, like footsteps, a SoundPool decodes the sound once and reuses Players to play it,
+	// rather than decoding it again each time. Here, up to 4 footsteps can play at once.
+	steps, err := resound.NewSoundPool(stepData, func(src io.Reader) (io.Reader, error) {
+		return wav.DecodeWithSampleRate(sampleRate, src)
+	}, 4)
+	if err != nil {
+		panic(err)
+	}
+
+	// The pool's voices play through the DSP channel, so they take on its effects as well.
+	game.Steps = steps.SetDSPChannel(game.DSP)
 
 	return game
 }
@@ -111,17 +128,9 @@ func (game *Game) Update() error {
 
 	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
 
-		reader := bytes.NewReader(stepData)
-		stream, err := wav.DecodeWithSampleRate(sampleRate, reader)
-		if err != nil {
-			panic(err)
-		}
-
-		player, err := resound.NewPlayer(stream)
-		if err != nil {
+		if _, err := game.Steps.Play(); err != nil {
 			panic(err)
 		}
-		player.SetDSPChannel(game.DSP).Play()
 
 	}
 
@@ -70,7 +70,8 @@ func (game *Game) Play(name string, sample []byte) {
 		panic(err)
 	}
 
-	volume := effects.NewVolume().SetSource(stream)
+	volume := effects.NewVolume()
+	volume.SetSource(stream)
 
 	if game.Normalize {
 		// Here we analyze the stream, using a scan count number for how many times to sample the audio file.
@@ -43,9 +43,11 @@ func NewGame() *Game {
 
 	game := &Game{
 		// Create a pitch shift effect with the given pitch buffer size.
-		PitchShift: effects.NewPitchShift(1024).SetSource(loop).SetPitch(0.8),
+		PitchShift: effects.NewPitchShift(1024).SetPitch(0.8),
 	}
 
+	game.PitchShift.SetSource(loop)
+
 	player, err := context.NewPlayer(game.PitchShift)
 
 	if err != nil {
@@ -55,7 +57,8 @@ func NewGame() *Game {
 	// Change the buffer size so that we can have some responsiveness
 	// when we change effect parameters on the fly; if we leave this
 	// default (which is like 200 milliseconds or something like that),
-	// then changing effect parameters will seem laggy.
+	// then changing effect parameters will seem laggy. The effect is played
+	// through an audio.Player directly here, so its own buffer size applies.
 	player.SetBufferSize(time.Millisecond * 50)
 
 	// Finally, play the sound.
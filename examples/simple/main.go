@@ -59,11 +59,12 @@ func NewGame() *Game {
 	// Change the buffer size of audio so that we can have some responsiveness
 	// when we change effect parameters on the fly; if we leave this
 	// set to the default (which is like 200 milliseconds or something like that),
-	// then changing effect parameters will feel laggy.
-	game.Audio.SetBufferSize(time.Millisecond * 50)
+	// then changing effect parameters will feel laggy. Players are mixed into
+	// the master channel's stream, so that's the buffer size to change.
+	resound.SetMasterBufferSize(time.Millisecond * 50)
 
 	// We will also create a new effect for it - the delay.
-	game.Audio.AddEffect("delay", effects.NewDelay().SetStrength(0.75).SetWait(0.1).SetFeedback(0.5))
+	game.Audio.AddEffect("delay", effects.NewDelay().SetWetLevel(0.4).SetWait(0.1).SetFeedback(0.5))
 	// The Volume effect will be used for fading.
 	game.Audio.AddEffect("volume", effects.NewVolume())
 
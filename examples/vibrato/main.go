@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"time"
+
+	_ "embed"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/solarlune/resound"
+	"github.com/solarlune/resound/effects"
+	"golang.org/x/image/font/basicfont"
+)
+
+type Game struct {
+	Vibrato *effects.Vibrato
+	Time    float64
+}
+
+//go:embed song.ogg
+var songData []byte
+
+const sampleRate = 44100
+
+func NewGame() *Game {
+
+	audio.NewContext(sampleRate)
+
+	reader := bytes.NewReader(songData)
+
+	stream, err := vorbis.DecodeWithSampleRate(sampleRate, reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	loop := audio.NewInfiniteLoop(stream, stream.Length())
+
+	game := &Game{
+		// Create a vibrato effect that wobbles the pitch 5 times a second, by 25 cents up and down.
+		Vibrato: effects.NewVibrato().SetRate(5).SetDepth(25),
+	}
+
+	game.Vibrato.SetSource(loop)
+
+	// The vibrato effect reads from the loop, so we can play it through a resound.Player directly.
+	player, err := resound.NewPlayer(game.Vibrato)
+
+	if err != nil {
+		panic(err)
+	}
+
+	// Change the buffer size so that we can have some responsiveness
+	// when we change effect parameters on the fly; if we leave this
+	// default (which is like 200 milliseconds or something like that),
+	// then changing effect parameters will seem laggy. Players are mixed into
+	// the master channel's stream, so that's the buffer size to change.
+	resound.SetMasterBufferSize(time.Millisecond * 50)
+
+	// Finally, play the sound.
+	player.Play()
+
+	return game
+}
+
+func (game *Game) Update() error {
+
+	var err error
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		game.Vibrato.SetActive(!game.Vibrato.Active())
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		game.Vibrato.SetDepth(game.Vibrato.Depth() + 5)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		game.Vibrato.SetDepth(game.Vibrato.Depth() - 5)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		game.Vibrato.SetRate(game.Vibrato.Rate() + 0.5)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		game.Vibrato.SetRate(game.Vibrato.Rate() - 0.5)
+	}
+
+	game.Time += 1.0 / 60.0
+
+	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
+		err = ebiten.Termination
+	}
+
+	return err
+}
+
+func (game *Game) Draw(screen *ebiten.Image) {
+
+	text.Draw(screen, fmt.Sprintf(`This example shows how the Vibrato
+effect works. Press the Space key
+to toggle the vibrato effect.
+The up and down keys change the
+depth, and the left and right keys
+change the rate.
+
+Vibrato On: %t
+Depth (cents): %.0f
+Rate (hz): %.1f`, game.Vibrato.Active(), game.Vibrato.Depth(), game.Vibrato.Rate()), basicfont.Face7x13, 16, 16, color.White)
+
+}
+
+func (game *Game) Layout(w, h int) (int, int) { return 320, 240 }
+
+func main() {
+
+	ebiten.SetWindowTitle("Resound Demo - Vibrato")
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+
+	if err := ebiten.RunGame(NewGame()); err != nil {
+		panic(err)
+	}
+
+}
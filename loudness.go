@@ -0,0 +1,113 @@
+package resound
+
+import "math"
+
+// kWeightingStage is a single biquad stage of a K-weighting filter, processing stereo audio.
+type kWeightingStage struct {
+	b0, b1, b2, a1, a2 float64
+
+	x1, x2 [2]float64
+	y1, y2 [2]float64
+}
+
+func (s *kWeightingStage) process(channel int, x float64) float64 {
+	y := s.b0*x + s.b1*s.x1[channel] + s.b2*s.x2[channel] - s.a1*s.y1[channel] - s.a2*s.y2[channel]
+	s.x2[channel] = s.x1[channel]
+	s.x1[channel] = x
+	s.y2[channel] = s.y1[channel]
+	s.y1[channel] = y
+	return y
+}
+
+// kWeighting is the K-weighting filter from ITU-R BS.1770, used to measure loudness; it consists of a high shelf
+// (modelling the acoustic effect of the head) followed by a high-pass filter (modelling how we hear low frequencies).
+// The coefficients are calculated for any sample rate using the same formulas as libebur128.
+type kWeighting struct {
+	shelf    kWeightingStage
+	highpass kWeightingStage
+}
+
+func newKWeighting(sampleRate int) *kWeighting {
+
+	kw := &kWeighting{}
+
+	rate := float64(sampleRate)
+
+	f0 := 1681.974450955533
+	g := 3.999843853973347
+	q := 0.7071752369554196
+
+	k := math.Tan(math.Pi * f0 / rate)
+	vh := math.Pow(10, g/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+
+	kw.shelf = kWeightingStage{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	f0 = 38.13547087602444
+	q = 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / rate)
+	a0 = 1 + k/q + k*k
+
+	kw.highpass = kWeightingStage{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	return kw
+
+}
+
+func (kw *kWeighting) process(channel int, x float64) float64 {
+	return kw.highpass.process(channel, kw.shelf.process(channel, x))
+}
+
+// blockLoudness returns the loudness (in LUFS) of a block of stereo audio given the mean squares of its K-weighted left and right channels.
+func blockLoudness(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// integratedLoudness returns the gated integrated loudness (in LUFS) of the given blocks, each represented by the summed mean squares of
+// its K-weighted channels, following ITU-R BS.1770: blocks quieter than -70 LUFS are discarded, and then blocks more than 10 LU
+// quieter than the loudness of the remaining blocks are discarded as well.
+func integratedLoudness(blocks []float64) float64 {
+
+	gated := func(threshold float64) (float64, int) {
+		sum := 0.0
+		count := 0
+		for _, b := range blocks {
+			if blockLoudness(b) > threshold {
+				sum += b
+				count++
+			}
+		}
+		return sum, count
+	}
+
+	sum, count := gated(-70)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+
+	relative := blockLoudness(sum/float64(count)) - 10
+
+	sum, count = gated(relative)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+
+	return blockLoudness(sum / float64(count))
+
+}
@@ -0,0 +1,176 @@
+package resound
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+var (
+	masterChannel     *DSPChannel
+	masterChannelOnce sync.Once
+)
+
+// MasterChannel returns the master DSPChannel, which is the final stage all audio played through resound passes through before reaching
+// the audio context. New Players play through the master channel by default, and DSPChannels that aren't routed into another channel
+// (see DSPChannel.SetOutput()) are routed into it, so effects added to the master channel (like a Limiter) and its volume apply to everything.
+// A Player can opt out of the master channel by calling Player.SetDSPChannel(nil), in which case it plays without any channel processing.
+// The master channel is created the first time it's needed, and its mix is played through the audio context as a single stream,
+// starting the first time a Player is played through it. The stream pauses once nothing is playing through any channel and their
+// effects have died away, and resumes when a Player is played through a channel again. While the master channel has no effects, analyzer, or sends and its volume
+// is left at 1, mixing through it costs no more than summing the audio; once effects are added, they process the final mix once per buffer.
+func MasterChannel() *DSPChannel {
+	masterChannelOnce.Do(func() {
+		masterChannel = NewDSPChannel()
+	})
+	return masterChannel
+}
+
+// SetMasterVolume sets the volume of the master channel, and so of all audio played through resound (see MasterChannel()).
+// This is a shortcut for MasterChannel().SetVolume().
+func SetMasterVolume(volume float64) {
+	MasterChannel().SetVolume(volume)
+}
+
+// MasterVolume returns the volume of the master channel.
+func MasterVolume() float64 {
+	return MasterChannel().Volume()
+}
+
+// SetMasterBufferSize sets the buffer size of the stream the master channel plays through the audio context, which is how far ahead
+// audio played through DSPChannels is mixed (see audio.Player.SetBufferSize()). Smaller buffers make changes to Players and effects
+// heard sooner, at the cost of more CPU overhead and a higher risk of dropouts. Players playing through a DSPChannel are mixed into
+// the master channel's stream rather than being played by the audio context, so this is the buffer size that applies to them.
+func SetMasterBufferSize(bufferSize time.Duration) {
+	master.setBufferSize(bufferSize)
+}
+
+// SetMasterMuted sets whether the master channel, and so all audio played through resound, is muted.
+// This is a shortcut for MasterChannel().SetMuted().
+func SetMasterMuted(muted bool) {
+	MasterChannel().SetMuted(muted)
+}
+
+// master plays the master channel's mix through the audio context.
+var master = &masterStream{}
+
+// masterStream is the stream that plays the master channel through the audio context; every Player playing through a DSPChannel
+// is mixed into it, rather than being played by the audio context itself.
+type masterStream struct {
+	graph      renderGraph
+	player     *audio.Player
+	bufferSize time.Duration
+	written    atomic.Int64 // The number of bytes of audio that have been rendered
+	pausing    atomic.Bool  // Whether the stream is about to be paused, as it's idle
+	mutex      sync.Mutex
+}
+
+// start starts playing the master channel through the audio context if it isn't already playing. If there's no audio context yet,
+// this does nothing, and the master channel starts playing the next time a Player is played through it.
+func (m *masterStream) start() {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.player != nil {
+		if !m.player.IsPlaying() {
+			m.player.Play()
+		}
+		return
+	}
+
+	context := audio.CurrentContext()
+	if context == nil {
+		return
+	}
+
+	player, err := context.NewPlayer(m)
+	if err != nil {
+		return
+	}
+
+	if m.bufferSize > 0 {
+		player.SetBufferSize(m.bufferSize)
+	}
+
+	m.player = player
+	m.player.Play()
+
+}
+
+// setBufferSize sets the buffer size of the stream's audio.Player, now if it's been created, or otherwise once it is.
+func (m *masterStream) setBufferSize(bufferSize time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.bufferSize = bufferSize
+	if m.player != nil {
+		m.player.SetBufferSize(bufferSize)
+	}
+}
+
+// pauseIfIdle pauses the stream if nothing is playing through the master channel or the channels routed into it (see
+// DSPChannel.idleRoute()). This is called from its own goroutine, as the audio.Player is locked while it reads the stream.
+// Playing a Player through a channel adds it to the channel before starting the stream, so a Player played while this is
+// running either keeps the stream from pausing or starts it again.
+func (m *masterStream) pauseIfIdle() {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.player != nil && MasterChannel().idleRoute() {
+		m.player.Pause()
+	}
+
+	m.pausing.Store(false)
+
+}
+
+// Read renders the master channel and everything routed into it. The stream never ends; when nothing is playing, it reads silence
+// until it's paused.
+func (m *masterStream) Read(p []byte) (int, error) {
+
+	frames := len(p) / 4
+
+	if m.graph.roots == nil {
+		m.graph.roots = []*DSPChannel{MasterChannel()}
+	}
+
+	m.graph.render(frames)
+
+	bus := &MasterChannel().bus
+	bus.mutex.Lock()
+	n := copy(p, bus.out[:frames*4])
+	bus.mutex.Unlock()
+
+	m.written.Add(int64(n))
+
+	if MasterChannel().idleRoute() && m.pausing.CompareAndSwap(false, true) {
+		go m.pauseIfIdle()
+	}
+
+	return n, nil
+
+}
+
+// buffered returns the number of bytes of audio the master channel has rendered that haven't been heard yet.
+func (m *masterStream) buffered() int64 {
+
+	m.mutex.Lock()
+	player := m.player
+	m.mutex.Unlock()
+
+	if player == nil {
+		return 0
+	}
+
+	heard := int64(player.Position().Seconds() * float64(SampleRate()*4))
+
+	if buffered := m.written.Load() - heard; buffered > 0 {
+		return buffered
+	}
+
+	return 0
+
+}
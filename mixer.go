@@ -0,0 +1,270 @@
+package resound
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrChannelNotInMixer is returned by Mixer.Add() when the Player's DSPChannel isn't routed into one of the Mixer's channels.
+var ErrChannelNotInMixer = errors.New("resound: the Player's DSPChannel isn't routed into one of the Mixer's channels")
+
+// Mixer renders several independent DSPChannels (buses) and sums them into a single stream, optionally rendering the buses in
+// parallel on multiple goroutines (see SetWorkers()). A Mixer is an io.ReadSeeker, so it's played by using it as the source of a Player.
+//
+// Players are added to a Mixer with Add(), which plays them through their DSPChannels; the Mixer renders each bus (with the Players
+// and channels routed into it) itself. The Mixer's channels output into the Mixer, rather than into another channel, so their output
+// settings are ignored while they belong to it. The Player playing the Mixer plays through the master channel (or another channel) as usual.
+//
+// The buses are rendered independently and then summed in the order their channels were given to NewMixer(), so the result is the
+// same regardless of how many workers are used. Buses whose audio reaches any of the same channels (like two buses that send to the
+// same reverb channel, or a bus that sends to another of the Mixer's buses) aren't independent, so they're grouped together and
+// rendered one after another on the same goroutine; this way, no channel is ever processed by two goroutines at once.
+//
+// Parallel rendering only helps when the buses are heavy, like buses with reverbs, convolution, or many Players each; the work of each
+// bus then outweighs the cost of handing it to another goroutine. For light buses, or when Ebitengine reads in very small buffers,
+// the overhead of coordinating the goroutines for every buffer can cost more than it saves, so a single worker is faster.
+type Mixer struct {
+	channels []*DSPChannel
+	bus      channelBus // The sum of the Mixer's channels
+	workers  int
+	mutex    sync.Mutex
+
+	groups        []*renderGraph // The groups of channels that can be rendered independently of each other
+	groupsVersion uint64         // The graph version the groups were built for
+	grouped       bool
+}
+
+// NewMixer creates a new Mixer that renders the given DSPChannels. A DSPChannel should only belong to one Mixer.
+// The Mixer renders its buses on a single goroutine by default; see SetWorkers().
+func NewMixer(channels ...*DSPChannel) *Mixer {
+
+	mixer := &Mixer{workers: 1}
+
+	for _, c := range channels {
+		if c == nil || mixer.owns(c) {
+			continue
+		}
+		c.reroute(func() { c.mixer = mixer })
+		mixer.channels = append(mixer.channels, c)
+	}
+
+	return mixer
+
+}
+
+// owns returns if the given channel is one of the Mixer's channels.
+func (m *Mixer) owns(channel *DSPChannel) bool {
+	for _, c := range m.channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Channels returns the DSPChannels the Mixer renders, in the order they're summed.
+func (m *Mixer) Channels() []*DSPChannel {
+	return append([]*DSPChannel{}, m.channels...)
+}
+
+// Add plays the given Player through its DSPChannel, which must be one of the Mixer's channels (or a channel routed into one of them),
+// or Add returns ErrChannelNotInMixer. The Mixer then renders the Player as part of its channel's mix; it's removed from the Mixer
+// once its stream ends, or when it's paused.
+func (m *Mixer) Add(player *Player) error {
+
+	player.mutex.Lock()
+	channel := player.DSPChannel
+	player.mutex.Unlock()
+
+	if channel == nil || !m.owns(channel.root()) {
+		return ErrChannelNotInMixer
+	}
+
+	player.Play()
+
+	return nil
+
+}
+
+// Remove removes the given Player from the Mixer by pausing it. If the Player isn't in the Mixer, this does nothing.
+func (m *Mixer) Remove(player *Player) {
+
+	player.mutex.Lock()
+	channel := player.DSPChannel
+	player.mutex.Unlock()
+
+	if channel != nil && m.owns(channel.root()) {
+		player.Pause()
+	}
+
+}
+
+// SetWorkers sets the number of goroutines the Mixer renders its buses on at once. 1 (the default) renders the buses one after
+// another on the goroutine reading from the Mixer; values above 1 render them in parallel (up to one goroutine per group of buses
+// that share channels; see Mixer). Values less than 1 are treated as 1.
+func (m *Mixer) SetWorkers(workers int) *Mixer {
+	if workers < 1 {
+		workers = 1
+	}
+	m.mutex.Lock()
+	m.workers = workers
+	m.mutex.Unlock()
+	return m
+}
+
+// Workers returns the number of goroutines the Mixer renders its buses on at once.
+func (m *Mixer) Workers() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.workers
+}
+
+// Read renders each of the Mixer's buses and sums them into the given buffer. The Mixer never ends; when nothing is playing, it reads silence.
+func (m *Mixer) Read(p []byte) (int, error) {
+
+	frames := len(p) / 4
+
+	m.mutex.Lock()
+	workers := m.workers
+	m.mutex.Unlock()
+
+	m.updateGroups()
+
+	if workers > len(m.groups) {
+		workers = len(m.groups)
+	}
+
+	if workers <= 1 {
+
+		for _, g := range m.groups {
+			g.renderOrder(frames)
+		}
+
+	} else {
+
+		// Each worker takes the next group of buses that hasn't been rendered until they're all done.
+		next := int32(-1)
+		wg := sync.WaitGroup{}
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := int(atomic.AddInt32(&next, 1)); i < len(m.groups); i = int(atomic.AddInt32(&next, 1)) {
+					m.groups[i].renderOrder(frames)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+	}
+
+	m.bus.resize(frames)
+
+	for _, c := range m.channels {
+		c.bus.mutex.Lock()
+		m.bus.add(c.bus.out, 1, 1)
+		c.bus.mutex.Unlock()
+	}
+
+	m.bus.store()
+
+	return copy(p, m.bus.out), nil
+
+}
+
+// updateGroups splits the Mixer's channels into groups that can be rendered independently of each other, if channels have been linked,
+// unlinked, or had their sends changed since the groups were last built. Channels whose audio reaches any of the same channels (through
+// the channels routed into them and their sends) are grouped together.
+func (m *Mixer) updateGroups() {
+
+	version := graphVersion.Load()
+
+	if m.grouped && m.groupsVersion == version {
+		return
+	}
+
+	m.grouped = true
+	m.groupsVersion = version
+
+	// Each of the Mixer's channels starts out in a group of its own, and groups are merged whenever they reach the same channel.
+	group := make([]int, len(m.channels))
+	for i := range group {
+		group[i] = i
+	}
+
+	find := func(i int) int {
+		for group[i] != i {
+			group[i] = group[group[i]]
+			i = group[i]
+		}
+		return i
+	}
+
+	reached := map[*DSPChannel]int{}
+
+	reach := func(c *DSPChannel, i int) {
+		if j, ok := reached[c]; ok {
+			group[find(j)] = find(i)
+		} else {
+			reached[c] = i
+		}
+	}
+
+	orders := make([][]*DSPChannel, len(m.channels))
+
+	for i, root := range m.channels {
+		orders[i] = root.appendRenderOrder(nil)
+		for _, c := range orders[i] {
+			reach(c, i)
+			for _, send := range c.sendList() {
+				reach(send.target, i)
+			}
+		}
+	}
+
+	graphs := map[int]*renderGraph{}
+	m.groups = m.groups[:0]
+
+	// The groups are rendered in the order they were built in, rather than updating themselves, so they always match the grouping.
+	for i, root := range m.channels {
+		g, ok := graphs[find(i)]
+		if !ok {
+			g = &renderGraph{}
+			graphs[find(i)] = g
+			m.groups = append(m.groups, g)
+		}
+		g.roots = append(g.roots, root)
+		g.order = append(g.order, orders[i]...)
+	}
+
+	for _, g := range m.groups {
+		g.order = sortRenderOrder(g.order)
+	}
+
+}
+
+// Seek does nothing, as a Mixer is a live mix of its Players rather than a stream with a position; it always returns 0.
+// Seek the Mixer's Players individually instead.
+func (m *Mixer) Seek(offset int64, whence int) (int64, error) {
+	return 0, nil
+}
+
+// Close releases the Mixer's channels, so they output into their output channels again, and removes all of the Mixer's Players by pausing them.
+func (m *Mixer) Close() {
+
+	for _, c := range m.channels {
+
+		for _, channel := range c.appendRenderOrder(nil) {
+			for _, player := range channel.PlayingPlayers() {
+				player.Pause()
+			}
+		}
+
+		c.reroute(func() { c.mixer = nil })
+
+	}
+
+}
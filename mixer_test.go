@@ -0,0 +1,91 @@
+package resound
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+// mixSharedSend renders two Mixer buses that both send to a shared effect bus with the given number of workers.
+func mixSharedSend(t *testing.T, workers int) (*Mixer, []byte) {
+
+	a, b, fx := NewDSPChannel(), NewDSPChannel(), NewDSPChannel()
+
+	fx.AddEffect("gain", &testEffect{gain: 0.5})
+	a.AddEffect("gain", &testEffect{gain: 0.8})
+	b.AddEffect("gain", &testEffect{gain: 0.8})
+
+	a.AddSend(fx, 1)
+	b.AddSend(fx, 0.5)
+
+	mixer := NewMixer(a, b, fx).SetWorkers(workers)
+
+	for i, c := range []*DSPChannel{a, b} {
+		player := newPlayer(bytes.NewReader(testSine(44100, 220*float64(i+1), 0.25)))
+		player.SetDSPChannel(c)
+		if err := mixer.Add(player); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := []byte{}
+	buffer := make([]byte, 2048*4)
+
+	for i := 0; i < 20; i++ {
+		n, _ := mixer.Read(buffer)
+		out = append(out, buffer[:n]...)
+	}
+
+	return mixer, out
+
+}
+
+// TestMixerSharedSend checks that buses sharing a send target are rendered together, so the target isn't processed by two
+// goroutines at once (which shows up as a data race with -race).
+func TestMixerSharedSend(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	// The workers should be able to run at the same time, even on a single CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	_, serial := mixSharedSend(t, 1)
+	mixer, parallel := mixSharedSend(t, 4)
+
+	// The buses all reach the effect bus, so they have to be rendered together.
+	if len(mixer.groups) != 1 {
+		t.Errorf("expected the buses to be rendered as 1 group, got %d", len(mixer.groups))
+	}
+
+	if !bytes.Equal(serial, parallel) {
+		t.Error("rendering with 4 workers differs from rendering with 1")
+	}
+
+}
+
+func TestMixerIndependentBuses(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	a, b := NewDSPChannel(), NewDSPChannel()
+	a.AddEffect("gain", &testEffect{gain: 0.5})
+	b.AddEffect("gain", &testEffect{gain: 0.5})
+
+	mixer := NewMixer(a, b).SetWorkers(2)
+
+	for _, c := range []*DSPChannel{a, b} {
+		player := newPlayer(bytes.NewReader(testSine(2048, 440, 0.25)))
+		player.SetDSPChannel(c)
+		mixer.Add(player)
+	}
+
+	buffer := make([]byte, 256*4)
+	for i := 0; i < 4; i++ {
+		mixer.Read(buffer)
+	}
+
+	if len(mixer.groups) != 2 {
+		t.Errorf("expected independent buses to be rendered as 2 groups, got %d", len(mixer.groups))
+	}
+
+}
@@ -2,30 +2,173 @@ package resound
 
 import (
 	"io"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2/audio"
 )
 
+// playingPlayers is a global registry of the Players that have been played and may still be playing.
+var playingPlayers []*Player
+
+// playingPlayersMutex guards the global registry of playing Players.
+var playingPlayersMutex sync.Mutex
+
+// ActiveVoiceCount returns the number of resound.Players that are currently playing across all DSPChannels.
+func ActiveVoiceCount() int {
+	playingPlayersMutex.Lock()
+	defer playingPlayersMutex.Unlock()
+	cleanPlayingPlayers()
+	return len(playingPlayers)
+}
+
+// cleanPlayingPlayers removes any Players that are no longer playing from the global registry. The registry's mutex should be held when calling this.
+func cleanPlayingPlayers() {
+	for i := len(playingPlayers) - 1; i >= 0; i-- {
+		if !playingPlayers[i].IsPlaying() {
+			playingPlayers = append(playingPlayers[:i], playingPlayers[i+1:]...)
+		}
+	}
+}
+
+// StopAllAudio stops all resound.Players that are currently playing by pausing them.
+// If fade is greater than 0, the Players fade out over that duration before pausing, which avoids an audible click.
+// Players faded out this way have their volume restored when they're played again (see Player.FadeOut()).
+func StopAllAudio(fade time.Duration) {
+
+	playingPlayersMutex.Lock()
+	cleanPlayingPlayers()
+	players := make([]*Player, len(playingPlayers))
+	copy(players, playingPlayers)
+	playingPlayersMutex.Unlock()
+
+	for _, p := range players {
+
+		if fade <= 0 {
+			p.Pause()
+			continue
+		}
+
+		p.FadeOut(fade)
+
+	}
+
+}
+
+// Crossfade fades the from Player out while fading the to Player in over the given duration, which is handy for transitioning
+// between pieces of music. The to Player starts playing immediately (if it isn't playing already), and the from Player is paused
+// once it's faded out (see Player.FadeOut()). If the from Player isn't playing (or is nil), the to Player is just faded in,
+// and if the to Player is nil, the from Player is just faded out.
+func Crossfade(from, to *Player, duration time.Duration) {
+
+	if from == to {
+		return
+	}
+
+	if from != nil && from.IsPlaying() {
+		from.FadeOut(duration)
+	}
+
+	if to != nil {
+		to.FadeIn(duration)
+	}
+
+}
+
+// EffectRouting indicates the order in which a Player applies its own effects and the effects of its DSPChannel.
+// By default, a Player's effects are applied to its audio, which is then mixed into its DSPChannel, where the channel's effects
+// process the mix of everything playing through it. With the other routings, the Player processes its own audio with copies of
+// the channel's effects (see IEffect.Clone()), so that they can run before (or in between) the Player's effects; the copies follow
+// the settings of the channel's effects that implement IParameterized, and are remade whenever the channel's effects are changed.
+// The processed audio is mixed into the channel after the channel's effects, so it isn't processed twice, but it still takes on
+// the channel's volume, muting, and ducking (though not its automatic gain or process block size).
+// As each Player has its own copies of the effects, routing many Players this way costs more CPU than the default.
+type EffectRouting int
+
+const (
+	EffectRoutingPlayerThenChannel EffectRouting = iota // The Player's effects are applied first, and then its DSPChannel's. This is the default.
+	EffectRoutingChannelThenPlayer                      // The DSPChannel's effects are applied first, and then the Player's.
+	EffectRoutingCustom                                 // The DSPChannel's effects are applied at the Player's channel insert index (see Player.SetChannelInsertIndex()).
+)
+
 // Player handles playback of audio and effects.
 // Player embeds audio.Player and so has all of the functions and abilities of the default audio.Player
 // while also applying effects either played from its source, through the Player's Effects, or through the
 // Player's DSPChannel.
+// A Player playing through a DSPChannel is mixed into the channel along with the channel's other Players, rather than being played by
+// the audio context itself; its embedded audio.Player is only played when it plays without a channel (see SetDSPChannel()). So,
+// settings of the embedded audio.Player that the Player doesn't handle itself (like its buffer size; see SetBufferSize()) only
+// apply while the Player plays without a channel.
+// Adding, removing, and reordering effects through the Player's functions is safe to do while the Player is playing; modifying
+// the Effects map or EffectOrder slice directly is not.
 type Player struct {
 	*audio.Player
 	DSPChannel *DSPChannel
-	Source     io.ReadSeeker
+	Source     io.ReadSeeker // The Player's source stream; use SetSource() to change it, so the Player's stream effects read from the new one
 
 	EffectOrder []IEffect
 	Effects     map[any]IEffect
+
+	StreamEffectOrder []IStreamEffect
+	StreamEffects     map[any]IStreamEffect
+
+	pan    float64
+	volume float64
+
+	muted bool
+	solo  bool
+
+	playing bool // Whether the Player is playing through its DSPChannel
+
+	registry   *DSPChannel // The DSPChannel the Player was created through with DSPChannel.NewPlayer(), if any
+	registryID any         // The ID the Player is registered under on that channel
+
+	effectRouting        EffectRouting
+	channelInsertIndex   int
+	channelEffects       []IEffect // The Player's own copies of its DSPChannel's effects, for routings that apply them to the Player's audio
+	channelEffectSources []IEffect // The DSPChannel's effects that the copies were made from
+	channelProcessed     bool      // Whether the Player's last buffer was processed with its DSPChannel's effects
+
+	scheduled   bool
+	startSample int64
+
+	fadeGain   float64 // The current gain applied by the Player's built-in fade
+	fadeTarget float64 // The gain the Player's built-in fade is heading towards
+	fadeStep   float64 // How much the fade gain changes each sample
+	fadeID     int     // Incremented with each fade, so a fade out only pauses the Player if it hasn't been interrupted by another fade
+	fadePause  bool    // Whether the Player pauses once its fade reaches silence
+
+	analyzer func(AnalysisFrame)
+
+	position  int64 // The position of the Player's stream, in bytes
+	loopStart int64 // The start of the loop region, in bytes
+	loopEnd   int64 // The end of the loop region, in bytes; if this is 0 or less, the Player doesn't loop
+
+	playbackRate  float64
+	preservePitch bool
+	resampler     *Resampler
+	stretcher     *TimeStretcher
+	rateStage     io.ReadSeeker // The stream the Player last read from to apply its playback rate
+
+	seekBase     int64         // The position (in bytes) the Player was last seeked to, as reported to the underlying audio.Player
+	outputBytes  int64         // The number of bytes the Player has output since it was last seeked
+	length       int64         // The cached length of the Player's stream, in bytes
+	lengthSource io.ReadSeeker // The stream the cached length belongs to
+
+	onEnd func()
+	ended bool // Whether the Player's stream has ended since it was last seeked; guarded by the Player's mutex
+	endID int  // Incremented with each seek, so an end callback doesn't fire if the Player was seeked while it was draining; guarded by the Player's mutex
+
+	mutex       sync.Mutex // Guards the Player's effects and playback state, as they're used by both the game's goroutine and the audio goroutine
+	playMutex   sync.Mutex // Serializes playing and pausing the Player, so a fade out finishing can't pause the Player just after it's been played
+	streamMutex sync.Mutex // Guards the Player's stream (along with its stream effects, playback rate, and loop region), which is read by the audio goroutine and seeked by the game's goroutine
 }
 
 // NewPlayer creates a new Player to playback an io.ReadSeeker-fulfilling audio stream.
 func NewPlayer(sourceStream io.ReadSeeker) (*Player, error) {
 
-	cp := &Player{
-		Source:  sourceStream,
-		Effects: map[any]IEffect{},
-	}
+	cp := newPlayer(sourceStream)
 
 	player, err := audio.CurrentContext().NewPlayer(cp)
 
@@ -39,90 +182,1432 @@ func NewPlayer(sourceStream io.ReadSeeker) (*Player, error) {
 
 }
 
+// newPlayer creates a new Player to play back the given stream through the master channel, without an audio.Player of its own.
+func newPlayer(sourceStream io.ReadSeeker) *Player {
+	return &Player{
+		DSPChannel:    MasterChannel(),
+		Source:        sourceStream,
+		Effects:       map[any]IEffect{},
+		StreamEffects: map[any]IStreamEffect{},
+		volume:        1,
+		fadeGain:      1,
+		fadeTarget:    1,
+		playbackRate:  1,
+	}
+}
+
+// resetProperties restores the Player's properties to their defaults, as though it had just been created, so a pooled Player that's
+// reused doesn't carry over anything that was set on it while it last played: its volume, panning, muting and soloing, effects and
+// effect routing, end callback, analyzer, loop region, playback rate, scheduling, and any fade in progress. Its source, stream effects,
+// DSPChannel, and audio.Player are kept.
+func (p *Player) resetProperties() {
+
+	p.mutex.Lock()
+	p.pan = 0
+	p.muted = false
+	p.solo = false
+	p.Effects = map[any]IEffect{}
+	p.EffectOrder = []IEffect{}
+	p.effectRouting = EffectRoutingPlayerThenChannel
+	p.channelInsertIndex = 0
+	p.onEnd = nil
+	p.analyzer = nil
+	p.fadeID++
+	p.fadeGain = 1
+	p.fadeTarget = 1
+	p.fadeStep = 0
+	p.fadePause = false
+	p.mutex.Unlock()
+
+	p.SetVolume(1)
+
+	p.streamMutex.Lock()
+	p.loopStart = 0
+	p.loopEnd = 0
+	p.playbackRate = 1
+	p.preservePitch = false
+	p.scheduled = false
+	p.streamMutex.Unlock()
+
+}
+
 // NewPlayerFromPlayer creates a new resound.Player from an existing *audio.Player.
+// The Player has no Source of its own until one is set with SetSource(), which also wires up any stream effects added before then.
 func NewPlayerFromPlayer(player *audio.Player) *Player {
 
 	cp := &Player{
-		Player:  player,
-		Effects: map[any]IEffect{},
+		Player:        player,
+		Effects:       map[any]IEffect{},
+		StreamEffects: map[any]IStreamEffect{},
+		volume:        player.Volume(),
+		fadeGain:      1,
+		fadeTarget:    1,
+		playbackRate:  1,
 	}
 
 	return cp
 
 }
 
-// AddEffect adds the specified Effect to the Player, with the given ID.
+// AddEffect adds the specified Effect to the Player, with the given ID. Like with DSPChannels, effects added to a Player
+// don't need to specify source streams, as the Player applies them to its audio directly.
+// If an effect already exists with the given ID, it's replaced, and the new effect is added to the end of the Player's effect order.
 func (p *Player) AddEffect(id any, effect IEffect) *Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	order := p.EffectOrder
+	if existing, ok := p.Effects[id]; ok {
+		order = removeEffect(order, existing)
+	}
 	p.Effects[id] = effect
-	p.EffectOrder = append(p.EffectOrder, effect)
+	p.EffectOrder = append(order[:len(order):len(order)], effect)
+	return p
+}
+
+// RemoveEffect removes the effect with the given ID from the Player. If an effect with the provided ID doesn't exist, this does nothing.
+func (p *Player) RemoveEffect(id any) *Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if effect, ok := p.Effects[id]; ok {
+		delete(p.Effects, id)
+		p.EffectOrder = removeEffect(p.EffectOrder, effect)
+	}
+	return p
+}
+
+// MoveEffect moves the effect with the given ID to the given index in the Player's effect order, which is the order the
+// effects are applied in (e.g. a delay before a lowpass filter sounds different from a lowpass filter before a delay).
+// The index is clamped to the bounds of the effect order. If an effect with the provided ID doesn't exist, this does nothing.
+func (p *Player) MoveEffect(id any, toIndex int) *Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if effect, ok := p.Effects[id]; ok {
+		p.EffectOrder = moveEffect(p.EffectOrder, effect, toIndex)
+	}
+	return p
+}
+
+// ClearEffects removes all effects from the Player.
+func (p *Player) ClearEffects() *Player {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Effects = map[any]IEffect{}
+	p.EffectOrder = []IEffect{}
 	return p
 }
 
+// ResetEffects clears the internal state of each of the Player's effects that holds any (i.e. that implements IResettable),
+// including its copies of its DSPChannel's effects (see EffectRouting). Note that this doesn't reset the effects of the Player's
+// DSPChannel itself, as they're shared with other Players.
+func (p *Player) ResetEffects() {
+	p.streamMutex.Lock()
+	p.resetEffects()
+	p.streamMutex.Unlock()
+}
+
+// resetEffects resets the Player's effects, as ResetEffects() does. The Player's stream mutex should be held when calling this.
+func (p *Player) resetEffects() {
+	for _, effect := range p.effectOrder() {
+		if resettable, ok := effect.(IResettable); ok {
+			resettable.Reset()
+		}
+	}
+	for _, effect := range p.channelEffects {
+		if resettable, ok := effect.(IResettable); ok {
+			resettable.Reset()
+		}
+	}
+}
+
 // Effect returns the effect associated with the given id.
 // If an effect with the provided ID doesn't exist, this function will return nil.
 func (p *Player) Effect(id any) IEffect {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	return p.Effects[id]
 }
 
-// SetDSPChannel sets the DSPChannel to be used for playing audio back through the Player.
+// effectOrder returns the Player's current effect order. Like a DSPChannel's, the effect order is never modified in place,
+// so the returned slice can be iterated over safely without holding the lock.
+func (p *Player) effectOrder() []IEffect {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.EffectOrder
+}
+
+// effectMap returns a copy of the Player's Effects map, so it can be iterated over without holding the lock.
+// Unlike the effect order, the map is modified in place when effects are added or removed.
+func (p *Player) effectMap() map[any]IEffect {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return copyEffectMap(p.Effects)
+}
+
+// AddStreamEffect adds the specified stream effect to the Player, with the given ID.
+// Stream effects can change the length of the audio stream (like resampling or time-stretching), so they are applied to
+// the Player's Source before any ordinary effects, in the order they're added. Each stream effect reads from the one before it,
+// with the first reading from the Player's Source.
+// Note that stream effects can't be added to a DSPChannel, as they need to control how audio is read from the source.
+func (p *Player) AddStreamEffect(id any, effect IStreamEffect) *Player {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	p.StreamEffects[id] = effect
+	p.StreamEffectOrder = append(p.StreamEffectOrder, effect)
+	p.wireStreamEffects()
+	return p
+}
+
+// StreamEffect returns the stream effect associated with the given id.
+// If a stream effect with the provided ID doesn't exist, this function will return nil.
+func (p *Player) StreamEffect(id any) IStreamEffect {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	return p.StreamEffects[id]
+}
+
+// SetSource sets the source stream the Player plays, re-wiring the Player's stream effects so the first reads from the new source.
+// Audio the Player's playback rate stage buffered from the old source is discarded. The Player carries on from the new source's
+// current position; rewind the Player (see Rewind()) to play the new source from the start.
+func (p *Player) SetSource(source io.ReadSeeker) *Player {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	p.Source = source
+	p.wireStreamEffects()
+	p.rateStage = nil
+	p.lengthSource = nil
+	return p
+}
+
+// wireStreamEffects sets the sources of the Player's stream effects so that each reads from the one before it.
+// The Player's stream mutex should be held when calling this.
+func (p *Player) wireStreamEffects() {
+	var source io.ReadSeeker = p.Source
+	for _, effect := range p.StreamEffectOrder {
+		effect.SetSource(source)
+		source = effect
+	}
+}
+
+// stream returns the stream the Player should read from - either the last stream effect, or the Player's Source.
+func (p *Player) stream() io.ReadSeeker {
+	if len(p.StreamEffectOrder) > 0 {
+		return p.StreamEffectOrder[len(p.StreamEffectOrder)-1]
+	}
+	return p.Source
+}
+
+// Play plays the Player's audio. If the Player has a DSPChannel set, it's mixed into the channel; otherwise, it's played by the audio context directly.
+func (p *Player) Play() {
+
+	p.playMutex.Lock()
+	defer p.playMutex.Unlock()
+
+	p.mutex.Lock()
+	channel := p.DSPChannel
+	p.playing = channel != nil
+	// Playing the Player cancels any fade out, restoring its volume.
+	if p.fadeTarget == 0 {
+		p.fadeID++
+		p.fadeGain = 1
+		p.fadeTarget = 1
+		p.fadeStep = 0
+		p.fadePause = false
+	}
+	p.mutex.Unlock()
+
+	if channel != nil {
+		channel.addVoice(p)
+	} else if p.Player != nil {
+		p.Player.Play()
+	}
+
+	playingPlayersMutex.Lock()
+	defer playingPlayersMutex.Unlock()
+
+	cleanPlayingPlayers()
+	for _, other := range playingPlayers {
+		if other == p {
+			return
+		}
+	}
+	playingPlayers = append(playingPlayers, p)
+
+}
+
+// PlayAtSample plays the Player's audio once the global sample clock reaches the given sample position.
+// The Player starts playing immediately, but outputs silence until the scheduled sample, where its audio
+// starts partway through the buffer being read for sample-accurate timing.
+// An error is returned if the sample clock couldn't be started.
+func (p *Player) PlayAtSample(samplePos int64) error {
+
+	if err := clock.start(); err != nil {
+		return err
+	}
+
+	p.scheduled = true
+	p.startSample = samplePos
+	p.Play()
+
+	return nil
+
+}
+
+// SetDSPChannel sets the DSPChannel to be used for playing audio back through the Player. By default, Players play through
+// the master channel (see MasterChannel()); channels that aren't routed elsewhere are routed into the master channel, so setting
+// a channel here still applies the master channel's effects and volume afterwards. Passing nil plays the Player without any channel
+// processing at all, bypassing the master channel as well. If the Player is playing, it moves to the new channel immediately.
 func (p *Player) SetDSPChannel(c *DSPChannel) *Player {
+
+	p.playMutex.Lock()
+	defer p.playMutex.Unlock()
+
+	p.mutex.Lock()
+	old := p.DSPChannel
 	p.DSPChannel = c
+	playing := p.playing
+	p.mutex.Unlock()
+
+	if old == c {
+		return p
+	}
+
+	if old != nil {
+		old.removeVoice(p)
+	} else if p.Player != nil && p.Player.IsPlaying() {
+		p.Player.Pause()
+		playing = true
+	}
+
+	if !playing {
+		return p
+	}
+
+	if c != nil {
+		p.mutex.Lock()
+		p.playing = true
+		p.mutex.Unlock()
+		c.addVoice(p)
+	} else {
+		p.mutex.Lock()
+		p.playing = false
+		p.mutex.Unlock()
+		if p.Player != nil {
+			p.Player.Play()
+		}
+	}
+
+	return p
+
+}
+
+// Pause pauses the Player's audio.
+func (p *Player) Pause() {
+	p.playMutex.Lock()
+	defer p.playMutex.Unlock()
+	p.pause()
+}
+
+// pause pauses the Player's audio. The Player's play mutex should be held when calling this.
+func (p *Player) pause() {
+
+	p.mutex.Lock()
+	channel := p.DSPChannel
+	p.playing = false
+	p.mutex.Unlock()
+
+	if channel != nil {
+		channel.removeVoice(p)
+	}
+
+	if p.Player != nil {
+		p.Player.Pause()
+	}
+
+}
+
+// IsPlaying returns if the Player is playing.
+func (p *Player) IsPlaying() bool {
+
+	p.mutex.Lock()
+	channel, playing := p.DSPChannel, p.playing
+	p.mutex.Unlock()
+
+	if channel != nil {
+		return playing
+	}
+
+	return p.Player != nil && p.Player.IsPlaying()
+
+}
+
+// voiceEnded marks the Player as no longer playing once its stream has ended while playing through the given DSPChannel.
+func (p *Player) voiceEnded(channel *DSPChannel) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.DSPChannel == channel {
+		p.playing = false
+	}
+}
+
+// SetVolume sets the volume of the Player, where 1 is the original volume and 0 is silent. A Player playing through a DSPChannel
+// is scaled by its volume as it's mixed into the channel, ramping to the new volume over a buffer to avoid clicks.
+func (p *Player) SetVolume(volume float64) {
+
+	p.mutex.Lock()
+	p.volume = volume
+	p.mutex.Unlock()
+
+	if p.Player != nil {
+		p.Player.SetVolume(volume)
+	}
+
+}
+
+// Volume returns the volume of the Player.
+func (p *Player) Volume() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.volume
+}
+
+// mixGain returns the gain the Player is mixed into its DSPChannel with: its volume, or 0 if it's muted, or if it isn't soloed while
+// another Player playing through the channel is (as indicated by solo).
+func (p *Player) mixGain(solo bool) float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.muted || (solo && !p.solo) {
+		return 0
+	}
+	return p.volume
+}
+
+// Rewind rewinds the Player to the start of its stream. This is the same as SetPosition(0).
+func (p *Player) Rewind() error {
+	return p.SetPosition(0)
+}
+
+// SetPosition seeks the Player to the given position in its stream.
+func (p *Player) SetPosition(offset time.Duration) error {
+
+	p.mutex.Lock()
+	channel := p.DSPChannel
+	p.mutex.Unlock()
+
+	// The audio.Player seeks the Player itself (through Seek()), clearing any audio it's buffered.
+	if channel == nil && p.Player != nil {
+		return p.Player.SetPosition(offset)
+	}
+
+	_, err := p.Seek(int64(offset.Seconds()*float64(p.bytesPerSecond()))/4*4, io.SeekStart)
+	return err
+
+}
+
+// Close stops the Player and closes its underlying audio.Player. If the Player was created through DSPChannel.NewPlayer(),
+// it's removed from that channel's Players, so DSPChannel.Player() no longer returns it.
+func (p *Player) Close() error {
+
+	p.Pause()
+
+	p.mutex.Lock()
+	registry, id := p.registry, p.registryID
+	p.registry, p.registryID = nil, nil
+	p.mutex.Unlock()
+
+	if registry != nil {
+		registry.unregisterPlayer(id, p)
+	}
+
+	if p.Player == nil {
+		return nil
+	}
+
+	return p.Player.Close()
+
+}
+
+// closeOnChannel closes the Player and releases its source, as the DSPChannel it's playing through has been closed.
+func (p *Player) closeOnChannel() {
+	p.Close()
+	p.SetSource(nil)
+}
+
+// SetBufferSize sets the buffer size of the Player's embedded audio.Player (see audio.Player.SetBufferSize()), which only applies
+// while the Player plays without a DSPChannel. Players playing through a DSPChannel are mixed into the master channel's stream,
+// so their buffer size is set for all of them at once with SetMasterBufferSize().
+func (p *Player) SetBufferSize(bufferSize time.Duration) *Player {
+	if p.Player != nil {
+		p.Player.SetBufferSize(bufferSize)
+	}
+	return p
+}
+
+// SetPan sets the panning of the Player, ranging from -1 (hard left) to 1 (hard right), with 0 being the center.
+// The Player's panning is applied after all of the Player's effects, before the Player is mixed into its DSPChannel (so before the
+// channel's effects), and is independent of any Pan effect.
+func (p *Player) SetPan(pan float64) *Player {
+	p.mutex.Lock()
+	p.pan = clamp(pan, -1, 1)
+	p.mutex.Unlock()
+	return p
+}
+
+// Pan returns the panning of the Player, ranging from -1 (hard left) to 1 (hard right).
+func (p *Player) Pan() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.pan
+}
+
+// SetMuted sets whether the Player is muted. A muted Player keeps playing silently, so its stream keeps advancing and stays in
+// sync with other Players (like the layers of a piece of music); unmuting it brings it back in at the point it's reached.
+// The Player is silenced as it's mixed into its DSPChannel, ramping its volume over a buffer to avoid clicks; as this happens before
+// the channel's effects, any echoes or reverb tails the channel is still playing die away naturally. A Player that isn't playing through
+// a DSPChannel is silenced at the end of its processing.
+func (p *Player) SetMuted(muted bool) *Player {
+	p.mutex.Lock()
+	p.muted = muted
+	p.mutex.Unlock()
+	return p
+}
+
+// Muted returns whether the Player is muted.
+func (p *Player) Muted() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.muted
+}
+
+// SetSolo sets whether the Player is soloed. While any Player playing through a DSPChannel is soloed, the channel silences
+// the Players playing through it that aren't, so only the soloed Players are heard; as with muting, the silenced Players keep
+// advancing. Soloing only affects the Players playing directly through the same DSPChannel (see DSPChannel.PlayingPlayers()),
+// not those playing through other channels routed into it. A Player that's both soloed and muted is silent.
+func (p *Player) SetSolo(solo bool) *Player {
+	p.mutex.Lock()
+	p.solo = solo
+	p.mutex.Unlock()
 	return p
 }
 
+// Solo returns whether the Player is soloed.
+func (p *Player) Solo() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.solo
+}
+
 // CopyProperties copies the properties (effects, current DSP Channel, etc) from one resound.Player to the other.
+// Each effect is cloned using its Clone() function rather than shared, so the two Players' effects don't share state
+// (like delay buffers or filter history); effects the other Player already has under the same IDs are replaced.
 // Note that this won't duplicate the current state of playback of the internal audio stream.
+// Stream effects aren't copied, as each one reads directly from the stream of the Player it's added to.
 func (p *Player) CopyProperties(other *Player) *Player {
 
-	for k, v := range p.Effects {
-		other.Effects[k] = v
+	effects, order := p.cloneEffects()
+
+	ids := make(map[IEffect]any, len(effects))
+	for id, effect := range effects {
+		ids[effect] = id
+	}
+
+	// The clones are added in the original order, so they're applied in the same order on the other Player.
+	for _, effect := range order {
+		if id, ok := ids[effect]; ok {
+			other.AddEffect(id, effect)
+		}
 	}
-	other.EffectOrder = append(other.EffectOrder, p.EffectOrder...)
 
-	other.DSPChannel = p.DSPChannel
+	p.mutex.Lock()
+	channel, pan, routing, insertIndex := p.DSPChannel, p.pan, p.effectRouting, p.channelInsertIndex
+	p.mutex.Unlock()
+
+	other.SetDSPChannel(channel)
+	other.SetPan(pan)
+	other.SetPlaybackRate(p.PlaybackRate())
+	other.SetPreservePitch(p.PreservePitch())
+
+	other.mutex.Lock()
+	other.effectRouting = routing
+	other.channelInsertIndex = insertIndex
+	other.mutex.Unlock()
 
 	return p
 
 }
 
+// Clone returns a new Player with the same properties as the original (its effects, DSPChannel, volume, muting, soloing, pan,
+// playback rate, loop region, and so on). Each of the original's effects is cloned using its Clone() function, so the clone's effects
+// don't share state (like delay buffers or filter history) with the original's. Stream effects aren't cloned. The original's analyzer
+// and end callback aren't carried over either, as they're usually tied to the Player they were set on; set them on the clone as needed.
+// The clone starts paused at the beginning of the stream. Note that the clone plays the same Source as the original, and
+// an io.ReadSeeker can't be read from two places at once; if both Players will play at the same time, give the clone an
+// independent stream of its own (like a fresh decode of the same file) with SetSource().
+func (p *Player) Clone() (*Player, error) {
+
+	p.streamMutex.Lock()
+	source := p.Source
+	p.streamMutex.Unlock()
+
+	clone := newPlayer(source)
+
+	// The clone only gets an audio.Player of its own if the original has one.
+	if p.Player != nil {
+		player, err := audio.CurrentContext().NewPlayer(clone)
+		if err != nil {
+			return nil, err
+		}
+		clone.Player = player
+	}
+
+	clone.Effects, clone.EffectOrder = p.cloneEffects()
+
+	p.mutex.Lock()
+	clone.DSPChannel = p.DSPChannel
+	clone.pan = p.pan
+	clone.muted = p.muted
+	clone.solo = p.solo
+	clone.effectRouting = p.effectRouting
+	clone.channelInsertIndex = p.channelInsertIndex
+	volume := p.volume
+	p.mutex.Unlock()
+
+	p.streamMutex.Lock()
+	clone.playbackRate = p.playbackRate
+	clone.preservePitch = p.preservePitch
+	clone.loopStart = p.loopStart
+	clone.loopEnd = p.loopEnd
+	p.streamMutex.Unlock()
+
+	clone.SetVolume(volume)
+
+	return clone, nil
+
+}
+
+// cloneEffects returns clones of the Player's effects, keyed by the same IDs and in the same order as the originals.
+func (p *Player) cloneEffects() (map[any]IEffect, []IEffect) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	clones := make(map[IEffect]IEffect, len(p.EffectOrder))
+	order := make([]IEffect, 0, len(p.EffectOrder))
+
+	for _, effect := range p.EffectOrder {
+		clone := effect.Clone()
+		clones[effect] = clone
+		order = append(order, clone)
+	}
+
+	effects := make(map[any]IEffect, len(p.Effects))
+	for id, effect := range p.Effects {
+		effects[id] = clones[effect]
+	}
+
+	return effects, order
+
+}
+
+// Read reads the Player's audio, with its effects, panning, and fading applied. A Player playing through a DSPChannel is read by the
+// channel as it's mixed, while one playing without a channel is read by the audio context.
 func (p *Player) Read(bytes []byte) (n int, err error) {
 
-	if p.DSPChannel != nil {
+	p.streamMutex.Lock()
+	n, err = p.read(bytes)
+	p.streamMutex.Unlock()
+
+	p.mutex.Lock()
+	analyzer := p.analyzer
+	p.mutex.Unlock()
+
+	// The analyzer is called once the stream has been released, so it can safely call the Player's functions.
+	if analyzer != nil && n > 0 {
+		analyzer(newAnalysisFrame(p, bytes, n))
+	}
+
+	return
+
+}
+
+// read reads and processes the Player's audio. The Player's stream mutex should be held when calling this.
+func (p *Player) read(bytes []byte) (n int, err error) {
+
+	if p.Source == nil {
+		return 0, io.EOF
+	}
+
+	offset := 0
 
-		if !p.DSPChannel.Active {
+	if p.scheduled {
+
+		now := clock.elapsed()
+		frames := int64(len(bytes) / 4)
+
+		// It's not time to play yet, so output silence.
+		if p.startSample >= now+frames {
+			n = int(frames) * 4
+			for i := 0; i < n; i++ {
+				bytes[i] = 0
+			}
 			return
-		} else if p.DSPChannel.closed {
-			p.Close() // Close player if the DSPChannel it's playing on is also closed
-			p.Source = nil
-			return 0, io.EOF
 		}
 
+		p.scheduled = false
+
+		// Start partway through the buffer, with silence leading up to the scheduled sample.
+		if p.startSample > now {
+			offset = int(p.startSample-now) * 4
+			for i := 0; i < offset; i++ {
+				bytes[i] = 0
+			}
+		}
+
+	}
+
+	n, err = p.rateStream().Read(bytes[offset:])
+	n += offset
+
+	p.outputBytes += int64(n)
+
+	if err == io.EOF {
+		p.signalEnd()
+	}
+
+	// If the source returned less than the full buffer (e.g. at the end of a short sound), zero the rest of the buffer
+	// so that stale audio from a previous read doesn't leak through as a click.
+	for i := n; i < len(bytes); i++ {
+		bytes[i] = 0
 	}
 
-	if n, err = p.Source.Read(bytes); err != nil {
+	// Sources can return the last bit of audio along with io.EOF, so effects still need to be applied in that case.
+	if err != nil && (err != io.EOF || n == 0) {
 		return
 	}
 
-	for _, effect := range p.EffectOrder {
-		effect.ApplyEffect(bytes, n)
+	p.applyEffects(bytes, n)
+
+	p.applyFinalStage(bytes, n)
+
+	return
+
+}
+
+// SetOnEnd sets a callback that's called once the Player's stream has ended and the last of its audio has finished playing.
+// The callback is called from its own goroutine, not the audio thread, so it's safe to call Player functions from it (though
+// as with any goroutine, take care when touching game state from it).
+// The callback fires once each time the stream ends; seeking the Player (e.g. rewinding it with Rewind()) re-arms it, so
+// it fires again if the Player is replayed to the end. A Player that loops (with audio.InfiniteLoop or a loop region) never ends.
+// Passing nil removes the callback.
+func (p *Player) SetOnEnd(onEnd func()) *Player {
+	p.mutex.Lock()
+	p.onEnd = onEnd
+	p.mutex.Unlock()
+	return p
+}
+
+// signalEnd is called when the Player's stream returns io.EOF, scheduling the Player's end callback the first time the stream ends
+// after a seek. A Player playing through a DSPChannel has had its last audio mixed by the time its stream ends, so the callback is
+// called right away; otherwise, it's called once the audio.Player has played the audio it's buffered.
+func (p *Player) signalEnd() {
+
+	p.mutex.Lock()
+	if p.ended {
+		p.mutex.Unlock()
+		return
+	}
+	p.ended = true
+	onEnd, id, channel := p.onEnd, p.endID, p.DSPChannel
+	p.mutex.Unlock()
+
+	if onEnd == nil {
+		return
+	}
+
+	if channel != nil || p.Player == nil {
+		go p.callOnEnd(onEnd, id)
+	} else {
+		go p.drainEnd(onEnd, id)
+	}
+
+}
+
+// drainEnd calls the given end callback once the audio the audio.Player has buffered from the Player has been played.
+// This is called from its own goroutine, as the audio.Player is locked while it reads the Player.
+func (p *Player) drainEnd(onEnd func(), id int) {
+
+	heard := p.Player.Position()
+
+	p.streamMutex.Lock()
+	read := time.Duration(float64(p.seekBase+p.outputBytes) / float64(p.bytesPerSecond()) * float64(time.Second))
+	p.streamMutex.Unlock()
+
+	time.AfterFunc(read-heard, func() { p.callOnEnd(onEnd, id) })
+
+}
+
+// callOnEnd calls the given end callback, as long as the Player hasn't been seeked since its stream ended.
+func (p *Player) callOnEnd(onEnd func(), id int) {
+
+	p.mutex.Lock()
+	current := p.endID == id
+	p.mutex.Unlock()
+
+	if current {
+		onEnd()
+	}
+
+}
+
+// applyEffects applies the Player's effects to the given buffer, in order, along with copies of its DSPChannel's effects
+// if its effect routing calls for them (see EffectRouting). The Player's stream mutex should be held when calling this.
+func (p *Player) applyEffects(data []byte, bytesRead int) {
+
+	effects := p.effectOrder()
+
+	p.mutex.Lock()
+	channel, routing, insertIndex := p.DSPChannel, p.effectRouting, p.channelInsertIndex
+	p.mutex.Unlock()
+
+	p.channelProcessed = channel != nil && routing != EffectRoutingPlayerThenChannel
+
+	if !p.channelProcessed {
+		p.channelEffects, p.channelEffectSources = nil, nil
+		for _, effect := range effects {
+			effect.ApplyEffect(data, bytesRead)
+		}
+		return
+	}
+
+	if routing == EffectRoutingChannelThenPlayer {
+		insertIndex = 0
+	}
+
+	insertIndex = int(clamp(float64(insertIndex), 0, float64(len(effects))))
+
+	channelEffects := p.channelEffectCopies(channel)
+
+	for i := 0; i <= len(effects); i++ {
+		if i == insertIndex {
+			for _, effect := range channelEffects {
+				effect.ApplyEffect(data, bytesRead)
+			}
+		}
+		if i < len(effects) {
+			effects[i].ApplyEffect(data, bytesRead)
+		}
+	}
+
+}
+
+// channelEffectCopies returns the Player's copies of the given DSPChannel's effects, remaking them if the channel's effects have
+// changed since they were made, and otherwise updating their settings to match. The Player's stream mutex should be held when calling this.
+func (p *Player) channelEffectCopies(channel *DSPChannel) []IEffect {
+
+	order := channel.effectOrder()
+
+	if !sameEffects(order, p.channelEffectSources) {
+		p.channelEffectSources = order
+		p.channelEffects = make([]IEffect, len(order))
+		for i, effect := range order {
+			p.channelEffects[i] = effect.Clone()
+		}
+		return p.channelEffects
+	}
+
+	for i, effect := range order {
+		source, ok := effect.(IParameterized)
+		if !ok {
+			continue
+		}
+		if copied, ok := p.channelEffects[i].(IParameterized); ok {
+			copied.SetParameters(source.Parameters())
+		}
+	}
+
+	return p.channelEffects
+
+}
+
+// processedByChannel returns if the Player's last buffer was processed with copies of its DSPChannel's effects, and so
+// shouldn't be processed by the channel's effects again.
+func (p *Player) processedByChannel() bool {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	return p.channelProcessed
+}
+
+// SetEffectRouting sets the order in which the Player applies its own effects and the effects of its DSPChannel (see EffectRouting).
+// Defaults to EffectRoutingPlayerThenChannel.
+func (p *Player) SetEffectRouting(routing EffectRouting) *Player {
+	p.mutex.Lock()
+	p.effectRouting = routing
+	p.mutex.Unlock()
+	return p
+}
+
+// EffectRouting returns the order in which the Player applies its own effects and the effects of its DSPChannel.
+func (p *Player) EffectRouting() EffectRouting {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.effectRouting
+}
+
+// SetChannelInsertIndex sets the index in the Player's effect order at which the effects of its DSPChannel are applied when its
+// effect routing is set to EffectRoutingCustom; the channel's effects are applied before the Player's effect at that index.
+// 0 applies them before all of the Player's effects, and the length of the Player's effect order (or more) applies them after.
+func (p *Player) SetChannelInsertIndex(index int) *Player {
+	p.mutex.Lock()
+	p.channelInsertIndex = index
+	p.mutex.Unlock()
+	return p
+}
+
+// ChannelInsertIndex returns the index in the Player's effect order at which the effects of its DSPChannel are applied when its
+// effect routing is set to EffectRoutingCustom.
+func (p *Player) ChannelInsertIndex() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.channelInsertIndex
+}
+
+// SetAnalyzer sets a callback that receives the levels of each buffer of audio the Player plays, after the Player's effects, panning,
+// and fading have been applied; the processing of its DSPChannel applies to the channel's whole mix, so it isn't included (see
+// DSPChannel.SetAnalyzer()). The callback runs in the audio thread, so it should return quickly. Passing nil removes the analyzer.
+func (p *Player) SetAnalyzer(analyzer func(AnalysisFrame)) *Player {
+	p.mutex.Lock()
+	p.analyzer = analyzer
+	p.mutex.Unlock()
+	return p
+}
+
+// playerStream allows the Player's stream (including looping) to be read and seeked as an io.ReadSeeker, so the Player's
+// playback rate can be applied on top of it.
+type playerStream struct {
+	player *Player
+}
+
+func (s playerStream) Read(bytes []byte) (int, error) {
+	return s.player.readStream(bytes)
+}
+
+func (s playerStream) Seek(offset int64, whence int) (int64, error) {
+	pos, err := s.player.stream().Seek(offset, whence)
+	if err == nil {
+		s.player.position = pos
+	}
+	return pos, err
+}
+
+// rateStream returns the stream the Player should read from to apply its playback rate - either a resampler, a TimeStretcher
+// (if the Player preserves its pitch), or the Player's stream directly if the playback rate is 1.
+// The Player's stream mutex should be held when calling this.
+func (p *Player) rateStream() io.ReadSeeker {
+
+	var stage io.ReadSeeker = playerStream{p}
+
+	if p.playbackRate != 1 {
+
+		if p.preservePitch {
+			if p.stretcher == nil {
+				p.stretcher = NewTimeStretcher()
+				p.stretcher.SetSource(playerStream{p})
+			}
+			p.stretcher.SetStretch(1 / p.playbackRate)
+			stage = p.stretcher
+		} else {
+			if p.resampler == nil {
+				p.resampler = newResampler(playerStream{p}, p.playbackRate)
+			}
+			p.resampler.rate = p.playbackRate
+			stage = p.resampler
+		}
+
+	}
+
+	// When switching to a different stage, any audio it buffered when it was last used is stale, so it's cleared.
+	if stage != p.rateStage {
+		switch s := stage.(type) {
+		case *Resampler:
+			s.Reset()
+		case *TimeStretcher:
+			s.Reset()
+		}
+		p.rateStage = stage
+	}
+
+	return stage
+
+}
+
+// SetPlaybackRate sets how quickly the Player plays its stream; 2 plays it at double speed, while 0.5 plays it at half speed.
+// By default, this changes the pitch of the audio as well (like speeding up or slowing down a record); use SetPreservePitch() to
+// change the speed without changing the pitch. The rate is clamped from 0.25 to 4.
+// Seeking, loop regions, and Position() all remain relative to the stream's original timeline.
+func (p *Player) SetPlaybackRate(rate float64) *Player {
+	p.streamMutex.Lock()
+	p.playbackRate = clamp(rate, 0.25, 4)
+	p.streamMutex.Unlock()
+	return p
+}
+
+// PlaybackRate returns how quickly the Player plays its stream.
+func (p *Player) PlaybackRate() float64 {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	return p.playbackRate
+}
+
+// SetPreservePitch sets whether the Player preserves the pitch of its audio when its playback rate isn't 1. When enabled, the Player
+// time-stretches its audio (see TimeStretcher) rather than resampling it, which changes the speed without making it sound higher or lower.
+// Note that this adds a small amount of latency (see TimeStretcher.Latency()).
+func (p *Player) SetPreservePitch(preserve bool) *Player {
+	p.streamMutex.Lock()
+	p.preservePitch = preserve
+	p.streamMutex.Unlock()
+	return p
+}
+
+// PreservePitch returns whether the Player preserves the pitch of its audio when its playback rate isn't 1.
+func (p *Player) PreservePitch() bool {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	return p.preservePitch
+}
+
+// readStream reads from the Player's stream into the given buffer, looping within the loop region if one has been set.
+func (p *Player) readStream(bytes []byte) (n int, err error) {
+
+	if p.loopEnd <= p.loopStart {
+		n, err = p.stream().Read(bytes)
+		p.position += int64(n)
+		return
 	}
 
-	if p.DSPChannel != nil {
-		for _, effect := range p.DSPChannel.EffectOrder {
-			effect.ApplyEffect(bytes, n)
+	for n < len(bytes) {
+
+		if p.position >= p.loopEnd {
+			if err = p.seekLoopStart(); err != nil {
+				return
+			}
+		}
+
+		// Read no further than the end of the loop region, so the loop is sample-accurate.
+		chunk := bytes[n:]
+		if remaining := p.loopEnd - p.position; int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		var read int
+		read, err = p.stream().Read(chunk)
+		n += read
+		p.position += int64(read)
+
+		if err == io.EOF {
+
+			// If the stream ends before the end of the loop region, loop from there instead - unless nothing
+			// was read since looping back, in which case the loop region lies past the end of the stream.
+			if read == 0 && p.position == p.loopStart {
+				return
+			}
+
+			err = nil
+
+			if seekErr := p.seekLoopStart(); seekErr != nil {
+				return n, seekErr
+			}
+
+		} else if err != nil {
+			return
+		} else if read == 0 {
+			break
 		}
+
 	}
 
 	return
 
 }
 
+func (p *Player) seekLoopStart() error {
+	pos, err := p.stream().Seek(p.loopStart, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	p.position = pos
+	return nil
+}
+
+// Position returns the playback position of the Player in its stream's original timeline. Unlike audio.Player.Position(), this takes
+// the Player's playback rate and loop region into account, so it stays accurate when those are used. The position accounts for audio
+// that has been read but is still waiting in the underlying audio.Player's buffer to be heard.
+func (p *Player) Position() time.Duration {
+
+	p.mutex.Lock()
+	channel := p.DSPChannel
+	p.mutex.Unlock()
+
+	// How much audio has been read but not heard yet is found before locking the stream, as the audio.Player locks itself while reading it.
+	var heard, mixBuffered float64
+	if channel != nil {
+		mixBuffered = float64(channel.bufferedBytes())
+	} else if p.Player != nil {
+		heard = p.Player.Position().Seconds() * float64(p.bytesPerSecond())
+	}
+
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+
+	pos := float64(p.position)
+
+	var buffered float64
+
+	if channel != nil {
+		// A Player playing through a DSPChannel has been mixed into the channel's stream, which has buffered audio of its own.
+		buffered = math.Min(mixBuffered, float64(p.outputBytes))
+	} else if p.Player != nil {
+		// The audio.Player reports its position as the number of bytes it's read from this Player (starting from the last seek)
+		// minus however many bytes it has buffered but not played yet; those buffered bytes are still to come in the stream.
+		buffered = float64(p.seekBase+p.outputBytes) - heard
+	}
+
+	if buffered > 0 {
+		pos -= buffered * p.playbackRate
+	}
+
+	// If the buffered audio crosses the loop point, the position heard is back before the end of the loop region.
+	if p.loopEnd > p.loopStart && pos < float64(p.loopStart) && p.position >= p.loopStart {
+		pos += float64(p.loopEnd - p.loopStart)
+	}
+
+	if pos < 0 {
+		pos = 0
+	}
+
+	return time.Duration(pos / float64(p.bytesPerSecond()) * float64(time.Second))
+
+}
+
+// Duration returns the length of the Player's stream. If the Player has a loop region, the Duration is capped at the end of the region,
+// as playback never goes past it. The length is taken from the stream's Length() function if it has one (like the streams decoded by
+// Ebitengine's audio packages), and otherwise by seeking to the end of the stream and back; it's cached for each stream.
+// If the length can't be determined (for example, with an audio.InfiniteLoop), Duration returns the end of the loop region, or 0 if there isn't one.
+func (p *Player) Duration() time.Duration {
+
+	p.streamMutex.Lock()
+	length := p.streamLength()
+	if p.loopEnd > p.loopStart && (length < 0 || p.loopEnd < length) {
+		length = p.loopEnd
+	}
+	p.streamMutex.Unlock()
+
+	if length < 0 {
+		return 0
+	}
+
+	return time.Duration(float64(length) / float64(p.bytesPerSecond()) * float64(time.Second))
+
+}
+
+// streamLength returns the length of the Player's stream in bytes, or -1 if it can't be determined.
+// The Player's stream mutex should be held when calling this.
+func (p *Player) streamLength() int64 {
+
+	stream := p.stream()
+
+	if stream == nil {
+		return -1
+	}
+
+	if stream == p.lengthSource {
+		return p.length
+	}
+
+	p.lengthSource = stream
+	p.length = -1
+
+	if lengther, ok := stream.(interface{ Length() int64 }); ok {
+		p.length = lengther.Length()
+		return p.length
+	}
+
+	current, err := stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return p.length
+	}
+
+	if end, err := stream.Seek(0, io.SeekEnd); err == nil {
+		p.length = end
+	}
+
+	stream.Seek(current, io.SeekStart)
+
+	return p.length
+
+}
+
+func (p *Player) bytesPerSecond() int {
+	return SampleRate() * 4
+}
+
+// SetLoopRegion sets the Player to loop a region of its stream, from the start time to the end time, which is useful for music with an intro
+// that plays once before the rest of the track loops. When playback reaches the end of the loop region (or the end of the stream, if that comes
+// first), it jumps back to the start of the region, accurate to the sample. The jump itself doesn't click, but the audio on either side of the
+// loop points should match up for the loop to sound seamless.
+// Effects aren't reset when looping, so echoes and reverb tails carry across the loop point; seeking the Player (e.g. with Rewind()) works as normal,
+// and if the Player is seeked past the end of the loop region, it jumps back to the start of the region as soon as it plays.
+// If end is less than or equal to start, the Player doesn't loop.
+func (p *Player) SetLoopRegion(start, end time.Duration) *Player {
+	bytesPerSecond := float64(SampleRate() * 4)
+	return p.SetLoopRegionBytes(int64(start.Seconds()*bytesPerSecond), int64(end.Seconds()*bytesPerSecond))
+}
+
+// SetLoopRegionBytes sets the Player to loop a region of its stream like SetLoopRegion(), but with the start and end given as byte offsets into the stream.
+// The offsets are rounded down to the start of a frame (a multiple of 4 bytes, as the stream is stereo 16-bit audio).
+func (p *Player) SetLoopRegionBytes(start, end int64) *Player {
+	if start < 0 {
+		start = 0
+	}
+	p.streamMutex.Lock()
+	p.loopStart = start / 4 * 4
+	p.loopEnd = end / 4 * 4
+	p.streamMutex.Unlock()
+	return p
+}
+
+// LoopRegion returns the start and end of the Player's loop region. If the Player doesn't loop, both are 0.
+func (p *Player) LoopRegion() (start, end time.Duration) {
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+	if p.loopEnd <= p.loopStart {
+		return 0, 0
+	}
+	bytesPerSecond := float64(SampleRate() * 4)
+	return time.Duration(float64(p.loopStart) / bytesPerSecond * float64(time.Second)), time.Duration(float64(p.loopEnd) / bytesPerSecond * float64(time.Second))
+}
+
+// ClearLoopRegion stops the Player from looping.
+func (p *Player) ClearLoopRegion() *Player {
+	p.streamMutex.Lock()
+	p.loopStart = 0
+	p.loopEnd = 0
+	p.streamMutex.Unlock()
+	return p
+}
+
+// applyFinalStage applies the Player's own built-in properties (like panning and fading) to the audio after all effects have been applied.
+// The fade advances here, as the audio is played; once a fade out reaches silence, the Player is paused.
+func (p *Player) applyFinalStage(data []byte, bytesRead int) {
+
+	p.mutex.Lock()
+	pan, muted, channel := p.pan, p.muted, p.DSPChannel
+	gain, target, step, id, pause := p.fadeGain, p.fadeTarget, p.fadeStep, p.fadeID, p.fadePause
+	p.mutex.Unlock()
+
+	if pan == 0 && gain == 1 && step == 0 && !muted {
+		return
+	}
+
+	// This uses the same linear panning law as the Pan effect.
+	ls := math.Min(pan*-1+1, 1)
+	rs := math.Min(pan+1, 1)
+
+	// Players playing through a DSPChannel are muted by the channel.
+	if muted && channel == nil {
+		ls, rs = 0, 0
+	}
+
+	audioBuffer := AudioBuffer(data)
+
+	for i := 0; i < bytesRead/4; i++ {
+
+		if step != 0 {
+			gain += step
+			if (step > 0 && gain >= target) || (step < 0 && gain <= target) {
+				gain = target
+				step = 0
+			}
+		}
+
+		l, r := audioBuffer.Get(i)
+		audioBuffer.Set(i, l*ls*gain, r*rs*gain)
+
+	}
+
+	ended := false
+
+	// The fade is only updated if another one hasn't been started while the audio was processed.
+	p.mutex.Lock()
+	if p.fadeID == id {
+		p.fadeGain = gain
+		p.fadeStep = step
+		if pause && step == 0 {
+			p.fadePause = false
+			ended = true
+		}
+	}
+	p.mutex.Unlock()
+
+	// The Player is paused from another goroutine, as pausing it may have to wait on the audio thread.
+	if ended {
+		go p.endFadeOut(id)
+	}
+
+}
+
+// FadeIn starts playing the Player (if it isn't playing already), fading its volume in from silence over the given duration.
+// The fade advances as audio is played, so it's independent of the game's frame rate.
+func (p *Player) FadeIn(duration time.Duration) {
+
+	playing := p.IsPlaying()
+
+	p.mutex.Lock()
+	if !playing {
+		p.fadeGain = 0
+	}
+	p.startFade(1, duration)
+	p.mutex.Unlock()
+
+	p.Play()
+
+}
+
+// Stop stops the Player by pausing it and rewinding it to the start of its stream. Rewinding also resets the Player's effects
+// (see ResetEffects()), so echoes and reverb tails don't carry over to the next time it's played. Any fade in progress is cancelled,
+// and the Player's volume is restored.
+func (p *Player) Stop() error {
+
+	p.mutex.Lock()
+	p.fadeID++
+	p.fadeGain = 1
+	p.fadeTarget = 1
+	p.fadeStep = 0
+	p.fadePause = false
+	p.mutex.Unlock()
+
+	p.Pause()
+
+	return p.Rewind()
+
+}
+
+// Restart stops the Player (see Stop()) and then plays it again from the start of its stream. This is handy for replaying one-shot sounds.
+func (p *Player) Restart() error {
+
+	if err := p.Stop(); err != nil {
+		return err
+	}
+
+	p.Play()
+
+	return nil
+
+}
+
+// FadeOut fades the Player's volume out to silence over the given duration, and then pauses it. Like FadeIn(), the fade advances
+// as the audio is played, and the Player is paused once the fade reaches silence in its audio, so the pause lines up with the end
+// of the fade rather than with the wall clock. The Player's volume is restored when it's played again. Playing the Player or starting
+// another fade before the fade out finishes cancels it, so the Player isn't paused.
+func (p *Player) FadeOut(duration time.Duration) {
+
+	p.mutex.Lock()
+	id := p.startFade(0, duration)
+	p.fadePause = true
+	done := p.fadeStep == 0
+	if done {
+		p.fadePause = false
+	}
+	p.mutex.Unlock()
+
+	// A fade with no duration finishes immediately.
+	if done {
+		p.endFadeOut(id)
+	}
+
+}
+
+// endFadeOut pauses the Player once the fade out with the given ID has finished, as long as another fade hasn't been started since.
+func (p *Player) endFadeOut(id int) {
+
+	p.playMutex.Lock()
+	defer p.playMutex.Unlock()
+
+	p.mutex.Lock()
+	current := p.fadeID == id
+	p.mutex.Unlock()
+
+	if current {
+		p.pause()
+	}
+
+}
+
+// StartFade fades the Player's volume from the given starting level to the given target level over the given duration,
+// where a level of 1 leaves the volume set with SetVolume() unchanged and 0 is silence. Unlike FadeIn() and FadeOut(),
+// StartFade() neither plays nor pauses the Player, and the Player stays at the target level once the fade finishes.
+// Like the other fades, it advances as the audio is played, and starting another fade cancels it.
+func (p *Player) StartFade(from, to float64, duration time.Duration) *Player {
+	p.mutex.Lock()
+	p.fadeGain = from
+	p.startFade(to, duration)
+	p.mutex.Unlock()
+	return p
+}
+
+// IsFading returns if the Player is in the middle of a fade started with FadeIn(), FadeOut(), or StartFade().
+func (p *Player) IsFading() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.fadeStep != 0
+}
+
+// startFade starts fading the Player's built-in gain from its current value to the target value over the given duration,
+// returning the ID of the fade. The Player's mutex should be held when calling this.
+func (p *Player) startFade(target float64, duration time.Duration) int {
+
+	p.fadeID++
+	p.fadeTarget = target
+	p.fadePause = false
+
+	samples := duration.Seconds() * float64(SampleRate())
+
+	if samples <= 0 {
+		p.fadeGain = target
+		p.fadeStep = 0
+		return p.fadeID
+	}
+
+	p.fadeStep = (target - p.fadeGain) / samples
+
+	return p.fadeID
+
+}
+
+// Seek seeks the Player's source stream. Seeking to the start of the stream (like when rewinding the Player) also resets
+// the Player's effects (see ResetEffects()), so audio from before the seek doesn't bleed into the audio after it.
 func (p *Player) Seek(offset int64, whence int) (int64, error) {
 
+	p.streamMutex.Lock()
+	defer p.streamMutex.Unlock()
+
 	if p.Source == nil {
 		return 0, nil
 	}
 
-	return p.Source.Seek(offset, whence)
+	if offset == 0 && whence == io.SeekStart {
+		p.resetEffects()
+	}
+
+	// Seeking through the rate stage clears any audio it's buffered.
+	pos, err := p.rateStream().Seek(offset, whence)
+	if err == nil {
+		p.seekBase = pos
+		p.outputBytes = 0
+		p.mutex.Lock()
+		p.ended = false
+		p.endID++
+		p.mutex.Unlock()
+	}
+
+	return pos, err
 
 }
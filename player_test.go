@@ -0,0 +1,448 @@
+package resound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestFadeOutPauses checks that a fade out is driven by the audio that's read, pausing the Player once it reaches silence,
+// and that playing the Player again restores its volume.
+func TestFadeOutPauses(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	player := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	buffer := make([]byte, 256*4)
+	mixer.Read(buffer)
+
+	// The fade lasts 1024 frames, or 4 buffers.
+	player.FadeOut(time.Second * 1024 / 44100)
+
+	for i := 0; i < 4; i++ {
+		if !player.IsFading() {
+			t.Fatalf("expected the Player to still be fading after %d buffers", i)
+		}
+		mixer.Read(buffer)
+	}
+
+	if l, r := AudioBuffer(buffer).Get(255); math.Abs(l) > 0.001 || math.Abs(r) > 0.001 {
+		t.Errorf("expected the end of the fade to be silent, got %f, %f", l, r)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for player.IsPlaying() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the Player to pause once its fade out reached silence")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	player.Play()
+	mixer.Read(buffer)
+
+	if l, _ := AudioBuffer(buffer).Get(255); math.Abs(l-0.5) > 0.001 {
+		t.Errorf("expected playing the Player to restore its volume, got %f", l)
+	}
+
+}
+
+// TestFadeOutCancelled checks that playing a Player while it's fading out cancels the fade, so it isn't paused.
+func TestFadeOutCancelled(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	player := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	buffer := make([]byte, 256*4)
+
+	player.FadeOut(time.Second * 512 / 44100)
+	mixer.Read(buffer)
+	player.Play()
+
+	for i := 0; i < 4; i++ {
+		mixer.Read(buffer)
+	}
+
+	if !player.IsPlaying() || player.IsFading() {
+		t.Error("expected playing the Player to cancel its fade out")
+	}
+
+}
+
+// TestStartFade checks that a fade between two levels ramps the Player's volume over the given duration, and then holds it at the target level.
+func TestStartFade(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	player := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	buffer := make([]byte, 256*4)
+
+	// The fade lasts 512 frames, or 2 buffers.
+	player.StartFade(1, 0.5, time.Second*512/44100)
+
+	mixer.Read(buffer)
+
+	if l, _ := AudioBuffer(buffer).Get(0); math.Abs(l-0.5) > 0.001 {
+		t.Errorf("expected the fade to start at its starting level, got %f", l)
+	}
+
+	if l, _ := AudioBuffer(buffer).Get(255); math.Abs(l-0.375) > 0.005 {
+		t.Errorf("expected the fade to be halfway done after 1 buffer, got %f", l)
+	}
+
+	for i := 0; i < 2; i++ {
+		mixer.Read(buffer)
+	}
+
+	if l, _ := AudioBuffer(buffer).Get(255); math.Abs(l-0.25) > 0.001 || player.IsFading() || !player.IsPlaying() {
+		t.Errorf("expected the Player to keep playing at the fade's target level once it finished, got %f", l)
+	}
+
+}
+
+// TestOnEnd checks that a Player's end callback is called once when its stream ends, and again after it's rewound and replayed.
+func TestOnEnd(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	ended := make(chan struct{}, 4)
+
+	player := newPlayer(bytes.NewReader(testConstant(1000, 0.5)))
+	player.SetOnEnd(func() { ended <- struct{}{} })
+	player.SetDSPChannel(channel)
+
+	buffer := make([]byte, 256*4)
+
+	for replay := 0; replay < 2; replay++ {
+
+		mixer.Add(player)
+
+		for i := 0; i < 8; i++ {
+			mixer.Read(buffer)
+		}
+
+		select {
+		case <-ended:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the end callback to be called (play %d)", replay+1)
+		}
+
+		select {
+		case <-ended:
+			t.Fatalf("expected the end callback to be called only once (play %d)", replay+1)
+		case <-time.After(time.Millisecond * 20):
+		}
+
+		player.Rewind()
+
+	}
+
+}
+
+// TestCloneProperties checks that a clone copies the original's properties (including muting and soloing) and clones its effects,
+// but doesn't share its callbacks.
+func TestCloneProperties(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	effect := &testEffect{gain: 0.5}
+
+	player := newPlayer(bytes.NewReader(testConstant(1024, 0.5)))
+	player.AddEffect("gain", effect)
+	player.SetMuted(true).SetSolo(true).SetPan(0.5).SetAnalyzer(func(AnalysisFrame) {}).SetOnEnd(func() {})
+	player.SetVolume(0.25)
+
+	clone, err := player.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !clone.Muted() || !clone.Solo() || clone.Pan() != 0.5 || clone.Volume() != 0.25 {
+		t.Errorf("expected the clone to copy the original's properties, got muted %t, solo %t, pan %f, volume %f",
+			clone.Muted(), clone.Solo(), clone.Pan(), clone.Volume())
+	}
+
+	if clone.analyzer != nil || clone.onEnd != nil {
+		t.Error("expected the clone not to share the original's analyzer or end callback")
+	}
+
+	cloned, ok := clone.Effect("gain").(*testEffect)
+	if !ok || cloned == effect || cloned.gain != effect.gain {
+		t.Error("expected the clone's effect to be a copy of the original's")
+	}
+
+}
+
+// TestSetSourceRewiresStreamEffects checks that changing a Player's source re-wires its stream effects to read from the new source.
+func TestSetSourceRewiresStreamEffects(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	player := newPlayer(nil)
+	player.AddStreamEffect("resample", NewResampler(nil, 44100, 44100))
+
+	buffer := make([]byte, 64*4)
+
+	for _, level := range []float64{0.5, 0.25} {
+
+		player.SetSource(bytes.NewReader(testConstant(1024, level)))
+
+		if _, err := player.Read(buffer); err != nil {
+			t.Fatal(err)
+		}
+
+		if l, _ := AudioBuffer(buffer).Get(32); math.Abs(l-level) > 0.001 {
+			t.Errorf("expected the stream effect to read the new source at %f, got %f", level, l)
+		}
+
+	}
+
+}
+
+// testOrderEffect is an effect that logs its name each time it's applied, so the order effects are applied in can be checked.
+type testOrderEffect struct {
+	testEffect
+	name string
+	log  *[]string
+}
+
+func (e *testOrderEffect) ApplyEffect(data []byte, bytesRead int) {
+	*e.log = append(*e.log, e.name)
+}
+
+func TestMoveEffect(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	log := []string{}
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	player := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	player.SetDSPChannel(channel)
+	mixer.Add(player)
+
+	for _, name := range []string{"a", "b", "c"} {
+		player.AddEffect("player "+name, &testOrderEffect{name: "player " + name, log: &log})
+		channel.AddEffect("channel "+name, &testOrderEffect{name: "channel " + name, log: &log})
+	}
+
+	buffer := make([]byte, 256*4)
+
+	tests := []struct {
+		change   func()
+		expected []string
+	}{
+		{func() {}, []string{"player a", "player b", "player c", "channel a", "channel b", "channel c"}},
+		{func() { player.MoveEffect("player c", 0); channel.MoveEffect("channel a", 5) }, []string{"player c", "player a", "player b", "channel b", "channel c", "channel a"}},
+		{func() { player.RemoveEffect("player a"); channel.RemoveEffect("channel c") }, []string{"player c", "player b", "channel b", "channel a"}},
+		{func() { player.ClearEffects(); channel.ClearEffects() }, []string{}},
+	}
+
+	for i, test := range tests {
+
+		test.change()
+
+		log = log[:0]
+		mixer.Read(buffer)
+
+		if !reflect.DeepEqual(log, test.expected) {
+			t.Errorf("step %d: expected the effects to be applied in the order %v, got %v", i, test.expected, log)
+		}
+
+	}
+
+	if len(player.Effects) != 0 || len(player.EffectOrder) != 0 {
+		t.Errorf("expected clearing the effects to empty the Player's effect map and order, got %d and %d", len(player.Effects), len(player.EffectOrder))
+	}
+
+}
+
+// TestCopyPropertiesClonesEffects checks that Players given the same effects with CopyProperties() each process audio with their
+// own instances, so one Player's effect state doesn't bleed into the other's.
+func TestCopyPropertiesClonesEffects(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+	mixer := NewMixer(channel)
+
+	original := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	original.SetDSPChannel(channel)
+	original.AddEffect("gain", &testEffect{gain: 0.5})
+
+	other := newPlayer(bytes.NewReader(testConstant(44100, 0.5)))
+	original.CopyProperties(other)
+
+	if other.DSPChannel != channel {
+		t.Error("expected the DSPChannel to be copied")
+	}
+
+	a, b := original.Effect("gain").(*testEffect), other.Effect("gain").(*testEffect)
+	if a == b || b.gain != a.gain {
+		t.Fatal("expected the other Player's effect to be a copy of the original's")
+	}
+
+	buffer := make([]byte, 256*4)
+
+	// Only the original plays at first, then both play together.
+	mixer.Add(original)
+	mixer.Read(buffer)
+
+	mixer.Add(other)
+	mixer.Read(buffer)
+
+	if a.frames != 512 || b.frames != 256 {
+		t.Errorf("expected each Player's effect to only process its own audio, got %d and %d frames", a.frames, b.frames)
+	}
+
+}
+
+func TestLoopRegion(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	// Each frame of the source holds its own index, so where the Player reads from can be checked exactly.
+	source := make([]byte, 100*4)
+	for i := 0; i < 100; i++ {
+		binary.LittleEndian.PutUint16(source[i*4:], uint16(i))
+		binary.LittleEndian.PutUint16(source[i*4+2:], uint16(i))
+	}
+
+	for _, test := range []struct {
+		name       string
+		start, end int
+		loopFrom   int // The frame the Player loops back to
+		loopTo     int // The frame the Player loops back at
+	}{
+		{"loop within the stream", 40, 60, 40, 60},
+		{"loop past the end of the stream", 80, 200, 80, 100},
+	} {
+
+		player := newPlayer(bytes.NewReader(source))
+		player.SetLoopRegionBytes(int64(test.start*4), int64(test.end*4))
+
+		output := make([]byte, 300*4)
+		if _, err := player.Read(output); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		for i := 0; i < 300; i++ {
+
+			expected := i
+			if i >= test.loopTo {
+				expected = test.loopFrom + (i-test.loopTo)%(test.loopTo-test.loopFrom)
+			}
+
+			if frame := int(binary.LittleEndian.Uint16(output[i*4:])); frame != expected {
+				t.Fatalf("%s: expected frame %d of the output to be frame %d of the source, got %d", test.name, i, expected, frame)
+			}
+
+		}
+
+	}
+
+	player := newPlayer(bytes.NewReader(source)).SetLoopRegion(10*time.Millisecond, 20*time.Millisecond)
+
+	if start, end := player.LoopRegion(); start != 10*time.Millisecond || end != 20*time.Millisecond {
+		t.Errorf("expected a loop region from 10ms to 20ms, got %s to %s", start, end)
+	}
+
+	if start, end := player.ClearLoopRegion().LoopRegion(); start != 0 || end != 0 {
+		t.Errorf("expected no loop region once cleared, got %s to %s", start, end)
+	}
+
+}
+
+// testFrequency returns the frequency of the sine wave in the given audio, measured by counting how often the left channel crosses zero.
+func testFrequency(data []byte) float64 {
+	buffer := AudioBuffer(data)
+	crossings := 0
+	prev, _ := buffer.Get(0)
+	for i := 1; i < buffer.Len(); i++ {
+		l, _ := buffer.Get(i)
+		if (l >= 0) != (prev >= 0) {
+			crossings++
+		}
+		prev = l
+	}
+	return float64(crossings) / 2 / (float64(buffer.Len()) / 44100)
+}
+
+func TestPlaybackRate(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	source := make([]byte, 44100*4)
+	for i := 0; i < 44100; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*441*float64(i)/44100)
+		AudioBuffer(source).Set(i, v, v)
+	}
+
+	for _, test := range []struct {
+		preservePitch bool
+		freq          float64
+	}{
+		{false, 882},
+		{true, 441},
+	} {
+
+		player := newPlayer(bytes.NewReader(source)).SetPlaybackRate(2).SetPreservePitch(test.preservePitch)
+
+		output := []byte{}
+		buffer := make([]byte, 4096)
+
+		for {
+			n, err := player.Read(buffer)
+			output = append(output, buffer[:n]...)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if frames := len(output) / 4; math.Abs(float64(frames)-22050) > 22050*0.05 {
+			t.Errorf("expected playing at double speed to halve the length of the audio, got %d frames with pitch preservation set to %t", frames, test.preservePitch)
+		}
+
+		// The ends are skipped, as the time stretcher fades in and out.
+		middle := output[len(output)/4/4*4 : len(output)/4*3/4*4]
+
+		if freq := testFrequency(middle); math.Abs(freq-test.freq) > test.freq*0.02 {
+			t.Errorf("expected a 441 hz sine to play back at %f hz with pitch preservation set to %t, got %f hz", test.freq, test.preservePitch, freq)
+		}
+
+	}
+
+	if rate := newPlayer(bytes.NewReader(source)).SetPlaybackRate(10).PlaybackRate(); rate != 4 {
+		t.Errorf("expected the playback rate to be clamped to 4, got %f", rate)
+	}
+
+}
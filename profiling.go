@@ -0,0 +1,59 @@
+package resound
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// profiling indicates whether the time spent applying effects is being recorded.
+var profiling atomic.Bool
+
+// EnableProfiling sets whether resound records how long each DSPChannel's effects take to process audio, which can be read
+// with DSPChannel.EffectTimings(). This helps find which effects are expensive, so they can be simplified or moved to a shared channel.
+// Profiling is disabled by default; while disabled, effects are applied without being timed, so it costs next to nothing.
+func EnableProfiling(enabled bool) {
+	profiling.Store(enabled)
+}
+
+// ProfilingEnabled returns whether resound records how long each DSPChannel's effects take to process audio.
+func ProfilingEnabled() bool {
+	return profiling.Load()
+}
+
+// EffectTimings returns the total time spent applying each of the DSPChannel's effects (keyed by their IDs) since profiling was
+// enabled (see EnableProfiling()) or the timings were last reset. As a DSPChannel applies its effects to the mix of everything playing
+// through it at once, this is the time spent processing the channel's mix. Effects that haven't been timed aren't included.
+func (d *DSPChannel) EffectTimings() map[any]time.Duration {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	timings := map[any]time.Duration{}
+	for id, effect := range d.Effects {
+		if t, ok := d.timings[effect]; ok {
+			timings[id] = t
+		}
+	}
+	return timings
+}
+
+// ResetEffectTimings clears the DSPChannel's recorded effect timings.
+func (d *DSPChannel) ResetEffectTimings() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.timings = nil
+}
+
+// applyEffectTimed applies the given effect to the given audio, recording how long it took.
+func (d *DSPChannel) applyEffectTimed(effect IEffect, data []byte, bytesRead int) {
+
+	start := time.Now()
+	effect.ApplyEffect(data, bytesRead)
+	elapsed := time.Since(start)
+
+	d.mutex.Lock()
+	if d.timings == nil {
+		d.timings = map[IEffect]time.Duration{}
+	}
+	d.timings[effect] += elapsed
+	d.mutex.Unlock()
+
+}
@@ -0,0 +1,88 @@
+package resound
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// RenderToWAV reads the given stream (like an effect chain, or a Player's source) from its current position and writes the audio
+// to the given Writer as a 16-bit stereo PCM WAV file, at the sample rate given by SampleRate().
+// This is useful for rendering processed audio offline, or for checking what an effect does in an audio editor.
+// Reading stops when the stream ends or once maxDuration of audio has been read, whichever comes first; streams that never end (like an
+// audio.InfiniteLoop) need a maxDuration greater than 0, or RenderToWAV would never return. If maxDuration is 0 or less, the stream is read until it ends.
+// As the size of the audio must be known before the WAV header is written, the audio is rendered into memory before being written out.
+func RenderToWAV(w io.Writer, stream io.ReadSeeker, maxDuration time.Duration) error {
+
+	sampleRate := SampleRate()
+
+	maxFrames := 0
+	if maxDuration > 0 {
+		maxFrames = int(maxDuration.Seconds() * float64(sampleRate))
+	}
+
+	data, err := RenderBuffer(stream, maxFrames)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+len(data)))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)                   // Size of the format chunk
+	binary.LittleEndian.PutUint16(header[20:], 1)                    // PCM
+	binary.LittleEndian.PutUint16(header[22:], 2)                    // Channels
+	binary.LittleEndian.PutUint32(header[24:], uint32(sampleRate))   // Sample rate
+	binary.LittleEndian.PutUint32(header[28:], uint32(sampleRate*4)) // Bytes per second
+	binary.LittleEndian.PutUint16(header[32:], 4)                    // Bytes per frame
+	binary.LittleEndian.PutUint16(header[34:], 16)                   // Bits per sample
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+
+}
+
+// RenderBuffer reads up to the given number of frames from the given stream (like an effect chain) from its current position, returning
+// the raw 16-bit stereo audio read. Reading stops early if the stream ends; if maxFrames is 0 or less, the stream is read until it ends.
+// As no audio device is involved, this is handy for testing what effects do to a known input deterministically.
+// Effects that work in terms of time (like Delay, Reverb, Volume's fades, the filters, and other effects with settings in seconds or Hz)
+// use the audio context's sample rate, or the default sample rate if there's no context (see SetDefaultSampleRate()).
+func RenderBuffer(stream io.ReadSeeker, maxFrames int) ([]byte, error) {
+
+	limit := maxFrames * 4
+
+	data := []byte{}
+	buffer := make([]byte, 4096)
+
+	for maxFrames <= 0 || len(data) < limit {
+
+		n, err := stream.Read(buffer)
+
+		data = append(data, buffer[:n]...)
+
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+	}
+
+	if maxFrames > 0 && len(data) > limit {
+		data = data[:limit]
+	}
+
+	// A partial frame at the end of the stream isn't valid audio.
+	return data[:len(data)/4*4], nil
+
+}
@@ -0,0 +1,87 @@
+package resound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestRenderToWAV(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	source := make([]byte, 1000*4)
+	for i := range source {
+		source[i] = byte(i)
+	}
+
+	for _, test := range []struct {
+		maxDuration time.Duration
+		frames      int
+	}{
+		{0, 1000},
+		{10 * time.Millisecond, 441},
+	} {
+
+		out := &bytes.Buffer{}
+
+		if err := RenderToWAV(out, bytes.NewReader(source), test.maxDuration); err != nil {
+			t.Fatal(err)
+		}
+
+		wav := out.Bytes()
+
+		if len(wav) != 44+test.frames*4 {
+			t.Fatalf("expected a 44 byte header followed by %d frames, got %d bytes", test.frames, len(wav))
+		}
+
+		if string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" || string(wav[36:40]) != "data" {
+			t.Errorf("expected a RIFF WAVE header, got %q", wav[:44])
+		}
+
+		if rate := binary.LittleEndian.Uint32(wav[24:]); rate != 44100 {
+			t.Errorf("expected a sample rate of 44100, got %d", rate)
+		}
+
+		if size := binary.LittleEndian.Uint32(wav[40:]); size != uint32(test.frames*4) {
+			t.Errorf("expected the data chunk to be %d bytes, got %d", test.frames*4, size)
+		}
+
+		if !bytes.Equal(wav[44:], source[:test.frames*4]) {
+			t.Errorf("expected the WAV's data to be the stream's audio")
+		}
+
+	}
+
+}
+
+func TestRenderBuffer(t *testing.T) {
+
+	source := make([]byte, 10000*4+2) // With a partial frame at the end
+	for i := range source {
+		source[i] = byte(i)
+	}
+
+	for _, test := range []struct {
+		maxFrames int
+		frames    int
+	}{
+		{0, 10000},
+		{100, 100},
+		{20000, 10000},
+	} {
+
+		data, err := RenderBuffer(bytes.NewReader(source), test.maxFrames)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(data, source[:test.frames*4]) {
+			t.Errorf("expected rendering up to %d frames to return the first %d frames of the stream, got %d bytes", test.maxFrames, test.frames, len(data))
+		}
+
+	}
+
+}
@@ -0,0 +1,287 @@
+package resound
+
+import (
+	"io"
+	"math"
+)
+
+// ResampleQuality indicates how a Resampler interpolates between the frames of its source stream.
+type ResampleQuality int
+
+const (
+	// ResampleLinear linearly interpolates between the two nearest frames. This is very cheap, but slightly dulls high frequencies
+	// and lets some aliasing through (most noticeably when lowering the sample rate). It's fine for sound effects and for small rate changes.
+	ResampleLinear ResampleQuality = iota
+	// ResampleSinc interpolates with a windowed-sinc filter over the 16 nearest frames, low-pass filtering the audio when lowering
+	// the sample rate to avoid aliasing. This sounds much cleaner, particularly for music, but costs several times as much CPU as
+	// linear interpolation, as each output frame takes 16 sine evaluations and multiply-adds per channel.
+	ResampleSinc
+)
+
+// sincHalfWidth is the number of frames on either side of the read position that ResampleSinc interpolates over.
+const sincHalfWidth = 8
+
+// Resampler converts audio from one sample rate to another as it's read, so that audio decoded or authored at a different rate than the
+// audio context (like a 48000 Hz sound played in a 44100 Hz context) plays at the correct speed and pitch.
+// A Resampler is an io.ReadSeeker, so it can be used as a Player's source, or as the source of an effect. It can also be used as an
+// IStreamEffect, though its source should be set before it's read from.
+type Resampler struct {
+	source  io.ReadSeeker
+	rate    float64 // How many source frames are read for each output frame
+	quality ResampleQuality
+
+	// sourceTimeline indicates that seek offsets are in the source stream's timeline, rather than the resampled stream's.
+	// This is used by Players to change their playback rate.
+	sourceTimeline bool
+
+	frames     [][2]float64
+	pos        float64
+	outPos     int64 // The position in the resampled stream, in frames
+	ended      bool
+	err        error
+	readBuffer []byte
+}
+
+// NewResampler creates a new Resampler that reads 16-bit stereo audio at srcRate from the given source, and outputs it at dstRate
+// (usually the audio context's sample rate, given by SampleRate()). The Resampler uses linear interpolation by default (see SetQuality()).
+func NewResampler(source io.ReadSeeker, srcRate, dstRate int) *Resampler {
+	r := newResampler(source, 1)
+	r.SetRates(srcRate, dstRate)
+	r.sourceTimeline = false
+	return r
+}
+
+func newResampler(source io.ReadSeeker, rate float64) *Resampler {
+	return &Resampler{
+		source:         source,
+		rate:           rate,
+		sourceTimeline: true,
+		readBuffer:     make([]byte, 4096),
+	}
+}
+
+// SetRates sets the sample rate of the Resampler's source (srcRate) and the rate it outputs audio at (dstRate).
+// Rates of 0 or less are ignored.
+func (r *Resampler) SetRates(srcRate, dstRate int) *Resampler {
+	if srcRate > 0 && dstRate > 0 {
+		r.rate = float64(srcRate) / float64(dstRate)
+	}
+	return r
+}
+
+// SetQuality sets how the Resampler interpolates between frames (see the ResampleQuality constants). Defaults to ResampleLinear.
+func (r *Resampler) SetQuality(quality ResampleQuality) *Resampler {
+	r.quality = quality
+	return r
+}
+
+// Quality returns how the Resampler interpolates between frames.
+func (r *Resampler) Quality() ResampleQuality {
+	return r.quality
+}
+
+// SetSource sets the stream the Resampler reads from, clearing any audio it has buffered.
+func (r *Resampler) SetSource(source io.ReadSeeker) {
+	r.source = source
+	r.outPos = 0
+	r.Reset()
+}
+
+// lookbehind returns the number of frames before the read position that the Resampler needs to interpolate.
+func (r *Resampler) lookbehind() int {
+	if r.quality == ResampleSinc {
+		return sincHalfWidth - 1
+	}
+	return 0
+}
+
+// lookahead returns the number of frames from the read position onwards that the Resampler needs to interpolate.
+func (r *Resampler) lookahead() int {
+	if r.quality == ResampleSinc {
+		return sincHalfWidth + 1
+	}
+	return 2
+}
+
+func (r *Resampler) Read(p []byte) (int, error) {
+
+	audioBuffer := AudioBuffer(p)
+	count := 0
+
+	for count < audioBuffer.Len() {
+
+		i := int(math.Floor(r.pos))
+
+		r.fill(i + r.lookahead())
+
+		if i >= len(r.frames) {
+			break
+		}
+
+		var l, rr float64
+		if r.quality == ResampleSinc {
+			l, rr = r.sinc(i)
+		} else {
+			l, rr = r.linear(i)
+		}
+
+		audioBuffer.Set(count, l, rr)
+		count++
+
+		r.pos += r.rate
+
+	}
+
+	r.outPos += int64(count)
+
+	// Discard the frames that have been passed, keeping those that are still needed to interpolate.
+	if drop := int(r.pos) - r.lookbehind(); drop > 0 {
+		if drop > len(r.frames) {
+			drop = len(r.frames)
+		}
+		r.frames = r.frames[:copy(r.frames, r.frames[drop:])]
+		r.pos -= float64(drop)
+	}
+
+	if count == 0 && r.ended {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+
+	return count * 4, nil
+
+}
+
+// frame returns the buffered frame at the given index, or silence if it's out of range.
+func (r *Resampler) frame(i int) (float64, float64) {
+	if i < 0 || i >= len(r.frames) {
+		return 0, 0
+	}
+	return r.frames[i][0], r.frames[i][1]
+}
+
+// linear returns the linearly-interpolated frame at the read position, which lies between frames i and i+1.
+func (r *Resampler) linear(i int) (float64, float64) {
+
+	t := r.pos - float64(i)
+	l0, r0 := r.frame(i)
+	l1, r1 := l0, r0
+
+	if i+1 < len(r.frames) {
+		l1, r1 = r.frame(i + 1)
+	}
+
+	return l0 + (l1-l0)*t, r0 + (r1-r0)*t
+
+}
+
+// sinc returns the windowed-sinc-interpolated frame at the read position, which lies between frames i and i+1.
+func (r *Resampler) sinc(i int) (float64, float64) {
+
+	// When lowering the sample rate, the cutoff is lowered to the new rate's Nyquist frequency to avoid aliasing.
+	cutoff := 1.0
+	if r.rate > 1 {
+		cutoff = 1 / r.rate
+	}
+
+	var l, rr float64
+
+	for k := i - sincHalfWidth + 1; k <= i+sincHalfWidth; k++ {
+
+		x := r.pos - float64(k)
+
+		weight := cutoff
+		if x != 0 {
+			weight = math.Sin(math.Pi*cutoff*x) / (math.Pi * x)
+		}
+
+		// Hann window
+		weight *= 0.5 + 0.5*math.Cos(math.Pi*x/sincHalfWidth)
+
+		fl, fr := r.frame(k)
+		l += fl * weight
+		rr += fr * weight
+
+	}
+
+	return l, rr
+
+}
+
+// fill reads from the source until the Resampler holds at least the given number of frames, or the source ends.
+func (r *Resampler) fill(frames int) {
+
+	for len(r.frames) < frames && !r.ended {
+
+		n, err := r.source.Read(r.readBuffer)
+
+		buffer := AudioBuffer(r.readBuffer[:n])
+
+		for i := 0; i < buffer.Len(); i++ {
+			l, r2 := buffer.Get(i)
+			r.frames = append(r.frames, [2]float64{l, r2})
+		}
+
+		if err != nil {
+			r.ended = true
+			if err != io.EOF {
+				r.err = err
+			}
+		} else if n == 0 {
+			break
+		}
+
+	}
+
+}
+
+// Reset clears the Resampler's buffered audio. This doesn't seek its source.
+func (r *Resampler) Reset() {
+	r.frames = r.frames[:0]
+	r.pos = 0
+	r.ended = false
+	r.err = nil
+}
+
+// Seek seeks the Resampler, clearing any buffered audio. Offsets are in the resampled stream's timeline, so the source is seeked to the
+// matching point in its own timeline.
+func (r *Resampler) Seek(offset int64, whence int) (int64, error) {
+
+	if r.sourceTimeline {
+		r.Reset()
+		return r.source.Seek(offset, whence)
+	}
+
+	target := offset
+
+	switch whence {
+	case io.SeekCurrent:
+		if offset == 0 {
+			return r.outPos * 4, nil
+		}
+		target += r.outPos * 4
+	case io.SeekEnd:
+		end, err := r.source.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		target += int64(float64(end/4)/r.rate) * 4
+	}
+
+	if target < 0 {
+		target = 0
+	}
+
+	r.Reset()
+
+	if _, err := r.source.Seek(int64(float64(target/4)*r.rate)*4, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	r.outPos = target / 4
+
+	return r.outPos * 4, nil
+
+}
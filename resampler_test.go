@@ -0,0 +1,40 @@
+package resound
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestResamplerKeepsPitch(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	// One second of a 441 Hz sine, recorded at 22050 Hz.
+	source := make([]byte, 22050*4)
+	for i := 0; i < 22050; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*441*float64(i)/22050)
+		AudioBuffer(source).Set(i, v, v)
+	}
+
+	for _, quality := range []ResampleQuality{ResampleLinear, ResampleSinc} {
+
+		resampler := NewResampler(bytes.NewReader(source), 22050, 44100).SetQuality(quality)
+
+		data, err := io.ReadAll(resampler)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if frames := len(data) / 4; math.Abs(float64(frames)-44100) > 10 {
+			t.Errorf("quality %d: expected resampling from 22050 Hz to 44100 Hz to double the length to 44100 frames, got %d", quality, frames)
+		}
+
+		if freq := testFrequency(data); math.Abs(freq-441) > 441*0.02 {
+			t.Errorf("quality %d: expected the resampled sine to stay at 441 Hz, got %f Hz", quality, freq)
+		}
+
+	}
+
+}
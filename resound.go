@@ -1,16 +1,144 @@
 package resound
 
 import (
+	"encoding/binary"
 	"io"
 	"math"
 	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
 )
 
 // IEffect indicates an effect that implements io.ReadSeeker and generally takes effect on an existing audio stream.
 // It represents the result of applying an effect to an audio stream, and is playable in its own right.
+// Note that as SetSource() is part of the interface (so effects can be wired together generically, like with ChainEffects()),
+// the effects' SetSource() functions no longer return the effect, and so can't be chained with their other setters; set the
+// source in a statement of its own, or wire effects together with ChainEffects().
+//
+// An effect's source is only read when the effect is played as a stream, either directly or as part of a chain wired with
+// SetSource() or ChainEffects(). Players, DSPChannels, and EffectChains apply the effects added to them with ApplyEffect(), so
+// they never read from or change those effects' sources; an effect keeps whatever source it had while it's added to one. As an
+// effect holds state (like a delay line), it shouldn't be played as a stream and applied by a Player or DSPChannel at the same time.
+// Cloning an effect carries its source over to the clone. Stream effects are the exception: a Player sets their sources itself
+// when they're added, and re-wires them whenever its own source changes (see Player.AddStreamEffect() and Player.SetSource()).
 type IEffect interface {
 	io.ReadSeeker
 	ApplyEffect(data []byte, bytesRead int) // This function is called when sound data goes through an effect. The effect should modify the data byte buffer.
+	Clone() IEffect                         // This function should return a copy of the effect with the same settings, but with its own independent state.
+	SetSource(source io.ReadSeeker)         // This function sets the stream the effect reads from when it's played directly as a stream.
+}
+
+// ChainEffects wires the given effects together in order, setting each effect's source to the effect before it,
+// and returns the last effect so it can be played as the tail of the chain. The first effect's source is left as-is,
+// so you'll want to set it to the stream you want to process (either before or after calling ChainEffects).
+// If no effects are given, ChainEffects returns nil.
+func ChainEffects(effects ...IEffect) IEffect {
+
+	if len(effects) == 0 {
+		return nil
+	}
+
+	for i := 1; i < len(effects); i++ {
+		effects[i].SetSource(effects[i-1])
+	}
+
+	return effects[len(effects)-1]
+
+}
+
+// The effects and streams in this package satisfy the interfaces that let them be wired together generically.
+var (
+	_ IEffect       = (*EffectChain)(nil)
+	_ IStreamEffect = (*Resampler)(nil)
+	_ IStreamEffect = (*TimeStretcher)(nil)
+	_ IResettable   = (*EffectChain)(nil)
+)
+
+// IStreamEffect indicates an effect that can change the length of the audio stream that passes through it, like a resampler or
+// a time-stretching effect. Rather than modifying a buffer of already-read audio in place like an IEffect, an IStreamEffect reads
+// as much or as little as it needs from its source stream to fill the buffer it's asked to fill.
+type IStreamEffect interface {
+	io.ReadSeeker
+	SetSource(source io.ReadSeeker) // This function is called by a Player to set the stream the effect reads from.
+}
+
+// IResettable indicates an effect that holds internal state, like a delay line, filter history, or LFO phase, that can be cleared.
+// This is useful when reusing an effect for a different sound, or when jumping to a different point in a stream.
+// The effects in the effects package that implement it also reset themselves when their stream is seeked back to the start.
+type IResettable interface {
+	Reset() // This function should clear the effect's internal state, as though it had just been created, without changing its settings.
+}
+
+// ILatency indicates an effect that delays the audio passing through it, like a limiter's lookahead or a block-based convolution.
+// Knowing the latency of a group of effects allows sounds played through other groups to be delayed to line up with them
+// (e.g. delaying a dry bus to match a wet bus). Effects that don't implement ILatency are assumed to have no latency.
+type ILatency interface {
+	Latency() time.Duration // This function should return how long the effect delays audio by with its current settings.
+}
+
+// effectLatency returns the total latency of the given effects, skipping any in the given bypassed slice (which can be nil).
+func effectLatency(effects []IEffect, bypassed []bool) time.Duration {
+	total := time.Duration(0)
+	for i, effect := range effects {
+		if bypassed != nil && bypassed[i] {
+			continue
+		}
+		if l, ok := effect.(ILatency); ok {
+			total += l.Latency()
+		}
+	}
+	return total
+}
+
+// SampleFormat indicates the format of the samples in an audio stream.
+type SampleFormat int
+
+const (
+	// SampleFormatInt16 is signed 16-bit little-endian integer PCM, interleaved in stereo.
+	SampleFormatInt16 SampleFormat = iota
+	// SampleFormatFloat32 is 32-bit little-endian floating-point PCM, interleaved in stereo.
+	SampleFormatFloat32
+)
+
+// BytesPerFrame returns the number of bytes a single frame (one sample for both the left and right channels) takes in the format.
+func (f SampleFormat) BytesPerFrame() int {
+	if f == SampleFormatFloat32 {
+		return 8
+	}
+	return 4
+}
+
+func (f SampleFormat) String() string {
+	if f == SampleFormatFloat32 {
+		return "float32"
+	}
+	return "int16"
+}
+
+// ContextFormat returns the sample format of audio played through Ebitengine's audio context, so custom effects
+// know how to interpret the buffers they're given. At the moment, Ebitengine's audio.Context always plays stereo
+// signed 16-bit audio, which is what AudioBuffer handles.
+func ContextFormat() SampleFormat {
+	return SampleFormatInt16
+}
+
+// IAudioBuffer indicates a wrapper around a []byte of audio data that can get and set the values of the left and right
+// channels at a specific position in the buffer, regardless of the format of the data.
+type IAudioBuffer interface {
+	Len() int                 // Len returns the number of frames in the buffer.
+	Get(i int) (l, r float64) // Get returns the values for the left and right audio channels at the specified frame index.
+	Set(i int, l, r float64)  // Set sets the values for the left and right audio channels at the specified frame index.
+}
+
+// NewAudioBuffer wraps the given audio data in an IAudioBuffer that can read and write the given sample format;
+// an AudioBuffer for SampleFormatInt16, or an AudioBufferF32 for SampleFormatFloat32. Passing ContextFormat() as the
+// format gives a buffer that matches the audio played through Ebitengine's audio context.
+func NewAudioBuffer(data []byte, format SampleFormat) IAudioBuffer {
+	if format == SampleFormatFloat32 {
+		return AudioBufferF32(data)
+	}
+	return AudioBuffer(data)
 }
 
 // AudioBuffer wraps a []byte of audio data and provides handy functions to get
@@ -24,7 +152,11 @@ func (ab AudioBuffer) Len() int {
 
 // Get returns the values for the left and right audio channels at the specified stream sample index.
 // The values returned for the left and right audio channels range from 0 to 1.
+// If the index is out of range for the buffer, Get returns 0 for both channels.
 func (ab AudioBuffer) Get(i int) (l, r float64) {
+	if !ab.inRange(i) {
+		return 0, 0
+	}
 	lc := float64(int16(ab[i*4]) | int16(ab[i*4+1])<<8)
 	rc := float64(int16(ab[i*4+2]) | int16(ab[i*4+3])<<8)
 	lc /= math.MaxInt16
@@ -34,8 +166,13 @@ func (ab AudioBuffer) Get(i int) (l, r float64) {
 
 // Set sets the left and right audio channel values at the specified stream sample index.
 // The values should range from 0 to 1.
+// If the index is out of range for the buffer, Set does nothing.
 func (ab AudioBuffer) Set(i int, l, r float64) {
 
+	if !ab.inRange(i) {
+		return
+	}
+
 	max := float64(math.MaxInt16)
 
 	l = clamp(l*math.MaxInt16, -max, max)
@@ -50,6 +187,11 @@ func (ab AudioBuffer) Set(i int, l, r float64) {
 	ab[(i*4)+3] = byte(rcc >> 8)
 }
 
+// inRange returns if the given sample index can be safely read from or written to in the buffer.
+func (ab AudioBuffer) inRange(i int) bool {
+	return i >= 0 && i*4+3 < len(ab)
+}
+
 func (ab AudioBuffer) String() string {
 	s := "{ "
 	for i := 0; i < ab.Len(); i++ {
@@ -61,3 +203,191 @@ func (ab AudioBuffer) String() string {
 	s += " }"
 	return s
 }
+
+// Peak returns the peak (highest absolute) level of the left and right audio channels in the buffer, ranging from 0 to 1.
+func (ab AudioBuffer) Peak() (l, r float64) {
+	for i := 0; i < ab.Len(); i++ {
+		sl, sr := ab.Get(i)
+		l = math.Max(l, math.Abs(sl))
+		r = math.Max(r, math.Abs(sr))
+	}
+	return
+}
+
+// RMS returns the RMS (root mean square, or average) level of the left and right audio channels in the buffer, ranging from 0 to 1.
+// If the buffer is empty, RMS returns 0 for both channels.
+func (ab AudioBuffer) RMS() (l, r float64) {
+
+	frames := ab.Len()
+
+	if frames == 0 {
+		return 0, 0
+	}
+
+	for i := 0; i < frames; i++ {
+		sl, sr := ab.Get(i)
+		l += sl * sl
+		r += sr * sr
+	}
+
+	return math.Sqrt(l / float64(frames)), math.Sqrt(r / float64(frames))
+
+}
+
+// ScaleStereo multiplies the left and right audio channels of every frame in the buffer by the given gains, clamping the results
+// to full scale like Set() does. This works on the buffer's samples directly in a single tight loop, so it's much faster than
+// scaling each frame with Get() and Set(), and is a good choice for effects that change the volume of a whole buffer at once.
+// To scale only part of a buffer (like just the bytes read into it), slice it first (e.g. AudioBuffer(p[:bytesRead]).ScaleStereo(l, r)).
+func (ab AudioBuffer) ScaleStereo(lGain, rGain float64) {
+
+	const max = math.MaxInt16
+
+	// Each sample goes through the same conversion to and from the -1 to 1 range as Get() and Set(), so the results match theirs
+	// exactly; scaling the 16-bit value directly can round differently, as the results are truncated to whole values.
+	for i := 0; i+3 < len(ab); i += 4 {
+
+		l := float64(int16(ab[i])|int16(ab[i+1])<<8) / max * lGain * max
+		r := float64(int16(ab[i+2])|int16(ab[i+3])<<8) / max * rGain * max
+
+		if l > max {
+			l = max
+		} else if l < -max {
+			l = -max
+		}
+
+		if r > max {
+			r = max
+		} else if r < -max {
+			r = -max
+		}
+
+		lc, rc := int16(l), int16(r)
+
+		ab[i] = byte(lc)
+		ab[i+1] = byte(lc >> 8)
+		ab[i+2] = byte(rc)
+		ab[i+3] = byte(rc >> 8)
+
+	}
+
+}
+
+// Scale multiplies both audio channels of every frame in the buffer by the given gain; see ScaleStereo().
+func (ab AudioBuffer) Scale(gain float64) {
+	ab.ScaleStereo(gain, gain)
+}
+
+// ClippedCount returns the number of samples in the buffer (counting the left and right channels separately) that are at full scale,
+// which usually means that they were clipped when they were set.
+func (ab AudioBuffer) ClippedCount() int {
+	count := 0
+	for i := 0; i+1 < len(ab)/4*4; i += 2 {
+		if s := int16(ab[i]) | int16(ab[i+1])<<8; s >= math.MaxInt16 || s <= -math.MaxInt16 {
+			count++
+		}
+	}
+	return count
+}
+
+// MixInto adds the buffer's audio to the destination buffer, scaled by the given gain, which is useful for combining several streams into one.
+// The result is clamped to full scale, like it is with Set(). If the buffers are different lengths, only as many frames as are in
+// the shorter of the two are mixed; the rest of the destination buffer is left as is.
+func (ab AudioBuffer) MixInto(dst AudioBuffer, gain float64) {
+	frames := ab.Len()
+	if dst.Len() < frames {
+		frames = dst.Len()
+	}
+	for i := 0; i < frames; i++ {
+		sl, sr := ab.Get(i)
+		dl, dr := dst.Get(i)
+		dst.Set(i, dl+sl*gain, dr+sr*gain)
+	}
+}
+
+// MixBuffers adds the 16-bit stereo audio in src to the audio in dst, scaled by the given gain. This is a shortcut for AudioBuffer(src).MixInto(AudioBuffer(dst), gain);
+// see AudioBuffer.MixInto() for details on clamping and buffers of different lengths.
+func MixBuffers(dst, src []byte, gain float64) {
+	AudioBuffer(src).MixInto(AudioBuffer(dst), gain)
+}
+
+// AudioBufferF32 wraps a []byte of 32-bit little-endian floating-point audio data, interleaved in stereo, and provides
+// handy functions to get and set values for a specific position in the buffer. Unlike AudioBuffer, values aren't quantized
+// or clamped when set.
+type AudioBufferF32 []byte
+
+func (ab AudioBufferF32) Len() int {
+	// We divide by 8 because it's float32 PCM audio at 2 channels, with float32s composing 4 bytes per sample, per channel (4 bytes * 2 channels = 8).
+	return len(ab) / 8
+}
+
+// Get returns the values for the left and right audio channels at the specified stream sample index.
+// If the index is out of range for the buffer, Get returns 0 for both channels.
+func (ab AudioBufferF32) Get(i int) (l, r float64) {
+	if !ab.inRange(i) {
+		return 0, 0
+	}
+	lc := math.Float32frombits(binary.LittleEndian.Uint32(ab[i*8:]))
+	rc := math.Float32frombits(binary.LittleEndian.Uint32(ab[i*8+4:]))
+	return float64(lc), float64(rc)
+}
+
+// Set sets the left and right audio channel values at the specified stream sample index.
+// If the index is out of range for the buffer, Set does nothing.
+func (ab AudioBufferF32) Set(i int, l, r float64) {
+	if !ab.inRange(i) {
+		return
+	}
+	binary.LittleEndian.PutUint32(ab[i*8:], math.Float32bits(float32(l)))
+	binary.LittleEndian.PutUint32(ab[i*8+4:], math.Float32bits(float32(r)))
+}
+
+// inRange returns if the given sample index can be safely read from or written to in the buffer.
+func (ab AudioBufferF32) inRange(i int) bool {
+	return i >= 0 && i*8+7 < len(ab)
+}
+
+func (ab AudioBufferF32) String() string {
+	s := "{ "
+	for i := 0; i < ab.Len(); i++ {
+		l, r := ab.Get(i)
+		ls := strconv.FormatFloat(l, 'f', 6, 64)
+		rs := strconv.FormatFloat(r, 'f', 6, 64)
+		s += "( " + ls + ", " + rs + " ) "
+	}
+	s += " }"
+	return s
+}
+
+// defaultSampleRate is the sample rate used when an audio context hasn't been created.
+var defaultSampleRate = 44100
+
+// SetDefaultSampleRate sets the sample rate that effects and analysis use when an audio context hasn't been created (by default, 44100).
+// This allows effects to process audio without a live audio context, like in tests or on servers; when a context exists,
+// its sample rate is always used instead. Sample rates of 0 or less are ignored.
+func SetDefaultSampleRate(sampleRate int) {
+	if sampleRate > 0 {
+		defaultSampleRate = sampleRate
+	}
+}
+
+// SampleRate returns the sample rate of the audio context, or the default sample rate if a context hasn't been created yet (see SetDefaultSampleRate()).
+func SampleRate() int {
+	if context := audio.CurrentContext(); context != nil {
+		return context.SampleRate()
+	}
+	return defaultSampleRate
+}
+
+// DBToLinear converts the given gain in decibels to a linear gain multiplier (e.g. 0 dB is 1, -6 dB is about 0.5, and +6 dB is about 2).
+func DBToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// LinearToDB converts the given linear gain multiplier to decibels (e.g. 1 is 0 dB, and 0.5 is about -6 dB).
+// Gains of 0 or less return negative infinity, as silence is infinitely quiet in decibels.
+func LinearToDB(linear float64) float64 {
+	if linear <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(linear)
+}
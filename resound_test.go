@@ -0,0 +1,247 @@
+package resound
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// testSine returns the given number of frames of a stereo sine wave with the given frequency and amplitude, at the default sample rate.
+func testSine(frames int, freq, amplitude float64) []byte {
+	data := make([]byte, frames*4)
+	buffer := AudioBuffer(data)
+	for i := 0; i < frames; i++ {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(SampleRate()))
+		buffer.Set(i, v, v)
+	}
+	return data
+}
+
+// testConstant returns the given number of frames of stereo audio held at the given level.
+func testConstant(frames int, level float64) []byte {
+	data := make([]byte, frames*4)
+	buffer := AudioBuffer(data)
+	for i := 0; i < frames; i++ {
+		buffer.Set(i, level, level)
+	}
+	return data
+}
+
+// testEffect is an effect that scales audio by a gain and counts the frames it has processed, so that using it from two goroutines
+// at once shows up as a data race.
+type testEffect struct {
+	gain   float64
+	frames int
+}
+
+func (e *testEffect) ApplyEffect(data []byte, bytesRead int) {
+	AudioBuffer(data[:bytesRead]).Scale(e.gain)
+	e.frames += bytesRead / 4
+}
+
+func (e *testEffect) Read(p []byte) (int, error)                   { return 0, io.EOF }
+func (e *testEffect) Seek(offset int64, whence int) (int64, error) { return 0, nil }
+func (e *testEffect) Clone() IEffect                               { return &testEffect{gain: e.gain} }
+func (e *testEffect) SetSource(source io.ReadSeeker)               {}
+
+// testStage is an effect that can be played as a stream, logging its name each time it's read so the order of a chain can be checked.
+type testStage struct {
+	name   string
+	gain   float64
+	source io.ReadSeeker
+	log    *[]string
+}
+
+func (e *testStage) Read(p []byte) (int, error) {
+	n, err := e.source.Read(p)
+	*e.log = append(*e.log, e.name)
+	e.ApplyEffect(p, n)
+	return n, err
+}
+
+func (e *testStage) ApplyEffect(data []byte, bytesRead int) {
+	AudioBuffer(data[:bytesRead]).Scale(e.gain)
+}
+func (e *testStage) Seek(offset int64, whence int) (int64, error) {
+	return e.source.Seek(offset, whence)
+}
+func (e *testStage) Clone() IEffect                 { clone := *e; return &clone }
+func (e *testStage) SetSource(source io.ReadSeeker) { e.source = source }
+
+func TestChainEffects(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	log := []string{}
+	source := bytes.NewReader(testConstant(256, 0.8))
+
+	a := &testStage{name: "a", gain: 0.5, source: source, log: &log}
+	b := &testStage{name: "b", gain: 0.5, log: &log}
+	c := &testStage{name: "c", gain: 0.5, log: &log}
+
+	tail := ChainEffects(a, b, c)
+
+	if tail != c {
+		t.Fatal("expected the last effect to be returned as the tail of the chain")
+	}
+
+	if a.source != source || b.source != a || c.source != b {
+		t.Error("expected each effect's source to be the effect before it, with the first left as-is")
+	}
+
+	buffer := make([]byte, 64*4)
+	if _, err := tail.Read(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(log, []string{"a", "b", "c"}) {
+		t.Errorf("expected reading the tail to pull through every stage in order, got %v", log)
+	}
+
+	if l, _ := AudioBuffer(buffer).Get(0); math.Abs(l-0.1) > 0.001 {
+		t.Errorf("expected every stage to be applied, got %f", l)
+	}
+
+	if single := ChainEffects(a); single != a || a.source != source {
+		t.Error("expected chaining a single effect to return it unchanged")
+	}
+
+	if ChainEffects() != nil {
+		t.Error("expected chaining no effects to return nil")
+	}
+
+}
+
+func TestAudioBufferLevels(t *testing.T) {
+
+	buffer := AudioBuffer(make([]byte, 4*4))
+	buffer.Set(0, 0.5, -0.25)
+	buffer.Set(1, -1, 0.25)
+	buffer.Set(2, 0, 0)
+	buffer.Set(3, 1, 0)
+
+	const tolerance = 0.0001
+
+	if l, r := buffer.Peak(); math.Abs(l-1) > tolerance || math.Abs(r-0.25) > tolerance {
+		t.Errorf("expected a peak of 1, 0.25, got %f, %f", l, r)
+	}
+
+	if l, r := buffer.RMS(); math.Abs(l-0.75) > tolerance || math.Abs(r-math.Sqrt(0.03125)) > tolerance {
+		t.Errorf("expected an RMS of 0.75, %f, got %f, %f", math.Sqrt(0.03125), l, r)
+	}
+
+	if clipped := buffer.ClippedCount(); clipped != 2 {
+		t.Errorf("expected 2 clipped samples, got %d", clipped)
+	}
+
+	if l, r := AudioBuffer(nil).RMS(); l != 0 || r != 0 {
+		t.Errorf("expected an empty buffer to have an RMS of 0, got %f, %f", l, r)
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { buffer.Peak(); buffer.RMS(); buffer.ClippedCount() }); allocs != 0 {
+		t.Errorf("expected measuring the buffer not to allocate, got %f allocations", allocs)
+	}
+
+}
+
+// TestScaleStereo checks that scaling a whole buffer at once gives the same result as scaling each frame with Get() and Set().
+func TestScaleStereo(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	for _, gains := range [][2]float64{{1, 1}, {0.5, 0.25}, {0, 1}, {-1, 0.75}, {1.7, 3}, {0.333, 1.01}} {
+
+		// A loud sine, so the larger gains push some samples past full scale.
+		scaled := testSine(1024, 440, 0.8)
+		expected := append([]byte{}, scaled...)
+
+		AudioBuffer(scaled).ScaleStereo(gains[0], gains[1])
+
+		for i := 0; i < AudioBuffer(expected).Len(); i++ {
+			l, r := AudioBuffer(expected).Get(i)
+			AudioBuffer(expected).Set(i, l*gains[0], r*gains[1])
+		}
+
+		if !bytes.Equal(scaled, expected) {
+			t.Errorf("expected ScaleStereo(%f, %f) to match scaling each frame with Get() and Set()", gains[0], gains[1])
+		}
+
+	}
+
+	// Only whole frames are scaled, so a partial frame at the end of the buffer is left alone.
+	buffer := append(testConstant(2, 0.5), 0x12, 0x34)
+	AudioBuffer(buffer).ScaleStereo(0, 0)
+
+	if !bytes.Equal(buffer, append(make([]byte, 8), 0x12, 0x34)) {
+		t.Errorf("expected ScaleStereo to only scale whole frames, got %v", buffer)
+	}
+
+}
+
+// BenchmarkScaleStereo compares scaling a buffer with ScaleStereo() against the loop over Get() and Set() it replaced.
+func BenchmarkScaleStereo(b *testing.B) {
+
+	SetDefaultSampleRate(44100)
+
+	source := testSine(512, 440, 0.5)
+	data := make([]byte, len(source))
+
+	b.Run("ScaleStereo", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(data, source)
+			AudioBuffer(data).ScaleStereo(0.5, 0.25)
+		}
+	})
+
+	b.Run("GetSet", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			copy(data, source)
+			buffer := AudioBuffer(data)
+			for f := 0; f < buffer.Len(); f++ {
+				l, r := buffer.Get(f)
+				buffer.Set(f, l*0.5, r*0.25)
+			}
+		}
+	})
+
+}
+
+func TestAudioBufferF32(t *testing.T) {
+
+	data := make([]byte, 4*8)
+	buffer := AudioBufferF32(data)
+
+	if buffer.Len() != 4 {
+		t.Errorf("expected 32 bytes of float32 audio to be 4 frames long, got %d", buffer.Len())
+	}
+
+	// Unlike AudioBuffer, values outside of -1 to 1 aren't clamped.
+	buffer.Set(1, 0.25, -1.5)
+	buffer.Set(4, 1, 1) // Out of range, so ignored
+
+	if l, r := buffer.Get(1); l != 0.25 || r != -1.5 {
+		t.Errorf("expected to get back the values set, got %f, %f", l, r)
+	}
+
+	if l := math.Float32frombits(binary.LittleEndian.Uint32(data[8:])); l != 0.25 {
+		t.Errorf("expected the left channel to be stored as a little-endian float32, got %f", l)
+	}
+
+	if l, r := buffer.Get(4); l != 0 || r != 0 {
+		t.Errorf("expected an out of range frame to read as silence, got %f, %f", l, r)
+	}
+
+	if _, ok := NewAudioBuffer(data, SampleFormatFloat32).(AudioBufferF32); !ok {
+		t.Errorf("expected NewAudioBuffer to return an AudioBufferF32 for SampleFormatFloat32")
+	}
+
+	if _, ok := NewAudioBuffer(data, SampleFormatInt16).(AudioBuffer); !ok {
+		t.Errorf("expected NewAudioBuffer to return an AudioBuffer for SampleFormatInt16")
+	}
+
+}
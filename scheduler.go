@@ -0,0 +1,80 @@
+package resound
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Scheduler schedules Players to start at exact times on the global sample clock, for music and rhythm games that need sounds
+// to land on a beat rather than on the next game frame. Times are given relative to the Scheduler's start, which is the clock's
+// position when the Scheduler was created (or last reset with Reset()), so a song's timeline can be laid out from 0.
+//
+// A scheduled Player starts playing right away, but outputs silence until its scheduled sample; its audio then starts partway
+// through the buffer being read, with leading silence up to that sample, rather than at the start of the next buffer (see PlayAtSample()).
+//
+// As for precision: sounds are placed to the sample against the clock, so the spacing between sounds scheduled against the same
+// Scheduler is exact to within a sample. However, the clock is itself a stream played through the audio context, and Ebitengine reads
+// each Player (including the clock) ahead of time in its own buffers, so the clock's position when a Player reads a buffer can be
+// off from where that Player actually is by up to the difference in how far ahead they've been read. This depends on the platform and
+// the Players' buffer sizes (see audio.Player.SetBufferSize()); with the defaults it's usually within a few tens of milliseconds, and it
+// stays consistent while the Players keep playing. Smaller buffer sizes tighten it, at the cost of more CPU overhead and risk of dropouts.
+// Schedule sounds at least a buffer ahead of the clock's position; a sound scheduled in the past starts as soon as it's read.
+type Scheduler struct {
+	origin int64
+	mutex  sync.Mutex
+}
+
+// NewScheduler creates a new Scheduler, starting at the global sample clock's current position.
+// The sample clock is started if it isn't running yet; an error is returned if there's no audio context to start it with.
+func NewScheduler() (*Scheduler, error) {
+
+	if err := clock.start(); err != nil {
+		return nil, err
+	}
+
+	return &Scheduler{origin: clock.elapsed()}, nil
+
+}
+
+// Reset restarts the Scheduler at the global sample clock's current position, so scheduled times are counted from now.
+// Players that have already been scheduled keep their original start times.
+func (s *Scheduler) Reset() *Scheduler {
+	s.mutex.Lock()
+	s.origin = clock.elapsed()
+	s.mutex.Unlock()
+	return s
+}
+
+// Schedule plays the given Player so that it starts at the given time after the Scheduler's start, to the sample.
+// An error is returned if the sample clock couldn't be started.
+func (s *Scheduler) Schedule(player *Player, at time.Duration) error {
+	return player.PlayAtSample(s.sampleAt(at))
+}
+
+// ScheduleFunc calls the given callback once the global sample clock reaches the given time after the Scheduler's start.
+// Unlike Schedule(), callbacks are only as precise as the clock's buffers, and are called from their own goroutines,
+// so be careful to synchronize any data they touch (see ScheduleAtSample()).
+func (s *Scheduler) ScheduleFunc(at time.Duration, callback func()) error {
+	return ScheduleAtSample(s.sampleAt(at), callback)
+}
+
+// sampleAt returns the sample position on the global sample clock of the given time after the Scheduler's start.
+func (s *Scheduler) sampleAt(at time.Duration) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.origin + int64(math.Round(at.Seconds()*float64(SampleRate())))
+}
+
+// Position returns the current position of the global sample clock, relative to the Scheduler's start.
+// As the clock is read ahead of what's audible, this is slightly ahead of the audio that's being heard.
+func (s *Scheduler) Position() time.Duration {
+	return time.Duration(float64(s.PositionSamples()) / float64(SampleRate()) * float64(time.Second))
+}
+
+// PositionSamples returns the current position of the global sample clock in samples (frames), relative to the Scheduler's start.
+func (s *Scheduler) PositionSamples() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return clock.elapsed() - s.origin
+}
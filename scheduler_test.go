@@ -0,0 +1,63 @@
+package resound
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+func TestSchedulerTiming(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	// The clock isn't started here, as that needs an audio context; it's advanced by reading from it directly instead.
+	scheduler := &Scheduler{origin: clock.elapsed()}
+
+	clock.Read(make([]byte, 441*4))
+
+	if pos := scheduler.PositionSamples(); pos != 441 {
+		t.Errorf("expected the Scheduler to be 441 samples in, got %d", pos)
+	}
+
+	if pos := scheduler.Position(); pos != 10*time.Millisecond {
+		t.Errorf("expected the Scheduler to be 10ms in, got %s", pos)
+	}
+
+	if sample := scheduler.sampleAt(time.Second); sample != scheduler.origin+44100 {
+		t.Errorf("expected a second after the Scheduler's start to be %d samples after it, got %d", 44100, sample-scheduler.origin)
+	}
+
+	scheduler.Reset()
+
+	if pos := scheduler.PositionSamples(); pos != 0 {
+		t.Errorf("expected resetting the Scheduler to restart it at the clock's position, got %d samples in", pos)
+	}
+
+	// A Player scheduled partway into the next buffer starts with silence up to its sample.
+	source := bytes.Repeat([]byte{0xff, 0x3f, 0xff, 0x3f}, 1000)
+
+	player := newPlayer(bytes.NewReader(source))
+	player.scheduled = true
+	player.startSample = scheduler.sampleAt(time.Duration(100) * time.Second / 44100)
+
+	output := make([]byte, 256*4)
+	if _, err := player.Read(output); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 256; i++ {
+		l, _ := AudioBuffer(output).Get(i)
+		if silent := l == 0; silent != (i < 100) {
+			t.Fatalf("expected the Player to be silent for the first 100 frames and then play, but frame %d had a level of %f", i, l)
+		}
+	}
+
+	if audio.CurrentContext() == nil {
+		if _, err := NewScheduler(); err == nil {
+			t.Errorf("expected creating a Scheduler without an audio context to return an error")
+		}
+	}
+
+}
@@ -0,0 +1,149 @@
+package resound
+
+// channelSend is an aux send from a DSPChannel to another channel, with the level of the audio sent.
+type channelSend struct {
+	target *DSPChannel
+	level  float64
+}
+
+// AddSend adds an aux send from the DSPChannel to the given target channel, mixing a copy of the channel's audio (scaled by the
+// given level) into the target. This allows many channels to share a single instance of an expensive effect, like a reverb on
+// an "fx" channel, with each channel choosing how much of its audio to send to it.
+//
+// Sends are post-fader; the audio is sent after the channel's effects, volume, and analyzer. The target works like any other
+// channel: everything sent to it is mixed together with the audio of the Players and channels playing through it, processed once
+// per buffer by its effects and volume, and then continues on to its own output channel (the "return"), like an aux bus on a mixer.
+// As this channel's own audio continues on to its output channel as well, the target channel's effects should usually be fully wet
+// (e.g. a Reverb with its dry level set to 0), so the dry audio isn't doubled.
+//
+// Audio is only sent to a target that's rendered along with the DSPChannel - that is, one that ends up routed into the same place
+// (the master channel, or the same Mixer). Sending to a target whose audio already reaches this channel (through its output channels
+// or sends) would create a cycle, so in that case the send isn't added.
+//
+// If the DSPChannel already sends to the target, its level is updated. Sending a channel to itself does nothing.
+func (d *DSPChannel) AddSend(target *DSPChannel, level float64) *DSPChannel {
+
+	if target == nil || target == d || target.reaches(d) {
+		return d
+	}
+
+	d.mutex.Lock()
+
+	// The sends are replaced rather than modified in place, so audio being processed with the old ones isn't disturbed.
+	sends := make([]channelSend, 0, len(d.sends)+1)
+	existing := false
+	for _, s := range d.sends {
+		if s.target != target {
+			sends = append(sends, s)
+		} else {
+			existing = true
+		}
+	}
+	d.sends = append(sends, channelSend{target: target, level: level})
+
+	d.mutex.Unlock()
+
+	if !existing {
+		target.addSendRef(1)
+	}
+
+	graphVersion.Add(1)
+
+	return d
+
+}
+
+// RemoveSend removes the DSPChannel's send to the given target channel. If the channel doesn't send to the target, this does nothing.
+func (d *DSPChannel) RemoveSend(target *DSPChannel) *DSPChannel {
+
+	d.mutex.Lock()
+
+	sends := make([]channelSend, 0, len(d.sends))
+	for _, s := range d.sends {
+		if s.target != target {
+			sends = append(sends, s)
+		}
+	}
+	removed := len(sends) < len(d.sends)
+	d.sends = sends
+
+	d.mutex.Unlock()
+
+	if removed {
+		target.addSendRef(-1)
+		graphVersion.Add(1)
+	}
+
+	return d
+
+}
+
+// SendLevel returns the level of the DSPChannel's send to the given target channel, or 0 if it doesn't send to the target.
+func (d *DSPChannel) SendLevel(target *DSPChannel) float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, s := range d.sends {
+		if s.target == target {
+			return s.level
+		}
+	}
+	return 0
+}
+
+// sendList returns the DSPChannel's sends. The sends are replaced rather than modified in place, so the returned slice is safe to iterate over.
+func (d *DSPChannel) sendList() []channelSend {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.sends
+}
+
+// addSendRef changes the number of channels that send to the DSPChannel by the given amount. While any channel sends to it, the
+// DSPChannel stays linked into the channels it's routed through, so it's rendered along with the channels sending to it.
+func (d *DSPChannel) addSendRef(n int) {
+
+	d.mutex.Lock()
+	d.sendRefs += n
+	d.mutex.Unlock()
+
+	if n > 0 {
+		d.connect()
+	}
+
+}
+
+// reaches returns if the DSPChannel's audio reaches the given channel, through its output channels and sends.
+func (d *DSPChannel) reaches(target *DSPChannel) bool {
+
+	visited := map[*DSPChannel]bool{}
+
+	var visit func(c *DSPChannel) bool
+
+	visit = func(c *DSPChannel) bool {
+
+		if c == nil || visited[c] {
+			return false
+		}
+
+		if c == target {
+			return true
+		}
+
+		visited[c] = true
+
+		if visit(c.outputChannel()) {
+			return true
+		}
+
+		for _, s := range c.sendList() {
+			if visit(s.target) {
+				return true
+			}
+		}
+
+		return false
+
+	}
+
+	return visit(d)
+
+}
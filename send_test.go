@@ -0,0 +1,70 @@
+package resound
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestSendMixedOncePerBuffer(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	bus, a, b, fx := NewDSPChannel(), NewDSPChannel(), NewDSPChannel(), NewDSPChannel()
+
+	for _, c := range []*DSPChannel{a, b, fx} {
+		c.SetOutput(bus)
+	}
+
+	counter := &testEffect{gain: 1}
+	fx.AddEffect("count", counter)
+
+	a.AddSend(fx, 1)
+	b.AddSend(fx, 0.5)
+
+	mixer := NewMixer(bus)
+
+	for _, c := range []*DSPChannel{a, b} {
+		player := newPlayer(bytes.NewReader(testConstant(4096, 0.1)))
+		player.SetDSPChannel(c)
+		mixer.Add(player)
+	}
+
+	buffer := make([]byte, 256*4)
+
+	for i := 0; i < 4; i++ {
+		mixer.Read(buffer)
+	}
+
+	// The sends are mixed together and processed by the target's effects once per buffer, rather than once per sending channel.
+	if counter.frames != 4*256 {
+		t.Errorf("expected the send target to process %d frames, processed %d", 4*256, counter.frames)
+	}
+
+	// Both channels' dry audio, plus the return of the target (0.1 + 0.05), all mixed into the bus.
+	if l, _ := AudioBuffer(buffer).Get(100); math.Abs(l-0.35) > 0.001 {
+		t.Errorf("expected the bus to output 0.35, got %f", l)
+	}
+
+}
+
+func TestSendCycle(t *testing.T) {
+
+	a, b, c := NewDSPChannel(), NewDSPChannel(), NewDSPChannel()
+
+	a.AddSend(b, 1)
+	b.SetOutput(c)
+
+	if c.AddSend(a, 1); c.SendLevel(a) != 0 {
+		t.Error("a send creating a cycle through a send and an output was added")
+	}
+
+	if err := c.SetOutput(a); err != ErrRoutingCycle {
+		t.Errorf("expected ErrRoutingCycle when routing into a channel that sends back, got %v", err)
+	}
+
+	if a.AddSend(c, 0.5); a.SendLevel(c) != 0.5 {
+		t.Error("a send that doesn't create a cycle wasn't added")
+	}
+
+}
@@ -0,0 +1,204 @@
+package resound
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Decoder decodes an encoded audio file (like a WAV or OGG file) into a stream of 16-bit stereo audio at the audio context's sample rate.
+// Ebitengine's decoders can be wrapped to create a Decoder, like so:
+//
+//	decoder := func(src io.Reader) (io.Reader, error) { return wav.DecodeWithSampleRate(sampleRate, src) }
+type Decoder func(src io.Reader) (io.Reader, error)
+
+// ErrNoFreeVoices is returned by SoundPool.Play() when all of the pool's voices are playing and it isn't set to steal the oldest one.
+var ErrNoFreeVoices = errors.New("resound: all of the SoundPool's voices are playing")
+
+// SoundPool plays a single sound many times over, potentially simultaneously (like footsteps, gunshots, or UI clicks).
+// The sound is decoded once when the pool is created, and each time it's played, the pool hands out a Player (a "voice")
+// that reads from the decoded audio, reusing voices that have finished playing rather than creating new ones.
+type SoundPool struct {
+	data        []byte
+	voices      []*Player
+	maxVoices   int
+	stealOldest bool
+	channel     *DSPChannel
+}
+
+// NewSoundPool creates a new SoundPool that plays the given encoded audio data, decoding it once with the given Decoder.
+// If decoder is nil, the data is used as is, and so should already be 16-bit stereo audio at the audio context's sample rate.
+// maxVoices is the maximum number of voices that can play simultaneously; a value of 0 or less means there's no limit.
+// By default, when all voices are playing, the oldest one is stopped and reused to play the sound again (see SetStealOldest()),
+// and the voices play through the master channel (see SetDSPChannel()).
+func NewSoundPool(data []byte, decoder Decoder, maxVoices int) (*SoundPool, error) {
+
+	pool := &SoundPool{
+		data:        data,
+		maxVoices:   maxVoices,
+		stealOldest: true,
+		channel:     MasterChannel(),
+	}
+
+	if decoder != nil {
+
+		stream, err := decoder(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		pool.data, err = io.ReadAll(stream)
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	return pool, nil
+
+}
+
+// NewSoundPoolFromBuffer creates a new SoundPool that plays the given Buffer's audio, which has already been decoded.
+// This allows a sound to be decoded once and shared between a SoundPool and other Players. See NewSoundPool() for details on maxVoices.
+func NewSoundPoolFromBuffer(buffer *Buffer, maxVoices int) *SoundPool {
+	return &SoundPool{
+		data:        buffer.Bytes(),
+		maxVoices:   maxVoices,
+		stealOldest: true,
+		channel:     MasterChannel(),
+	}
+}
+
+// Play plays the pool's sound, returning the voice used to play it. The voice is only valid until the sound finishes playing
+// (or is stolen), after which the pool may reuse it, so references to it shouldn't be held onto for longer than that.
+// As with DSPChannel.PlayOneShot(), a reused voice starts out with its default properties.
+// If all voices are playing and the pool isn't set to steal the oldest voice, Play returns ErrNoFreeVoices.
+func (s *SoundPool) Play() (*Player, error) {
+
+	voices, voice, err := playPooled(s.voices, s.maxVoices, s.stealOldest, s.channel, bytes.NewReader(s.data))
+	if err != nil {
+		return nil, err
+	}
+
+	s.voices = voices
+
+	return voice, nil
+
+}
+
+// playPooled plays the given stream through the channel using a Player from the given pool, which is ordered from least to most
+// recently played. A Player that has finished playing is reset to its default properties and reused; otherwise, if the pool holds
+// max Players (with max being 0 or less meaning there's no limit), the oldest one is stopped and reused if steal is set, or
+// ErrNoFreeVoices is returned if it isn't. If neither applies, a new Player is created and added to the pool.
+// playPooled returns the updated pool, along with the Player that's playing the stream.
+func playPooled(pool []*Player, max int, steal bool, channel *DSPChannel, stream io.ReadSeeker) ([]*Player, *Player, error) {
+
+	var player *Player
+
+	index := -1
+
+	for i, p := range pool {
+		if !p.IsPlaying() {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 && max > 0 && len(pool) >= max {
+		if !steal {
+			return pool, nil, ErrNoFreeVoices
+		}
+		index = 0
+		pool[index].Pause()
+	}
+
+	if index >= 0 {
+
+		player = pool[index]
+
+		player.resetProperties()
+		player.SetSource(stream)
+
+		if err := player.Rewind(); err != nil {
+			return pool, nil, err
+		}
+
+		pool = append(pool[:index], pool[index+1:]...)
+
+	} else {
+
+		var err error
+
+		player, err = NewPlayer(stream)
+		if err != nil {
+			return pool, nil, err
+		}
+
+		player.SetDSPChannel(channel)
+
+	}
+
+	pool = append(pool, player)
+
+	player.Play()
+
+	return pool, player, nil
+
+}
+
+// Stop pauses all of the pool's voices.
+func (s *SoundPool) Stop() {
+	for _, v := range s.voices {
+		v.Pause()
+	}
+}
+
+// SetDSPChannel sets the DSPChannel the pool's voices play through, so they take on the channel's effects.
+// Voices that are already playing switch to the new channel immediately.
+func (s *SoundPool) SetDSPChannel(channel *DSPChannel) *SoundPool {
+	s.channel = channel
+	for _, v := range s.voices {
+		v.SetDSPChannel(channel)
+	}
+	return s
+}
+
+// DSPChannel returns the DSPChannel the pool's voices play through.
+func (s *SoundPool) DSPChannel() *DSPChannel {
+	return s.channel
+}
+
+// SetMaxVoices sets the maximum number of voices that can play simultaneously. A value of 0 or less means there's no limit.
+// If the pool already has more voices than the new maximum, the extra voices keep playing until they're reused.
+func (s *SoundPool) SetMaxVoices(max int) *SoundPool {
+	s.maxVoices = max
+	return s
+}
+
+// MaxVoices returns the maximum number of voices that can play simultaneously.
+func (s *SoundPool) MaxVoices() int {
+	return s.maxVoices
+}
+
+// SetStealOldest sets whether the pool stops and reuses its oldest voice to play the sound when all of its voices are playing.
+// If disabled, Play() returns ErrNoFreeVoices instead.
+func (s *SoundPool) SetStealOldest(steal bool) *SoundPool {
+	s.stealOldest = steal
+	return s
+}
+
+// StealOldest returns whether the pool stops and reuses its oldest voice when all of its voices are playing.
+func (s *SoundPool) StealOldest() bool {
+	return s.stealOldest
+}
+
+// PlayingVoices returns how many of the pool's voices are currently playing.
+func (s *SoundPool) PlayingVoices() int {
+	count := 0
+	for _, v := range s.voices {
+		if v.IsPlaying() {
+			count++
+		}
+	}
+	return count
+}
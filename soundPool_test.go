@@ -0,0 +1,56 @@
+package resound
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSoundPoolVoices(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	channel := NewDSPChannel()
+
+	pool, err := NewSoundPool(make([]byte, 44100*4), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.SetDSPChannel(channel)
+
+	// The voices are created ahead of time, so playing the pool reuses these rather than creating Players through the audio context.
+	for i := 0; i < 2; i++ {
+		pool.voices = append(pool.voices, newPlayer(bytes.NewReader(pool.data)).SetDSPChannel(channel))
+	}
+
+	first, _ := pool.Play()
+	second, _ := pool.Play()
+
+	if first == second || pool.PlayingVoices() != 2 {
+		t.Fatalf("expected two different voices to be playing, got %d", pool.PlayingVoices())
+	}
+
+	// With both voices playing, the oldest is stolen.
+	if third, _ := pool.Play(); third != first || pool.PlayingVoices() != 2 {
+		t.Errorf("expected the oldest voice to be reused, with 2 voices playing; got %d voices playing", pool.PlayingVoices())
+	}
+
+	if _, err := pool.SetStealOldest(false).Play(); err != ErrNoFreeVoices {
+		t.Errorf("expected ErrNoFreeVoices once every voice is playing, got %v", err)
+	}
+
+	second.SetVolume(0.5)
+
+	pool.Stop()
+
+	if pool.PlayingVoices() != 0 || len(channel.PlayingPlayers()) != 0 {
+		t.Errorf("expected stopping the pool to stop every voice, got %d playing", pool.PlayingVoices())
+	}
+
+	if voice, err := pool.Play(); err != nil || voice != second {
+		t.Errorf("expected the least recently played voice to be reused once the voices have stopped, got %v", err)
+	} else if voice.Volume() != 1 {
+		t.Errorf("expected the reused voice's volume to be reset to 1, got %f", voice.Volume())
+	}
+
+}
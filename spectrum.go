@@ -0,0 +1,205 @@
+package resound
+
+import (
+	"math"
+)
+
+// WindowFunction indicates the window function a SpectrumAnalyzer applies to audio before analyzing it.
+// Windowing tapers off the ends of the analyzed audio, which reduces spectral leakage (energy from one frequency smearing into neighboring bins).
+type WindowFunction int
+
+const (
+	WindowHann        WindowFunction = iota // A Hann window, which is a good general-purpose choice.
+	WindowHamming                           // A Hamming window, which has a narrower main lobe but higher side lobes than a Hann window.
+	WindowRectangular                       // No windowing at all.
+)
+
+// SpectrumAnalyzer measures the strength of the frequencies in audio using a fast Fourier transform (FFT), written in pure Go.
+// Feed it audio using Feed() (for example, from an analyzer callback set with Player.SetAnalyzer()), and then get the magnitudes
+// of each frequency bin using Bins().
+type SpectrumAnalyzer struct {
+	size       int
+	window     WindowFunction
+	samples    []float64
+	writeIndex int
+
+	coefficients []float64
+	windowSum    float64
+	re, im       []float64
+}
+
+// NewSpectrumAnalyzer creates a new SpectrumAnalyzer that analyzes the most recent fftSize frames fed to it. fftSize is rounded up to a
+// power of two (with a minimum of 2); larger sizes give finer frequency resolution, but respond more slowly to changes in the audio.
+// 1024 or 2048 are good starting points.
+func NewSpectrumAnalyzer(fftSize int) *SpectrumAnalyzer {
+
+	size := 2
+	for size < fftSize {
+		size *= 2
+	}
+
+	sa := &SpectrumAnalyzer{
+		size:    size,
+		samples: make([]float64, size),
+		re:      make([]float64, size),
+		im:      make([]float64, size),
+	}
+
+	sa.SetWindow(WindowHann)
+
+	return sa
+
+}
+
+// Feed adds the audio in the given buffer to the SpectrumAnalyzer, mixing the left and right channels together.
+func (sa *SpectrumAnalyzer) Feed(buffer AudioBuffer) {
+	for i := 0; i < buffer.Len(); i++ {
+		l, r := buffer.Get(i)
+		sa.samples[sa.writeIndex] = (l + r) / 2
+		sa.writeIndex = (sa.writeIndex + 1) % sa.size
+	}
+}
+
+// Bins returns the magnitudes of the frequencies in the most recent audio fed to the SpectrumAnalyzer. There are fftSize / 2 bins,
+// evenly spaced from 0hz up to half of the sample rate; see BinFrequency(). A full-scale sine wave has a magnitude of roughly 1.
+func (sa *SpectrumAnalyzer) Bins() []float64 {
+
+	// The samples are unrolled from the ring buffer, oldest first.
+	for i := 0; i < sa.size; i++ {
+		sa.re[i] = sa.samples[(sa.writeIndex+i)%sa.size] * sa.coefficients[i]
+		sa.im[i] = 0
+	}
+
+	FFT(sa.re, sa.im)
+
+	bins := make([]float64, sa.size/2)
+
+	for i := range bins {
+		bins[i] = math.Hypot(sa.re[i], sa.im[i]) * 2 / sa.windowSum
+	}
+
+	return bins
+
+}
+
+// BinFrequency returns the center frequency (in hertz) of the given bin, using the audio context's sample rate.
+func (sa *SpectrumAnalyzer) BinFrequency(bin int) float64 {
+	return float64(bin) * float64(SampleRate()) / float64(sa.size)
+}
+
+// Size returns the FFT size of the SpectrumAnalyzer.
+func (sa *SpectrumAnalyzer) Size() int {
+	return sa.size
+}
+
+// SetWindow sets the window function the SpectrumAnalyzer applies to audio before analyzing it. Defaults to WindowHann.
+func (sa *SpectrumAnalyzer) SetWindow(window WindowFunction) *SpectrumAnalyzer {
+
+	sa.window = window
+
+	sa.coefficients = make([]float64, sa.size)
+	sa.windowSum = 0
+
+	for i := range sa.coefficients {
+
+		t := 2 * math.Pi * float64(i) / float64(sa.size-1)
+
+		switch window {
+		case WindowHann:
+			sa.coefficients[i] = 0.5 - 0.5*math.Cos(t)
+		case WindowHamming:
+			sa.coefficients[i] = 0.54 - 0.46*math.Cos(t)
+		default:
+			sa.coefficients[i] = 1
+		}
+
+		sa.windowSum += sa.coefficients[i]
+
+	}
+
+	return sa
+
+}
+
+// Window returns the window function the SpectrumAnalyzer applies to audio before analyzing it.
+func (sa *SpectrumAnalyzer) Window() WindowFunction {
+	return sa.window
+}
+
+// Reset clears the audio fed to the SpectrumAnalyzer.
+func (sa *SpectrumAnalyzer) Reset() {
+	for i := range sa.samples {
+		sa.samples[i] = 0
+	}
+	sa.writeIndex = 0
+}
+
+// FFT performs an in-place iterative radix-2 fast Fourier transform on the given real and imaginary parts,
+// which must have the same power-of-two length. This is useful for writing effects that work in the frequency domain.
+func FFT(re, im []float64) {
+
+	n := len(re)
+
+	// Reorder the values into bit-reversed order.
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			re[i], re[j] = re[j], re[i]
+			im[i], im[j] = im[j], im[i]
+		}
+	}
+
+	for length := 2; length <= n; length <<= 1 {
+
+		angle := -2 * math.Pi / float64(length)
+		wRe, wIm := math.Cos(angle), math.Sin(angle)
+
+		for start := 0; start < n; start += length {
+
+			curRe, curIm := 1.0, 0.0
+
+			for k := 0; k < length/2; k++ {
+
+				a := start + k
+				b := a + length/2
+
+				tRe := re[b]*curRe - im[b]*curIm
+				tIm := re[b]*curIm + im[b]*curRe
+
+				re[b] = re[a] - tRe
+				im[b] = im[a] - tIm
+				re[a] += tRe
+				im[a] += tIm
+
+				curRe, curIm = curRe*wRe-curIm*wIm, curRe*wIm+curIm*wRe
+
+			}
+
+		}
+
+	}
+
+}
+
+// InverseFFT performs an in-place inverse fast Fourier transform on the given real and imaginary parts, which must have the same
+// power-of-two length, undoing FFT(). The result is scaled by 1 / length, so FFT() followed by InverseFFT() returns the original values.
+func InverseFFT(re, im []float64) {
+
+	for i := range im {
+		im[i] = -im[i]
+	}
+
+	FFT(re, im)
+
+	scale := 1 / float64(len(re))
+
+	for i := range re {
+		re[i] *= scale
+		im[i] *= -scale
+	}
+
+}
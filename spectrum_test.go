@@ -0,0 +1,54 @@
+package resound
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpectrumAnalyzerBins(t *testing.T) {
+
+	SetDefaultSampleRate(44100)
+
+	analyzer := NewSpectrumAnalyzer(1000)
+
+	if analyzer.Size() != 1024 {
+		t.Errorf("expected the FFT size to be rounded up to 1024, got %d", analyzer.Size())
+	}
+
+	// A sine at the center frequency of bin 32 should show up in that bin alone.
+	freq := analyzer.BinFrequency(32)
+
+	if math.Abs(freq-32*44100.0/1024) > 0.001 {
+		t.Errorf("expected bin 32 to be centered on %f hz, got %f hz", 32*44100.0/1024, freq)
+	}
+
+	data := make([]byte, 2048*4)
+	for i := 0; i < 2048; i++ {
+		v := 0.5 * math.Sin(2*math.Pi*freq*float64(i)/44100)
+		AudioBuffer(data).Set(i, v, v)
+	}
+
+	analyzer.Feed(AudioBuffer(data))
+
+	bins := analyzer.Bins()
+
+	if len(bins) != 512 {
+		t.Fatalf("expected 512 bins, got %d", len(bins))
+	}
+
+	peak := 0
+	for i := range bins {
+		if bins[i] > bins[peak] {
+			peak = i
+		}
+	}
+
+	if peak != 32 || math.Abs(bins[peak]-0.5) > 0.01 {
+		t.Errorf("expected a sine with an amplitude of 0.5 to peak at bin 32 with a magnitude of 0.5, got bin %d with %f", peak, bins[peak])
+	}
+
+	if bins[100] > 0.001 {
+		t.Errorf("expected bins away from the sine to be empty, got %f in bin 100", bins[100])
+	}
+
+}
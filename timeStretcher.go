@@ -0,0 +1,309 @@
+package resound
+
+import (
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	timeStretcherFrameLength = 0.046 // The length (in seconds) of each frame of audio the TimeStretcher overlaps
+	timeStretcherTolerance   = 0.01  // How far (in seconds) the TimeStretcher searches for the best-matching frame
+)
+
+// TimeStretcher is a stream effect that changes the length (and so the speed) of audio without changing its pitch.
+// It uses the WSOLA (waveform-similarity overlap-add) algorithm: it cuts the source audio into overlapping frames and
+// lays them back down closer together or further apart, choosing each frame's exact position so its waveform lines up
+// with the previous frame's to avoid warbling.
+//
+// Because it changes the length of the audio, TimeStretcher is an IStreamEffect, and so should be added to a Player using
+// Player.AddStreamEffect(), or used directly as a stream.
+//
+// The algorithm introduces latency of one frame (about 46 milliseconds); see Latency().
+// TimeStretcher lives in the resound package so Players can use it to change their playback rate without changing
+// their pitch (see Player.SetPreservePitch()); it's also available alongside the other effects as effects.TimeStretch.
+type TimeStretcher struct {
+	stretch float64
+	active  bool
+	Source  io.ReadSeeker
+
+	sampleRate int
+	frameSize  int
+	hop        int
+	tolerance  int
+	window     []float64
+
+	input       [][2]float64
+	inputStart  int
+	analysisPos float64
+	prevPos     int
+	overlap     [][2]float64
+	output      [][2]float64
+	sourceEnded bool
+	sourceErr   error
+	readBuffer  []byte
+}
+
+// NewTimeStretcher creates a new TimeStretcher.
+func NewTimeStretcher() *TimeStretcher {
+	return &TimeStretcher{
+		stretch:    1,
+		active:     true,
+		prevPos:    -1,
+		readBuffer: make([]byte, 4096),
+	}
+}
+
+// setup sizes the effect's frames and buffers for the current sample rate.
+func (ts *TimeStretcher) setup() {
+
+	sampleRate := SampleRate()
+
+	if sampleRate == ts.sampleRate {
+		return
+	}
+
+	ts.sampleRate = sampleRate
+	ts.frameSize = int(timeStretcherFrameLength*float64(sampleRate)) / 2 * 2
+	ts.hop = ts.frameSize / 2
+	ts.tolerance = int(timeStretcherTolerance * float64(sampleRate))
+
+	// A Hann window with 50% overlap sums to a constant, so overlapping frames don't change the volume.
+	ts.window = make([]float64, ts.frameSize)
+	for i := range ts.window {
+		ts.window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(ts.frameSize))
+	}
+
+	ts.reset()
+
+}
+
+// reset clears the effect's buffered audio.
+func (ts *TimeStretcher) reset() {
+	ts.input = ts.input[:0]
+	ts.inputStart = 0
+	ts.analysisPos = 0
+	ts.prevPos = -1
+	ts.overlap = make([][2]float64, ts.frameSize)
+	ts.output = ts.output[:0]
+	ts.sourceEnded = false
+	ts.sourceErr = nil
+}
+
+func (ts *TimeStretcher) Read(p []byte) (n int, err error) {
+
+	if !ts.active {
+		return ts.Source.Read(p)
+	}
+
+	ts.setup()
+
+	frames := len(p) / 4
+
+	for len(ts.output) < frames {
+		if !ts.processFrame() {
+			break
+		}
+	}
+
+	count := frames
+	if len(ts.output) < count {
+		count = len(ts.output)
+	}
+
+	audio := AudioBuffer(p)
+
+	for i := 0; i < count; i++ {
+		audio.Set(i, ts.output[i][0], ts.output[i][1])
+	}
+
+	ts.output = ts.output[:copy(ts.output, ts.output[count:])]
+
+	if count == 0 && ts.sourceEnded {
+		if ts.sourceErr != nil {
+			return 0, ts.sourceErr
+		}
+		return 0, io.EOF
+	}
+
+	return count * 4, nil
+
+}
+
+// processFrame overlap-adds the next frame of audio into the output. It returns false if there's no more audio to process.
+func (ts *TimeStretcher) processFrame() bool {
+
+	start := int(ts.analysisPos)
+
+	ts.fill(start + ts.tolerance + ts.frameSize - ts.inputStart)
+
+	if ts.sourceEnded && start >= ts.inputStart+len(ts.input) {
+		return false
+	}
+
+	best := start
+
+	// The next frame should continue on naturally from the previous one; we search around the nominal position for the
+	// frame that best matches that natural continuation so the overlapping waveforms line up.
+	if ts.prevPos >= 0 {
+		best = ts.bestMatch(start, ts.prevPos+ts.hop)
+	}
+
+	for i := 0; i < ts.frameSize; i++ {
+		l, r := ts.sample(best + i)
+		ts.overlap[i][0] += l * ts.window[i]
+		ts.overlap[i][1] += r * ts.window[i]
+	}
+
+	// The first hop of the overlap buffer won't be added to anymore, so it's ready to be output.
+	ts.output = append(ts.output, ts.overlap[:ts.hop]...)
+	copy(ts.overlap, ts.overlap[ts.hop:])
+	for i := ts.frameSize - ts.hop; i < ts.frameSize; i++ {
+		ts.overlap[i] = [2]float64{}
+	}
+
+	ts.prevPos = best
+	ts.analysisPos += float64(ts.hop) / ts.stretch
+
+	// Discard input that will never be read again.
+	keepFrom := ts.prevPos + ts.hop
+	if searchStart := int(ts.analysisPos) - ts.tolerance; searchStart < keepFrom {
+		keepFrom = searchStart
+	}
+
+	if trim := keepFrom - ts.inputStart; trim > 0 {
+		if trim > len(ts.input) {
+			trim = len(ts.input)
+		}
+		ts.input = ts.input[:copy(ts.input, ts.input[trim:])]
+		ts.inputStart += trim
+	}
+
+	return true
+
+}
+
+// bestMatch returns the position within the search tolerance of start whose audio best matches the audio at the natural position.
+func (ts *TimeStretcher) bestMatch(start, natural int) int {
+
+	best := start
+	bestScore := 0.0
+	first := true
+
+	length := ts.hop
+
+	for offset := -ts.tolerance; offset <= ts.tolerance; offset++ {
+
+		candidate := start + offset
+
+		if candidate < ts.inputStart {
+			continue
+		}
+
+		score := 0.0
+
+		// Every other sample is compared to keep the search cheap.
+		for i := 0; i < length; i += 2 {
+			cl, cr := ts.sample(candidate + i)
+			nl, nr := ts.sample(natural + i)
+			score += (cl + cr) * (nl + nr)
+		}
+
+		if first || score > bestScore {
+			best = candidate
+			bestScore = score
+			first = false
+		}
+
+	}
+
+	return best
+
+}
+
+// fill reads from the source until the input buffer holds at least the given number of frames, or the source ends.
+func (ts *TimeStretcher) fill(frames int) {
+
+	for len(ts.input) < frames && !ts.sourceEnded {
+
+		n, err := ts.Source.Read(ts.readBuffer)
+
+		buffer := AudioBuffer(ts.readBuffer[:n])
+
+		for i := 0; i < buffer.Len(); i++ {
+			l, r := buffer.Get(i)
+			ts.input = append(ts.input, [2]float64{l, r})
+		}
+
+		if err != nil {
+			ts.sourceEnded = true
+			if err != io.EOF {
+				ts.sourceErr = err
+			}
+		}
+
+	}
+
+}
+
+// sample returns the input sample at the given absolute position in the source stream, or silence if it's not buffered.
+func (ts *TimeStretcher) sample(pos int) (l, r float64) {
+	i := pos - ts.inputStart
+	if i < 0 || i >= len(ts.input) {
+		return 0, 0
+	}
+	return ts.input[i][0], ts.input[i][1]
+}
+
+// Seek seeks the source stream and clears any buffered audio. Note that the offset is in the source stream's
+// timeline, not the stretched timeline.
+func (ts *TimeStretcher) Seek(offset int64, whence int) (int64, error) {
+	if ts.Source == nil {
+		return 0, nil
+	}
+	ts.reset()
+	return ts.Source.Seek(offset, whence)
+}
+
+// Reset clears the effect's buffered audio, as though it had just been created. Its settings are left unchanged.
+func (ts *TimeStretcher) Reset() {
+	ts.reset()
+}
+
+// SetActive sets the effect to be active. When inactive, the TimeStretcher reads directly from its source.
+func (ts *TimeStretcher) SetActive(active bool) *TimeStretcher {
+	if active != ts.active {
+		ts.reset()
+	}
+	ts.active = active
+	return ts
+}
+
+// Active returns if the effect is active.
+func (ts *TimeStretcher) Active() bool {
+	return ts.active
+}
+
+// SetStretch sets the stretch factor of the effect; a factor of 2 makes audio play for twice as long (at half speed),
+// while 0.5 makes it play for half as long (at double speed). The pitch stays the same either way.
+// The value is clamped from 0.25 to 4.
+func (ts *TimeStretcher) SetStretch(factor float64) *TimeStretcher {
+	ts.stretch = clamp(factor, 0.25, 4)
+	return ts
+}
+
+// Stretch returns the stretch factor of the effect.
+func (ts *TimeStretcher) Stretch() float64 {
+	return ts.stretch
+}
+
+// Latency returns the latency the time-stretching algorithm introduces, which is the length of one frame of audio.
+func (ts *TimeStretcher) Latency() time.Duration {
+	return time.Duration(timeStretcherFrameLength * float64(time.Second))
+}
+
+// SetSource sets the active source for the effect.
+func (ts *TimeStretcher) SetSource(source io.ReadSeeker) {
+	ts.Source = source
+	ts.reset()
+}
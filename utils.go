@@ -8,3 +8,69 @@ func clamp(v, min, max float64) float64 {
 	}
 	return v
 }
+
+// copyEffectMap returns a copy of the given map of effects.
+func copyEffectMap(effects map[any]IEffect) map[any]IEffect {
+	out := make(map[any]IEffect, len(effects))
+	for id, effect := range effects {
+		out[id] = effect
+	}
+	return out
+}
+
+// sameEffects returns if the two effect orders hold the same effects in the same order.
+func sameEffects(a, b []IEffect) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// removeEffect returns a copy of the given effect order with the given effect removed. A copy is made (rather than
+// modifying the order in place) so that the audio thread can keep reading the old order safely.
+func removeEffect(order []IEffect, effect IEffect) []IEffect {
+	newOrder := make([]IEffect, 0, len(order))
+	for _, e := range order {
+		if e != effect {
+			newOrder = append(newOrder, e)
+		}
+	}
+	return newOrder
+}
+
+// moveEffect returns a copy of the given effect order with the given effect moved to the given index, which is clamped
+// to the bounds of the order. If the effect isn't in the order, the order is returned as is.
+func moveEffect(order []IEffect, effect IEffect, toIndex int) []IEffect {
+
+	found := false
+	for _, e := range order {
+		if e == effect {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return order
+	}
+
+	newOrder := removeEffect(order, effect)
+
+	if toIndex < 0 {
+		toIndex = 0
+	} else if toIndex > len(newOrder) {
+		toIndex = len(newOrder)
+	}
+
+	newOrder = append(newOrder, nil)
+	copy(newOrder[toIndex+1:], newOrder[toIndex:])
+	newOrder[toIndex] = effect
+
+	return newOrder
+
+}